@@ -0,0 +1,475 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: chargepoint.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/chargepoint
+	Purpose: OCPP-J client of the Charge Point.
+			 Connects to the Central System by WebSocket with ocpp1.6 subprotocol,
+			 sends Charge Point initiated Calls and passes Central System
+			 initiated Calls to the ChargePointHandlers
+	=============================================================================
+*/
+
+package chargepoint
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/core"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	// WebSocket subprotocol of the OCPP-J 1.6
+	SUBPROTOCOL_OCPP16 string = core.SUBPROTOCOL_OCPP16
+)
+
+var ErrNotConnected = errors.New("Charge Point is not connected to the Central System")
+
+/****************************************************************************************
+ *	Struct 	: ChargePoint
+ *
+ * 	Purpose : Struct handles connection of the Charge Point to the Central System
+ *
+*****************************************************************************************/
+type ChargePoint struct {
+	Name        string          // Charge Point identity, last part of the endpoint URL
+	Header      http.Header     // Additional headers of the handshake, as example Authorization
+	TLSConfig   *tls.Config     // Trusted CA and client certificate for wss:// (Security Profiles 2 and 3)
+	Subprotocol string          // Subprotocol agreed with the Central System
+	OnError     func(err error) // Optional callback for errors of the income messages handling
+	handler     RequestHandler
+	conn        *websocket.Conn
+	writeMux    sync.Mutex
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePointConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the ChargePoint
+ *
+ *	  Input : chargerName string - identity of the Charge Point
+ *			  callbackRoutines ChargePointHandlers - routines to handle Central System Calls
+ *
+ *	Return : *ChargePoint object
+ */
+func ChargePointConstructor(chargerName string, callbackRoutines ChargePointHandlers) *ChargePoint {
+	chargePoint := ChargePoint{}
+	chargePoint.Name = chargerName
+	chargePoint.Header = http.Header{}
+	chargePoint.handler = ChargePointHandlerConstructor(callbackRoutines)
+	chargePoint.done = make(chan struct{})
+	return &chargePoint
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::SetBasicAuth
+ *
+ *  Purpose : Set HTTP Basic authorization of the handshake regarding Security Profile 1,
+ *			  name of the Charge Point is used as username
+ *
+ *    Input : password string - AuthorizationKey of the Charge Point
+ *
+ *   Return : Nothing
+ */
+func (chargePoint *ChargePoint) SetBasicAuth(password string) {
+	credentials := base64.StdEncoding.EncodeToString([]byte(chargePoint.Name + ":" + password))
+	chargePoint.Header.Set("Authorization", "Basic "+credentials)
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::Connect
+ *
+ *  Purpose : Open WebSocket connection to the Central System and start reading goroutine
+ *
+ *    Input : ctx context.Context - context to limit time of the handshake
+ *			  serverURL string - endpoint of the Central System without identity,
+ *								 as example ws://localhost:9033/ocppj/1.6
+ *
+ *   Return : error - if happened, nil otherwise
+ */
+func (chargePoint *ChargePoint) Connect(ctx context.Context, serverURL string) error {
+	if chargePoint.conn != nil {
+		return errors.New(fmt.Sprintf("Charge Point '%v' is connected already", chargePoint.Name))
+	}
+
+	endpoint := strings.TrimSuffix(serverURL, "/") + "/" + url.PathEscape(chargePoint.Name)
+
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: websocket.DefaultDialer.HandshakeTimeout,
+		Subprotocols:     []string{SUBPROTOCOL_OCPP16},
+		TLSClientConfig:  chargePoint.TLSConfig,
+	}
+
+	conn, response, err := dialer.DialContext(ctx, endpoint, chargePoint.Header)
+	if err != nil {
+		if response != nil {
+			return errors.New(fmt.Sprintf("Cannot connect to '%v', status '%v', error '%v'", endpoint, response.Status, err))
+		}
+		return errors.New(fmt.Sprintf("Cannot connect to '%v' with error '%v'", endpoint, err))
+	}
+
+	// Connection must be dropped when Central System does not agree ocpp1.6, regarding OCPP-J section 3
+	if conn.Subprotocol() != SUBPROTOCOL_OCPP16 {
+		conn.Close()
+		return errors.New(fmt.Sprintf("Central System did not agree subprotocol '%v', answered '%v'", SUBPROTOCOL_OCPP16, conn.Subprotocol()))
+	}
+
+	chargePoint.conn = conn
+	chargePoint.Subprotocol = conn.Subprotocol()
+
+	go chargePoint.readMessages()
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::Done
+ *
+ *  Purpose : Get channel which is closed when connection is closed
+ *
+ *    Input : Nothing
+ *
+ *   Return : <-chan struct{}
+ */
+func (chargePoint *ChargePoint) Done() <-chan struct{} {
+	return chargePoint.done
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::Close
+ *
+ *  Purpose : Close connection to the Central System, outstanding Call is failed
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if happened, nil otherwise
+ */
+func (chargePoint *ChargePoint) Close() error {
+	if chargePoint.conn == nil {
+		return ErrNotConnected
+	}
+
+	var err error
+	chargePoint.closeOnce.Do(func() {
+		chargePoint.writeMux.Lock()
+		chargePoint.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		chargePoint.writeMux.Unlock()
+
+		// Reading goroutine checks done channel to know that close is expected
+		close(chargePoint.done)
+		err = chargePoint.conn.Close()
+		chargePoint.handler.Calls.Cancel()
+	})
+
+	return err
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::SendCall
+ *
+ *  Purpose : Send Call to the Central System and wait for the answer.
+ *			  OCPP-J allows one outstanding Call, next Call waits for the previous one
+ *
+ *    Input : ctx context.Context - context to limit time of the Call
+ *			  action string - action of the Call
+ *			  payload map[string]interface{} - payload of the Call
+ *
+ *   Return : messages.CallResultMessage - answer of the Central System
+ *			  error - messages.CallErrorMessage when Central System answered with CallError,
+ *					  core.ErrCallTimeout, core.ErrCallDisconnected or other error
+ */
+func (chargePoint *ChargePoint) SendCall(ctx context.Context, action string, payload map[string]interface{}) (messages.CallResultMessage, error) {
+	if chargePoint.conn == nil {
+		return messages.CallResultMessage{}, ErrNotConnected
+	}
+
+	callMessage := messages.CreateCallMessage(uuid.New().String(), action, payload)
+
+	return chargePoint.handler.Calls.Call(ctx, callMessage, chargePoint.write)
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::call
+ *
+ *  Purpose : Send Call with typed payload and decode the answer to the typed payload
+ *
+ *    Input : ctx context.Context - context to limit time of the Call
+ *			  action string - action of the Call
+ *			  request interface{} - payload struct of the request
+ *			  response interface{} - pointer to the payload struct of the response
+ *
+ *   Return : error - if happened, nil otherwise
+ */
+func (chargePoint *ChargePoint) call(ctx context.Context, action string, request interface{}, response interface{}) error {
+	payload, err := core.MarshalPayload(request)
+	if err != nil {
+		return err
+	}
+
+	callResult, err := chargePoint.SendCall(ctx, action, payload)
+	if err != nil {
+		return err
+	}
+
+	return core.UnmarshalPayload(callResult.Payload, response)
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::Authorize
+ *
+ *  Purpose : Send Authorize request to the Central System
+ *
+ *    Input : ctx context.Context - context to limit time of the Call
+ *			  request core.AuthorizeRequestPayload - payload of the request
+ *
+ *   Return : core.AuthorizeResponsePayload - answer of the Central System
+ *			  error - if happened, nil otherwise
+ */
+func (chargePoint *ChargePoint) Authorize(ctx context.Context, request core.AuthorizeRequestPayload) (core.AuthorizeResponsePayload, error) {
+	response := core.AuthorizeResponsePayload{}
+	err := chargePoint.call(ctx, core.ACTION_AUTHORIZE, request, &response)
+	return response, err
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::BootNotification
+ *
+ *  Purpose : Send BootNotification request to the Central System
+ *
+ *    Input : ctx context.Context - context to limit time of the Call
+ *			  request core.BootNotificationRequestPayload - payload of the request
+ *
+ *   Return : core.BootNotificationResponsePayload - answer of the Central System
+ *			  error - if happened, nil otherwise
+ */
+func (chargePoint *ChargePoint) BootNotification(ctx context.Context, request core.BootNotificationRequestPayload) (core.BootNotificationResponsePayload, error) {
+	response := core.BootNotificationResponsePayload{}
+	err := chargePoint.call(ctx, core.ACTION_BOOTNOTIFICATION, request, &response)
+	return response, err
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::DataTransfer
+ *
+ *  Purpose : Send DataTransfer request to the Central System
+ *
+ *    Input : ctx context.Context - context to limit time of the Call
+ *			  request core.DataTransferRequestPayload - payload of the request
+ *
+ *   Return : core.DataTransferResponsePayload - answer of the Central System
+ *			  error - if happened, nil otherwise
+ */
+func (chargePoint *ChargePoint) DataTransfer(ctx context.Context, request core.DataTransferRequestPayload) (core.DataTransferResponsePayload, error) {
+	response := core.DataTransferResponsePayload{}
+	err := chargePoint.call(ctx, core.ACTION_DATATRANSFER, request, &response)
+	return response, err
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::Heartbeat
+ *
+ *  Purpose : Send Heartbeat request to the Central System
+ *
+ *    Input : ctx context.Context - context to limit time of the Call
+ *
+ *   Return : core.HeartBeatResponse - answer of the Central System
+ *			  error - if happened, nil otherwise
+ */
+func (chargePoint *ChargePoint) Heartbeat(ctx context.Context) (core.HeartBeatResponse, error) {
+	response := core.HeartBeatResponse{}
+	err := chargePoint.call(ctx, core.ACTION_HEARTBEAT, core.HeartbeatRequestPayload{}, &response)
+	return response, err
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::MeterValues
+ *
+ *  Purpose : Send MeterValues request to the Central System
+ *
+ *    Input : ctx context.Context - context to limit time of the Call
+ *			  request core.MeterValuesRequestPayload - payload of the request
+ *
+ *   Return : error - if happened, nil otherwise
+ */
+func (chargePoint *ChargePoint) MeterValues(ctx context.Context, request core.MeterValuesRequestPayload) error {
+	response := core.MeterValuesResponsePayload{}
+	return chargePoint.call(ctx, core.ACTION_METERVALUES, request, &response)
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::StartTransaction
+ *
+ *  Purpose : Send StartTransaction request to the Central System
+ *
+ *    Input : ctx context.Context - context to limit time of the Call
+ *			  request core.StartTransactionRequestPayload - payload of the request
+ *
+ *   Return : core.StartTransactionResponsePayload - answer of the Central System
+ *			  error - if happened, nil otherwise
+ */
+func (chargePoint *ChargePoint) StartTransaction(ctx context.Context, request core.StartTransactionRequestPayload) (core.StartTransactionResponsePayload, error) {
+	response := core.StartTransactionResponsePayload{}
+	err := chargePoint.call(ctx, core.ACTION_STARTTRANSACTION, request, &response)
+	return response, err
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::StatusNotification
+ *
+ *  Purpose : Send StatusNotification request to the Central System
+ *
+ *    Input : ctx context.Context - context to limit time of the Call
+ *			  request core.StatusNotificationRequestPayload - payload of the request
+ *
+ *   Return : error - if happened, nil otherwise
+ */
+func (chargePoint *ChargePoint) StatusNotification(ctx context.Context, request core.StatusNotificationRequestPayload) error {
+	response := core.StatusNotificationResponsePayload{}
+	return chargePoint.call(ctx, core.ACTION_STATUSNOTIFICATION, request, &response)
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::StopTransaction
+ *
+ *  Purpose : Send StopTransaction request to the Central System
+ *
+ *    Input : ctx context.Context - context to limit time of the Call
+ *			  request core.StopTransactionRequestPayload - payload of the request
+ *
+ *   Return : core.StopTransactionResponsePayload - answer of the Central System
+ *			  error - if happened, nil otherwise
+ */
+func (chargePoint *ChargePoint) StopTransaction(ctx context.Context, request core.StopTransactionRequestPayload) (core.StopTransactionResponsePayload, error) {
+	response := core.StopTransactionResponsePayload{}
+	err := chargePoint.call(ctx, core.ACTION_STOPTRANSACTION, request, &response)
+	return response, err
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::write
+ *
+ *  Purpose : Send message to the Central System. WebSocket allows one writer at a time
+ *
+ *    Input : message string - message to send
+ *
+ *   Return : error - if happened, nil otherwise
+ */
+func (chargePoint *ChargePoint) write(message string) error {
+	select {
+	case <-chargePoint.done:
+		return core.ErrCallDisconnected
+	default:
+	}
+
+	chargePoint.writeMux.Lock()
+	defer chargePoint.writeMux.Unlock()
+
+	return chargePoint.conn.WriteMessage(websocket.TextMessage, []byte(message))
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::readMessages
+ *
+ *  Purpose : Goroutine method to read messages from the Central System.
+ *			  Calls are handled in own goroutine, so handler can send Call itself,
+ *			  as example BootNotification requested by TriggerMessage
+ *
+ *    Input : Nothing
+ *
+ *   Return : Nothing
+ */
+func (chargePoint *ChargePoint) readMessages() {
+	defer chargePoint.Close()
+
+	for {
+		_, rawMessage, err := chargePoint.conn.ReadMessage()
+		if err != nil {
+			select {
+			case <-chargePoint.done:
+			default:
+				chargePoint.reportError(errors.New(fmt.Sprintf("Connection is closed with error '%v'", err)))
+			}
+			return
+		}
+
+		if messageType, _, _ := messages.GetMessageTypeFromRaw(string(rawMessage)); messageType == int(messages.MESSAGE_TYPE_CALL) {
+			go chargePoint.handleMessage(string(rawMessage))
+			continue
+		}
+
+		chargePoint.handleMessage(string(rawMessage))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::handleMessage
+ *
+ *  Purpose : Handle message from the Central System and send the answer
+ *
+ *    Input : rawMessage string - message from the Central System
+ *
+ *   Return : Nothing
+ */
+func (chargePoint *ChargePoint) handleMessage(rawMessage string) {
+	response, err, socketStatus := chargePoint.handler.HandleIncomeMessage(rawMessage)
+	if err != nil {
+		chargePoint.reportError(err)
+	}
+
+	if response != "" {
+		if err := chargePoint.write(response); err != nil {
+			chargePoint.reportError(err)
+		}
+	}
+
+	if !socketStatus {
+		chargePoint.Close()
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePoint::reportError
+ *
+ *  Purpose : Pass error to the OnError callback if it is set
+ *
+ *    Input : err error - error to report
+ *
+ *   Return : Nothing
+ */
+func (chargePoint *ChargePoint) reportError(err error) {
+	if chargePoint.OnError != nil {
+		chargePoint.OnError(err)
+	}
+}
@@ -0,0 +1,195 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: chargepoint_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/chargepoint
+	Purpose: File with test cases for ChargePoint client
+	=============================================================================
+*/
+
+package chargepoint
+
+import (
+	"context"
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/core"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/****************************************************************************************
+ *	Struct 	: testCentralSystemHandlers
+ *
+ * 	Purpose : Central System handlers override BootNotification only
+ *
+*****************************************************************************************/
+type testCentralSystemHandlers struct {
+	core.CentralSystemHandlersBase
+}
+
+func (th *testCentralSystemHandlers) BootNotificationRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	bootNotificationResp := core.CreateBootNotificationResponsePayload(core.RegistrationStatusAccepted)
+	callResultMessage := messages.CreateCallResultMessage(callMessage.UniqueID, bootNotificationResp.GetPayload())
+	messageStr, err := callResultMessage.ToString()
+	return messageStr, err, true
+}
+
+/****************************************************************************************
+ *	Struct 	: testChargePointHandlers
+ *
+ * 	Purpose : Charge Point handlers override Reset only
+ *
+*****************************************************************************************/
+type testChargePointHandlers struct {
+	ChargePointHandlersBase
+}
+
+func (th *testChargePointHandlers) ResetRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	payload, _ := core.MarshalPayload(core.ResetResponsePayload{Status: core.ResetStatusAccepted})
+	callResultMessage := messages.CreateCallResultMessage(callMessage.UniqueID, payload)
+	messageStr, err := callResultMessage.ToString()
+	return messageStr, err, true
+}
+
+/****************************************************************************************
+ *	Struct 	: testCentralSystem
+ *
+ * 	Purpose : Central System with single connection for the tests
+ *
+*****************************************************************************************/
+type testCentralSystem struct {
+	server      *httptest.Server
+	calls       *core.CallTracker
+	conn        *websocket.Conn
+	path        string
+	subprotocol string
+	username    string
+	password    string
+	connected   chan struct{}
+	writeMux    sync.Mutex
+}
+
+/****************************************************************************************
+ *
+ * Function : startTestCentralSystem
+ *
+ *  Purpose : Start Central System which handles messages by core.RequestHandler
+ *
+ *    Input : t *testing.T - test object
+ *
+ *   Return : *testCentralSystem
+ */
+func startTestCentralSystem(t *testing.T) *testCentralSystem {
+	centralSystem := &testCentralSystem{calls: core.CallTrackerConstructor(), connected: make(chan struct{})}
+	upgrader := websocket.Upgrader{Subprotocols: []string{SUBPROTOCOL_OCPP16}}
+
+	centralSystem.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(fmt.Sprintf("Cannot upgrade connection with error '%v'", err))
+			return
+		}
+		defer conn.Close()
+
+		centralSystem.conn = conn
+		centralSystem.path = r.URL.Path
+		centralSystem.subprotocol = conn.Subprotocol()
+		centralSystem.username, centralSystem.password, _ = r.BasicAuth()
+		close(centralSystem.connected)
+
+		requestHandler := core.CentralSystemHandlerConstructor(&testCentralSystemHandlers{})
+		requestHandler.Calls = centralSystem.calls
+
+		for {
+			_, rawMessage, err := conn.ReadMessage()
+			if err != nil {
+				centralSystem.calls.Cancel()
+				return
+			}
+
+			response, _, _ := requestHandler.HandleIncomeMessage(string(rawMessage))
+			if response != "" {
+				centralSystem.write(response)
+			}
+		}
+	}))
+
+	return centralSystem
+}
+
+func (centralSystem *testCentralSystem) write(message string) error {
+	centralSystem.writeMux.Lock()
+	defer centralSystem.writeMux.Unlock()
+	return centralSystem.conn.WriteMessage(websocket.TextMessage, []byte(message))
+}
+
+/****************************************************************************************
+ *
+ * Function : TestChargePointCalls
+ *
+ *  Purpose : Test Calls of the Charge Point and the Central System over WebSocket
+ *
+ *   Return : Nothing
+ */
+func TestChargePointCalls(t *testing.T) {
+
+	centralSystem := startTestCentralSystem(t)
+	defer centralSystem.server.Close()
+
+	chargePoint := ChargePointConstructor("CP001", &testChargePointHandlers{})
+	chargePoint.SetBasicAuth("NIOERVB8REBOTIEBNRQ==")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	serverURL := "ws" + strings.TrimPrefix(centralSystem.server.URL, "http") + "/ocppj/1.6"
+	if err := chargePoint.Connect(ctx, serverURL); err != nil {
+		t.Fatal(fmt.Sprintf("Cannot connect with error '%v'", err))
+	}
+	defer chargePoint.Close()
+	<-centralSystem.connected
+
+	if centralSystem.path != "/ocppj/1.6/CP001" || chargePoint.Subprotocol != SUBPROTOCOL_OCPP16 {
+		t.Error(fmt.Sprintf("Wrong path '%v' or subprotocol '%v'", centralSystem.path, chargePoint.Subprotocol))
+	}
+
+	if centralSystem.username != "CP001" || centralSystem.password != "NIOERVB8REBOTIEBNRQ==" {
+		t.Error(fmt.Sprintf("Wrong Basic auth credentials '%v:%v'", centralSystem.username, centralSystem.password))
+	}
+
+	// Charge Point initiated Call is answered by the Central System handler
+	bootNotificationResp, err := chargePoint.BootNotification(ctx, core.BootNotificationRequestPayload{ChargePointVendor: "VendorX", ChargePointModel: "ModelY"})
+	if err != nil || bootNotificationResp.Status != core.RegistrationStatusAccepted || bootNotificationResp.HeartbeatInterval != 300 {
+		t.Error(fmt.Sprintf("Wrong BootNotification answer '%v' with error '%v'", bootNotificationResp, err))
+	}
+
+	// Action is not implemented by Central System
+	_, err = chargePoint.Heartbeat(ctx)
+	if callError, isCallError := err.(messages.CallErrorMessage); !isCallError || callError.ErrorCode != messages.ERROR_CODE_NOT_IMPLEMENTED {
+		t.Error(fmt.Sprintf("Expected NotImplemented CallError, got '%v'", err))
+	}
+
+	// Central System initiated Call is answered by the Charge Point handler
+	callResult, err := centralSystem.calls.Call(ctx, messages.CreateCallMessage("5001", core.ACTION_RESET, map[string]interface{}{"type": "Soft"}), centralSystem.write)
+	if err != nil || callResult.Payload["status"] != string(core.ResetStatusAccepted) {
+		t.Error(fmt.Sprintf("Wrong Reset answer '%v' with error '%v'", callResult, err))
+	}
+
+	// Action is not implemented by Charge Point
+	_, err = centralSystem.calls.Call(ctx, messages.CreateCallMessage("5002", core.ACTION_CLEARCACHE, map[string]interface{}{}), centralSystem.write)
+	if callError, isCallError := err.(messages.CallErrorMessage); !isCallError || callError.ErrorCode != messages.ERROR_CODE_NOT_IMPLEMENTED {
+		t.Error(fmt.Sprintf("Expected NotImplemented CallError, got '%v'", err))
+	}
+
+	// Outstanding Call is failed when connection is closed
+	chargePoint.Close()
+	<-chargePoint.Done()
+	if _, err := chargePoint.Heartbeat(ctx); err != core.ErrCallDisconnected {
+		t.Error(fmt.Sprintf("Expected disconnect error, got '%v'", err))
+	}
+}
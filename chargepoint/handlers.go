@@ -0,0 +1,301 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: handlers.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/chargepoint
+	Purpose: File keep routines to handle incoming OCPP messages on the Charge Point side
+	=============================================================================
+*/
+
+package chargepoint
+
+import (
+	"errors"
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/core"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+)
+
+/****************************************************************************************
+ *	Interface : ChargePointHandlers
+ *
+ * 	  Purpose : Callbacks the Charge Point has to implement to handle Calls
+ *				initiated by the Central System.
+ *				Embed ChargePointHandlersBase to get NotImplemented defaults.
+ *
+ *	Request handlers return:
+ *			  string - response message in string format
+ *			  error - if happened, nil otherwise
+ *			  bool - false - when connection to the Central System needs to be closed, otherwise true
+ *
+*****************************************************************************************/
+type ChargePointHandlers interface {
+	// Call handlers for the Central System initiated actions
+	CancelReservationRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	ChangeAvailabilityRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	ChangeConfigurationRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	ClearCacheRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	ClearChargingProfileRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	DataTransferRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	GetCompositeScheduleRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	GetConfigurationRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	GetDiagnosticsRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	GetLocalListVersionRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	RemoteStartTransactionRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	RemoteStopTransactionRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	ReserveNowRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	ResetRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	SendLocalListRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	SetChargingProfileRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	TriggerMessageRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	UnlockConnectorRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	UpdateFirmwareRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+}
+
+/****************************************************************************************
+ *	Struct 	: RequestHandler
+ *
+ * 	Purpose : Object handles the OCPP messages received from the Central System
+ *
+*****************************************************************************************/
+type RequestHandler struct {
+	APIhandlers ChargePointHandlers
+	Calls       *core.CallTracker // Tracker of the Calls sent to the Central System
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargePointHandlerConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the RequestHandler
+ *
+ *	  Input : callbackRoutines ChargePointHandlers - routines to handle OCPP requests
+ *
+ *	Return : RequestHandler object
+ */
+func ChargePointHandlerConstructor(callbackRoutines ChargePointHandlers) RequestHandler {
+	rh := RequestHandler{}
+	rh.APIhandlers = callbackRoutines
+	rh.Calls = core.CallTrackerConstructor()
+	return rh
+}
+
+/****************************************************************************************
+ *
+ * Function : RequestHandler::callRequestHandler
+ *
+ *  Purpose : Call request handler for the action of the Call message
+ *
+ *	  Input : callMessage messages.CallMessage - income Call message
+ *
+ *	Return : string - response
+ *			 error - if happened, nil otherwise
+ *			 bool - true if needs to keep websocket open, false otherwise
+ */
+func (requestHandler *RequestHandler) callRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+
+	handlers := requestHandler.APIhandlers
+
+	switch callMessage.Action {
+	case core.ACTION_CANCELRESERVATION:
+		return handlers.CancelReservationRequestHandler(callMessage)
+	case core.ACTION_CHANGEAVAILABILITY:
+		return handlers.ChangeAvailabilityRequestHandler(callMessage)
+	case core.ACTION_CHANGECONFIGURATION:
+		return handlers.ChangeConfigurationRequestHandler(callMessage)
+	case core.ACTION_CLEARCACHE:
+		return handlers.ClearCacheRequestHandler(callMessage)
+	case core.ACTION_CLEARCHARGINGPROFILE:
+		return handlers.ClearChargingProfileRequestHandler(callMessage)
+	case core.ACTION_DATATRANSFER:
+		return handlers.DataTransferRequestHandler(callMessage)
+	case core.ACTION_GETCOMPOSITESCHEDULE:
+		return handlers.GetCompositeScheduleRequestHandler(callMessage)
+	case core.ACTION_GETCONFIGURATION:
+		return handlers.GetConfigurationRequestHandler(callMessage)
+	case core.ACTION_GETDIAGNOSTICS:
+		return handlers.GetDiagnosticsRequestHandler(callMessage)
+	case core.ACTION_GETLOCALLISTVERSION:
+		return handlers.GetLocalListVersionRequestHandler(callMessage)
+	case core.ACTION_REMOTESTARTTRANSACTION:
+		return handlers.RemoteStartTransactionRequestHandler(callMessage)
+	case core.ACTION_REMOTESTOPTRANSACTION:
+		return handlers.RemoteStopTransactionRequestHandler(callMessage)
+	case core.ACTION_RESERVENOW:
+		return handlers.ReserveNowRequestHandler(callMessage)
+	case core.ACTION_RESET:
+		return handlers.ResetRequestHandler(callMessage)
+	case core.ACTION_SENDLOCALLIST:
+		return handlers.SendLocalListRequestHandler(callMessage)
+	case core.ACTION_SETCHARGINGPROFILE:
+		return handlers.SetChargingProfileRequestHandler(callMessage)
+	case core.ACTION_TRIGGERMESSAGE:
+		return handlers.TriggerMessageRequestHandler(callMessage)
+	case core.ACTION_UNLOCKCONNECTOR:
+		return handlers.UnlockConnectorRequestHandler(callMessage)
+	case core.ACTION_UPDATEFIRMWARE:
+		return handlers.UpdateFirmwareRequestHandler(callMessage)
+	}
+
+	// Action is not known by the Charge Point
+	err := errors.New(fmt.Sprintf("Cannot find CallRequest handler for action '%v'", callMessage.Action))
+	return core.CreateCallError(callMessage.UniqueID, messages.ERROR_CODE_NOT_IMPLEMENTED, err.Error()), err, true
+}
+
+/****************************************************************************************
+ *
+ * Function : RequestHandler::handleCall
+ *
+ *  Purpose : Validate payload of the Call message and call request handler.
+ *			  Central System always gets an answer: CallError is created when payload
+ *			  is not valid or handler failed without response
+ *
+ *	  Input : callMessage messages.CallMessage - income Call message
+ *
+ *	Return : string - response
+ *			 error - if happened, nil otherwise
+ *			 bool - true if needs to keep websocket open, false otherwise
+ */
+func (requestHandler *RequestHandler) handleCall(callMessage messages.CallMessage) (string, error, bool) {
+
+	// Validate payload by schema and by the payload struct before it reaches the handler
+	if err := callMessage.Validate(); err != nil {
+		return core.CreateCallErrorFromError(callMessage.UniqueID, err), err, true
+	}
+
+	if payloadStruct := newRequestPayload(callMessage.Action); payloadStruct != nil {
+		if err := core.DecodePayload(callMessage.Payload, payloadStruct); err != nil {
+			return core.CreateCallErrorFromError(callMessage.UniqueID, err), err, true
+		}
+	}
+
+	response, err, socketStatus := requestHandler.callRequestHandler(callMessage)
+	if response == "" && err != nil {
+		return core.CreateCallErrorFromError(callMessage.UniqueID, err), err, socketStatus
+	}
+
+	// Central System must not get CallResult which is not valid by schema
+	if messageType, _, _ := messages.GetMessageTypeFromRaw(response); messageType == int(messages.MESSAGE_TYPE_CALL_RESULT) {
+		callResult := messages.CallResultMessageCreator(response)
+		if errSchema := messages.ValidateResponsePayload(callMessage.Action, callResult.Payload); errSchema != nil {
+			errResponse := errors.New(fmt.Sprintf("CallResult for action '%v' is not valid: %v", callMessage.Action, errSchema))
+			return core.CreateCallError(callMessage.UniqueID, messages.ERROR_CODE_INTERNAL_ERROR, errResponse.Error()), errResponse, socketStatus
+		}
+	}
+
+	return response, err, socketStatus
+}
+
+/****************************************************************************************
+ *
+ * Function : newRequestPayload
+ *
+ *  Purpose : Create payload struct of the Central System initiated action
+ *
+ *	  Input : action string - action of the Call message
+ *
+ *	Return : interface{} - pointer to the empty payload struct, nil when there is no struct for action
+ */
+func newRequestPayload(action string) interface{} {
+	switch action {
+	case core.ACTION_CHANGEAVAILABILITY:
+		return &core.ChangeAvailabilityRequestPayload{}
+	case core.ACTION_CHANGECONFIGURATION:
+		return &core.ChangeConfigurationRequestPayload{}
+	case core.ACTION_CLEARCACHE:
+		return &core.ClearCacheRequestPayload{}
+	case core.ACTION_DATATRANSFER:
+		return &core.DataTransferRequestPayload{}
+	case core.ACTION_GETCONFIGURATION:
+		return &core.GetConfigurationRequestPayload{}
+	case core.ACTION_REMOTESTARTTRANSACTION:
+		return &core.RemoteStartTransactionRequestPayload{}
+	case core.ACTION_REMOTESTOPTRANSACTION:
+		return &core.RemoteStopTransactionRequestPayload{}
+	case core.ACTION_RESET:
+		return &core.ResetRequestPayload{}
+	case core.ACTION_TRIGGERMESSAGE:
+		return &core.TriggerMessageRequestPayload{}
+	case core.ACTION_UNLOCKCONNECTOR:
+		return &core.UnlockConnectorRequestPayload{}
+	}
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : RequestHandler::HandleIncomeMessage
+ *
+ *  Purpose : Parse and validate message from the Central System.
+ *			  Call is passed to the request handler, CallResult and CallError
+ *			  are passed to the outstanding Call
+ *
+ *    Input : rawMessage string - raw message to parse and validate
+ *
+ *   Return : string - response
+ *			  error - if happened, nil otherwise
+ *			  bool - true if needs to keep websocket open, false otherwise
+ */
+func (requestHandler *RequestHandler) HandleIncomeMessage(rawMessage string) (string, error, bool) {
+
+	// First check for income message
+	if rawMessage == "" {
+		return "", errors.New("Body of the request is empty"), true
+	}
+
+	// Get message type from the raw text
+	messageType, uniqueID, errMessageType := messages.GetMessageTypeFromRaw(rawMessage)
+	if errMessageType != nil {
+		// Message is not OCPP-J frame
+		return core.CreateCallError(uniqueID, messages.ERROR_CODE_FORMATION_VIOLATION, errMessageType.Error()), errMessageType, true
+	}
+
+	// Handle Call message
+	if messageType == int(messages.MESSAGE_TYPE_CALL) {
+		// Create CallMessage obj from raw message
+		callMessageObj, errCallMessage := messages.CallMessageParser(rawMessage)
+		if errCallMessage != nil {
+			return core.CreateCallError(uniqueID, messages.ERROR_CODE_FORMATION_VIOLATION, errCallMessage.Error()), errCallMessage, true
+		}
+
+		return requestHandler.handleCall(callMessageObj)
+	}
+
+	// Handle Call Result message
+	if messageType == int(messages.MESSAGE_TYPE_CALL_RESULT) {
+		// Create CallResultMessage obj from raw message
+		callResultObj := messages.CallResultMessageCreator(rawMessage)
+
+		// Action is not exist in CallResult message, it is taken from the outstanding Call
+		action, isOutstanding := requestHandler.Calls.GetAction(callResultObj.UniqueID)
+		if !isOutstanding {
+			return "", errors.New(fmt.Sprintf("There is no outstanding Call for CallResult '%v'", callResultObj.UniqueID)), true
+		}
+
+		// CallResult which is not valid by schema fails the Call
+		if errSchema := messages.ValidateResponsePayload(action, callResultObj.Payload); errSchema != nil {
+			err := errors.New(fmt.Sprintf("CallResult '%v' for action '%v' is not valid: %v", callResultObj.UniqueID, action, errSchema))
+			requestHandler.Calls.Fail(callResultObj.UniqueID, err)
+			return "", err, true
+		}
+
+		// Pass CallResult to the waiting Call
+		requestHandler.Calls.Resolve(callResultObj)
+		return "", nil, true
+	}
+
+	// Handle Call Error message
+	if messageType == int(messages.MESSAGE_TYPE_CALL_ERROR) {
+		// Create CallErrorMessage obj from raw message
+		callErrorObj := messages.CallErrorMessageCreator(rawMessage)
+
+		// Pass CallError to the waiting Call
+		if !requestHandler.Calls.Reject(callErrorObj) {
+			return "", errors.New(fmt.Sprintf("There is no outstanding Call for CallError '%v'", callErrorObj.UniqueID)), true
+		}
+		return "", nil, true
+	}
+
+	return "", errors.New(fmt.Sprintf("Handler for Type Message '%v' is not found", messageType)), true
+}
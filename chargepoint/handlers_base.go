@@ -0,0 +1,150 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: handlers_base.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/chargepoint
+	Purpose: Default implementation of the ChargePointHandlers interface.
+			 Every handler replies that action is not implemented
+	=============================================================================
+*/
+
+package chargepoint
+
+import (
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/core"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+)
+
+/****************************************************************************************
+ *	Struct 	: ChargePointHandlersBase
+ *
+ * 	Purpose : Object implements ChargePointHandlers with NotImplemented defaults.
+ *			  Embed it to the own handlers struct and override required handlers only
+ *
+*****************************************************************************************/
+type ChargePointHandlersBase struct{}
+
+// Make sure that base struct implements all handlers
+var _ ChargePointHandlers = (*ChargePointHandlersBase)(nil)
+
+/****************************************************************************************
+ *
+ * Function : notImplementedRequest
+ *
+ *  Purpose : Create NotImplemented CallError as response on the Call message
+ *
+ *    Input : callMessage messages.CallMessage - original Call message
+ *
+ *   Return : string - response message in string format
+ *			  error - if happened, nil otherwise
+ *			  bool - false - when connection to the Central System needs to be closed, otherwise true
+ */
+func notImplementedRequest(callMessage messages.CallMessage) (string, error, bool) {
+	response := core.CreateCallError(
+		callMessage.UniqueID,
+		messages.ERROR_CODE_NOT_IMPLEMENTED,
+		fmt.Sprintf("Action '%v' is not implemented", callMessage.Action),
+	)
+
+	return response, nil, true
+}
+
+/* Define Call Handlers =========================================================================================
+=================================================================================================================
+*/
+
+// CancelReservationRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) CancelReservationRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// ChangeAvailabilityRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) ChangeAvailabilityRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// ChangeConfigurationRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) ChangeConfigurationRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// ClearCacheRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) ClearCacheRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// ClearChargingProfileRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) ClearChargingProfileRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// DataTransferRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) DataTransferRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// GetCompositeScheduleRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) GetCompositeScheduleRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// GetConfigurationRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) GetConfigurationRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// GetDiagnosticsRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) GetDiagnosticsRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// GetLocalListVersionRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) GetLocalListVersionRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// RemoteStartTransactionRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) RemoteStartTransactionRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// RemoteStopTransactionRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) RemoteStopTransactionRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// ReserveNowRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) ReserveNowRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// ResetRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) ResetRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// SendLocalListRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) SendLocalListRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// SetChargingProfileRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) SetChargingProfileRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// TriggerMessageRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) TriggerMessageRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// UnlockConnectorRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) UnlockConnectorRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// UpdateFirmwareRequestHandler replies NotImplemented
+func (base *ChargePointHandlersBase) UpdateFirmwareRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
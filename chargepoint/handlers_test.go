@@ -0,0 +1,53 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: handlers_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/chargepoint
+	Purpose: File with test cases for Charge Point RequestHandler
+	=============================================================================
+*/
+
+package chargepoint
+
+import (
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+	"testing"
+)
+
+/****************************************************************************************
+ *
+ * Function : TestHandleIncomeMessageCallError
+ *
+ *  Purpose : Test that Central System gets CallError when Call cannot be handled
+ *
+ *   Return : Nothing
+ */
+func TestHandleIncomeMessageCallError(t *testing.T) {
+
+	chargePoint := ChargePointHandlerConstructor(&testChargePointHandlers{})
+
+	testCases := []struct {
+		rawMessage string
+		errorCode  messages.ErrorCode
+	}{
+		{"[2,\"6001\",\"Reset\",{\"type\":\"Warm\"}]", messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION},
+		{"[2,\"6002\",\"Reset\",{}]", messages.ERROR_CODE_OCCURENCE_CONSTRAINT_VIOLATION},
+		{"[2,\"6003\",\"UnlockConnector\",{\"connectorId\":1}]", messages.ERROR_CODE_NOT_IMPLEMENTED},
+		{"[2,\"6004\",\"Heartbeat\",{}]", messages.ERROR_CODE_NOT_IMPLEMENTED},
+	}
+
+	for _, testCase := range testCases {
+		response, _, _ := chargePoint.HandleIncomeMessage(testCase.rawMessage)
+
+		callError := messages.CallErrorMessageCreator(response)
+		if callError.ErrorCode != testCase.errorCode {
+			t.Error(fmt.Sprintf("Message '%v' expected '%v', got '%v'", testCase.rawMessage, testCase.errorCode, response))
+		}
+	}
+
+	// CallResult which is not expected by the Charge Point
+	if _, err, _ := chargePoint.HandleIncomeMessage("[3,\"6005\",{}]"); err == nil {
+		t.Error("CallResult without outstanding Call must be reported")
+	}
+}
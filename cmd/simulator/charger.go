@@ -0,0 +1,389 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: charger.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/cmd/simulator
+	Purpose: Virtual charger which runs the scenario against the Central System:
+			 boot, heartbeats, plug in, authorize, start transaction,
+			 meter values and stop transaction
+	=============================================================================
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/CoderSergiy/golib/logging"
+	"github.com/CoderSergiy/ocpp16-go/chargepoint"
+	"github.com/CoderSergiy/ocpp16-go/core"
+	"strconv"
+	"time"
+)
+
+/****************************************************************************************
+ *	Struct 	: VirtualCharger
+ *
+ * 	Purpose : Struct handles state of the single virtual charger
+ *
+*****************************************************************************************/
+type VirtualCharger struct {
+	Name        string
+	IdTag       string // Identifier presented to start the transaction
+	scenario    *Scenario
+	stats       *Stats
+	log         *logging.Log
+	chargePoint *chargepoint.ChargePoint
+	meterValue  int // Energy register of the connector in Wh
+}
+
+/****************************************************************************************
+ *	Struct 	: virtualChargerHandlers
+ *
+ * 	Purpose : Virtual charger does not handle Central System initiated Calls
+ *
+*****************************************************************************************/
+type virtualChargerHandlers struct {
+	chargepoint.ChargePointHandlersBase
+}
+
+/****************************************************************************************
+ *
+ * Function : VirtualChargerConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the VirtualCharger
+ *
+ *	  Input : chargerName string - name of the charger configured on the server
+ *			  idTag string - identifier to start the transactions
+ *			  scenario *Scenario - scenario to run
+ *			  stats *Stats - counters shared by all chargers
+ *			  log *logging.Log - pointer to the log
+ *
+ *	Return : *VirtualCharger object
+ */
+func VirtualChargerConstructor(chargerName string, idTag string, scenario *Scenario, stats *Stats, log *logging.Log) *VirtualCharger {
+	virtualCharger := VirtualCharger{}
+	virtualCharger.Name = chargerName
+	virtualCharger.IdTag = idTag
+	virtualCharger.scenario = scenario
+	virtualCharger.stats = stats
+	virtualCharger.log = log
+	virtualCharger.chargePoint = chargepoint.ChargePointConstructor(chargerName, &virtualChargerHandlers{})
+	virtualCharger.chargePoint.TLSConfig = scenario.tlsConfig
+	if scenario.Password != "" {
+		virtualCharger.chargePoint.SetBasicAuth(scenario.Password)
+	}
+	virtualCharger.chargePoint.OnError = func(err error) {
+		log.Error_Log("[%v] %v", chargerName, err)
+	}
+	return &virtualCharger
+}
+
+/****************************************************************************************
+ *
+ * Function : VirtualCharger::Run
+ *
+ *  Purpose : Connect to the Central System and run the scenario
+ *
+ *    Input : ctx context.Context - context to stop the simulation
+ *
+ *   Return : error - if happened, nil otherwise
+ */
+func (virtualCharger *VirtualCharger) Run(ctx context.Context) error {
+	err := virtualCharger.measure(ctx, "Connect", func(ctx context.Context) error {
+		return virtualCharger.chargePoint.Connect(ctx, virtualCharger.scenario.ServerURL)
+	})
+	if err != nil {
+		return err
+	}
+	defer virtualCharger.chargePoint.Close()
+
+	virtualCharger.log.Info_Log("[%v] Connected with subprotocol '%v'", virtualCharger.Name, virtualCharger.chargePoint.Subprotocol)
+
+	heartbeatInterval, err := virtualCharger.boot(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Heartbeats are sent during the whole scenario
+	heartbeatCtx, stopHeartbeats := context.WithCancel(ctx)
+	defer stopHeartbeats()
+	go virtualCharger.heartbeats(heartbeatCtx, heartbeatInterval)
+
+	if err := virtualCharger.statusNotification(ctx, core.ChargePointStatusAvailable); err != nil {
+		return err
+	}
+
+	for cycle := 0; cycle < virtualCharger.scenario.Cycles; cycle++ {
+		if err := virtualCharger.chargingCycle(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : VirtualCharger::boot
+ *
+ *  Purpose : Send BootNotification and get the heartbeat interval
+ *
+ *    Input : ctx context.Context - context to stop the simulation
+ *
+ *   Return : time.Duration - interval of the heartbeats
+ *			  error - if happened, nil otherwise
+ */
+func (virtualCharger *VirtualCharger) boot(ctx context.Context) (time.Duration, error) {
+	bootNotificationResp := core.BootNotificationResponsePayload{}
+
+	err := virtualCharger.measure(ctx, core.ACTION_BOOTNOTIFICATION, func(ctx context.Context) error {
+		var err error
+		bootNotificationResp, err = virtualCharger.chargePoint.BootNotification(ctx, core.BootNotificationRequestPayload{
+			ChargePointVendor: virtualCharger.scenario.ChargePointVendor,
+			ChargePointModel:  virtualCharger.scenario.ChargePointModel,
+		})
+		if err == nil && bootNotificationResp.Status != core.RegistrationStatusAccepted {
+			err = errors.New(fmt.Sprintf("Registration status is '%v'", bootNotificationResp.Status))
+		}
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	heartbeatInterval := bootNotificationResp.HeartbeatInterval
+	if virtualCharger.scenario.HeartbeatInterval > 0 {
+		heartbeatInterval = virtualCharger.scenario.HeartbeatInterval
+	}
+
+	virtualCharger.log.Info_Log("[%v] Boot is accepted, heartbeat interval is %v seconds", virtualCharger.Name, heartbeatInterval)
+
+	return time.Duration(heartbeatInterval) * time.Second, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : VirtualCharger::heartbeats
+ *
+ *  Purpose : Goroutine method to send Heartbeat with interval till ctx is done
+ *
+ *    Input : ctx context.Context - context to stop the heartbeats
+ *			  interval time.Duration - interval of the heartbeats
+ *
+ *   Return : Nothing
+ */
+func (virtualCharger *VirtualCharger) heartbeats(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			virtualCharger.measure(ctx, core.ACTION_HEARTBEAT, func(ctx context.Context) error {
+				_, err := virtualCharger.chargePoint.Heartbeat(ctx)
+				return err
+			})
+		}
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : VirtualCharger::chargingCycle
+ *
+ *  Purpose : Plug in, authorize, charge and unplug
+ *
+ *    Input : ctx context.Context - context to stop the simulation
+ *
+ *   Return : error - if happened, nil otherwise
+ */
+func (virtualCharger *VirtualCharger) chargingCycle(ctx context.Context) error {
+	scenario := virtualCharger.scenario
+
+	if err := sleep(ctx, time.Duration(scenario.PlugInDelay)*time.Second); err != nil {
+		return err
+	}
+
+	if err := virtualCharger.statusNotification(ctx, core.ChargePointStatusPreparing); err != nil {
+		return err
+	}
+
+	err := virtualCharger.measure(ctx, core.ACTION_AUTHORIZE, func(ctx context.Context) error {
+		authorizeResp, err := virtualCharger.chargePoint.Authorize(ctx, core.AuthorizeRequestPayload{IdTag: virtualCharger.IdTag})
+		if err == nil && authorizeResp.IdTagInfo.Status != core.AuthorizationStatusAccepted {
+			err = errors.New(fmt.Sprintf("IdTag '%v' is not accepted, status '%v'", virtualCharger.IdTag, authorizeResp.IdTagInfo.Status))
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	transactionId := 0
+	err = virtualCharger.measure(ctx, core.ACTION_STARTTRANSACTION, func(ctx context.Context) error {
+		startTransactionResp, err := virtualCharger.chargePoint.StartTransaction(ctx, core.StartTransactionRequestPayload{
+			ConnectorId: scenario.ConnectorId,
+			IdTag:       virtualCharger.IdTag,
+			MeterStart:  virtualCharger.meterValue,
+			Timestamp:   core.FormatDateTime(time.Now()),
+		})
+		if err == nil && startTransactionResp.IdTagInfo.Status != core.AuthorizationStatusAccepted {
+			err = errors.New(fmt.Sprintf("Transaction is not accepted, status '%v'", startTransactionResp.IdTagInfo.Status))
+		}
+		transactionId = startTransactionResp.TransactionId
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := virtualCharger.statusNotification(ctx, core.ChargePointStatusCharging); err != nil {
+		return err
+	}
+
+	for index := 0; index < scenario.MeterValues; index++ {
+		if err := sleep(ctx, time.Duration(scenario.MeterValuesInterval)*time.Second); err != nil {
+			return err
+		}
+
+		virtualCharger.meterValue += scenario.EnergyPerInterval
+		err := virtualCharger.measure(ctx, core.ACTION_METERVALUES, func(ctx context.Context) error {
+			return virtualCharger.chargePoint.MeterValues(ctx, core.MeterValuesRequestPayload{
+				ConnectorId:   scenario.ConnectorId,
+				TransactionId: transactionId,
+				MeterValue:    []core.MeterValue{virtualCharger.energyRegister(core.ReadingContextSamplePeriodic)},
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = virtualCharger.measure(ctx, core.ACTION_STOPTRANSACTION, func(ctx context.Context) error {
+		_, err := virtualCharger.chargePoint.StopTransaction(ctx, core.StopTransactionRequestPayload{
+			IdTag:         virtualCharger.IdTag,
+			MeterStop:     virtualCharger.meterValue,
+			Timestamp:     core.FormatDateTime(time.Now()),
+			TransactionId: transactionId,
+			Reason:        core.ReasonLocal,
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := virtualCharger.statusNotification(ctx, core.ChargePointStatusFinishing); err != nil {
+		return err
+	}
+
+	return virtualCharger.statusNotification(ctx, core.ChargePointStatusAvailable)
+}
+
+/****************************************************************************************
+ *
+ * Function : VirtualCharger::statusNotification
+ *
+ *  Purpose : Report status of the connector from the scenario
+ *
+ *    Input : ctx context.Context - context to stop the simulation
+ *			  status core.ChargePointStatus - new status of the connector
+ *
+ *   Return : error - if happened, nil otherwise
+ */
+func (virtualCharger *VirtualCharger) statusNotification(ctx context.Context, status core.ChargePointStatus) error {
+	return virtualCharger.measure(ctx, core.ACTION_STATUSNOTIFICATION, func(ctx context.Context) error {
+		return virtualCharger.chargePoint.StatusNotification(ctx, core.StatusNotificationRequestPayload{
+			ConnectorId: virtualCharger.scenario.ConnectorId,
+			ErrorCode:   core.ChargePointErrorCodeNoError,
+			Status:      status,
+			Timestamp:   core.FormatDateTime(time.Now()),
+		})
+	})
+}
+
+/****************************************************************************************
+ *
+ * Function : VirtualCharger::energyRegister
+ *
+ *  Purpose : Create MeterValue with current energy register of the connector
+ *
+ *    Input : readingContext core.ReadingContext - context of the reading
+ *
+ *   Return : core.MeterValue
+ */
+func (virtualCharger *VirtualCharger) energyRegister(readingContext core.ReadingContext) core.MeterValue {
+	return core.MeterValue{
+		Timestamp: core.FormatDateTime(time.Now()),
+		SampledValue: []core.SampledValue{{
+			Value:     strconv.Itoa(virtualCharger.meterValue),
+			Context:   readingContext,
+			Measurand: core.MeasurandEnergyActiveImportRegister,
+			Unit:      core.UnitOfMeasureWh,
+		}},
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : VirtualCharger::measure
+ *
+ *  Purpose : Run the step limited by CallTimeout and record its latency and result
+ *
+ *    Input : ctx context.Context - context to stop the simulation
+ *			  action string - name of the step
+ *			  step func(context.Context) error - step to run
+ *
+ *   Return : error - error of the step
+ */
+func (virtualCharger *VirtualCharger) measure(ctx context.Context, action string, step func(context.Context) error) error {
+	stepCtx, cancel := context.WithTimeout(ctx, time.Duration(virtualCharger.scenario.CallTimeout)*time.Second)
+	defer cancel()
+
+	started := time.Now()
+	err := step(stepCtx)
+
+	// Step interrupted by the end of the simulation is not counted
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	virtualCharger.stats.Record(action, time.Since(started), err)
+
+	if err != nil {
+		virtualCharger.log.Error_Log("[%v] %v is failed with error '%v'", virtualCharger.Name, action, err)
+		return errors.New(fmt.Sprintf("%v is failed with error '%v'", action, err))
+	}
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : sleep
+ *
+ *  Purpose : Wait for the duration or till ctx is done
+ *
+ *    Input : ctx context.Context - context to stop the waiting
+ *			  duration time.Duration - time to wait
+ *
+ *   Return : error - error of the context when it is done first, nil otherwise
+ */
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
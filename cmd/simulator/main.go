@@ -0,0 +1,126 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: main.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/cmd/simulator
+	Purpose: Charge Point simulator for the local load and scenario testing.
+			 Spins up virtual chargers from the scenario file, connects them to
+			 the Central System and reports latency and error counters per action
+
+	Usage:
+		simulator -scenario cmd/simulator/scenario.json -count 50 -url ws://localhost:9033/ocppj/1.6
+	=============================================================================
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/CoderSergiy/golib/logging"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	logFilesPath string = "/tmp/logs/simulator"
+)
+
+/****************************************************************************************
+ *
+ * Function : main
+ *
+ *  Purpose : Main method to start simulation
+ *
+ *   Return : Nothing
+ */
+func main() {
+	scenarioFilePath := flag.String("scenario", "cmd/simulator/scenario.json", "Path to the scenario file")
+	count := flag.Int("count", 0, "Number of virtual chargers, overrides Count of the scenario")
+	serverURL := flag.String("url", "", "Endpoint of the Central System, overrides ServerURL of the scenario")
+	flag.Parse()
+
+	log := logging.LogConstructor(logFilesPath, true)
+
+	scenario, err := SetScenarioFromFile(*scenarioFilePath)
+	if *count > 0 {
+		scenario.Count = *count
+	}
+	if *serverURL != "" {
+		scenario.ServerURL = *serverURL
+	}
+	if err == nil {
+		err = scenario.Validate()
+	}
+	if err == nil {
+		scenario.tlsConfig, err = scenario.TLSConfig()
+	}
+	if err != nil {
+		log.Error_Log("Cannot set scenario from file '%v' with error '%v'", *scenarioFilePath, err)
+		os.Exit(1)
+	}
+
+	// Stop simulation by Ctrl+C, counters are reported anyway
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stats := StatsConstructor()
+	started := time.Now()
+	failedChargers := runSimulation(ctx, &scenario, stats, &log)
+
+	fmt.Printf("\nSimulation of %v chargers is finished in %v, failed chargers: %v\n\n", len(scenario.ChargerNames()), time.Since(started).Round(time.Millisecond), failedChargers)
+	fmt.Print(stats.Report())
+
+	if failedChargers > 0 || stats.Errors() > 0 {
+		os.Exit(1)
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : runSimulation
+ *
+ *  Purpose : Run scenario for every virtual charger and wait till all of them are finished
+ *
+ *    Input : ctx context.Context - context to stop the simulation
+ *			  scenario *Scenario - scenario to run
+ *			  stats *Stats - counters of the actions
+ *			  log *logging.Log - pointer to the log
+ *
+ *   Return : int - number of chargers failed to finish the scenario
+ */
+func runSimulation(ctx context.Context, scenario *Scenario, stats *Stats, log *logging.Log) int {
+	var waitGroup sync.WaitGroup
+	var failedMux sync.Mutex
+	failedChargers := 0
+
+	for index, chargerName := range scenario.ChargerNames() {
+		// Ramp up chargers to not get all handshakes at the same moment
+		if err := sleep(ctx, time.Duration(scenario.ConnectInterval)*time.Millisecond); err != nil {
+			break
+		}
+
+		waitGroup.Add(1)
+		go func(virtualCharger *VirtualCharger) {
+			defer waitGroup.Done()
+
+			if err := virtualCharger.Run(ctx); err != nil {
+				log.Error_Log("[%v] Scenario is failed with error '%v'", virtualCharger.Name, err)
+				failedMux.Lock()
+				failedChargers++
+				failedMux.Unlock()
+				return
+			}
+
+			log.Info_Log("[%v] Scenario is finished", virtualCharger.Name)
+		}(VirtualChargerConstructor(chargerName, scenario.IdTag(index), scenario, stats, log))
+	}
+
+	waitGroup.Wait()
+
+	return failedChargers
+}
@@ -0,0 +1,200 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: scenario.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/cmd/simulator
+	Purpose: Scenario of the virtual chargers loaded from the file
+	=============================================================================
+*/
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+/****************************************************************************************
+ *	Struct 	: Scenario
+ *
+ * 	Purpose : Struct handles parameters of the simulation.
+ *			  Intervals and delays are in seconds, ConnectInterval is in milliseconds
+ *
+*****************************************************************************************/
+type Scenario struct {
+	ServerURL           string      `json:"ServerURL"`           // Endpoint of the Central System without charger name
+	Chargers            []string    `json:"Chargers"`            // Names of the chargers configured on the server
+	Count               int         `json:"Count"`               // Number of virtual chargers, 0 - one per name in Chargers
+	ChargerNamePrefix   string      `json:"ChargerNamePrefix"`   // Prefix of the generated names when Count exceeds Chargers
+	ConnectInterval     int         `json:"ConnectInterval"`     // Delay between connections of the chargers
+	Password            string      `json:"Password"`            // AuthorizationKey of the Basic auth, empty - no authorization
+	ChargePointVendor   string      `json:"ChargePointVendor"`   // Vendor reported by BootNotification
+	ChargePointModel    string      `json:"ChargePointModel"`    // Model reported by BootNotification
+	HeartbeatInterval   int         `json:"HeartbeatInterval"`   // 0 - use interval returned by BootNotification
+	ConnectorId         int         `json:"ConnectorId"`         // Connector used for the transactions
+	IdTags              []string    `json:"IdTags"`              // Identifiers to start the transactions, shared round-robin by chargers
+	PlugInDelay         int         `json:"PlugInDelay"`         // Delay before the cable is plugged in
+	MeterValues         int         `json:"MeterValues"`         // Number of MeterValues sent during the transaction
+	MeterValuesInterval int         `json:"MeterValuesInterval"` // Interval between MeterValues
+	EnergyPerInterval   int         `json:"EnergyPerInterval"`   // Energy in Wh consumed between MeterValues
+	Cycles              int         `json:"Cycles"`              // Number of transactions per charger
+	CallTimeout         int         `json:"CallTimeout"`         // Time to wait for the answer of the Central System
+	CAFile              string      `json:"CAFile"`              // CA bundle to verify the server certificate of wss:// endpoint
+	ClientCertFile      string      `json:"ClientCertFile"`      // Client certificate of the chargers (Security Profile 3)
+	ClientKeyFile       string      `json:"ClientKeyFile"`       // Private key of the client certificate
+	tlsConfig           *tls.Config // Loaded from the certificate files before the simulation
+}
+
+/****************************************************************************************
+ *
+ * Function : SetScenarioFromFile (Constructor)
+ *
+ *  Purpose : Creates a new instance of the Scenario from the file
+ *
+ *	  Input : fileName string - filename with scenario in JSON format
+ *
+ *	 Return : Scenario object
+ * 			  error - error if happened
+ */
+func SetScenarioFromFile(fileName string) (Scenario, error) {
+	scenario := Scenario{Cycles: 1, CallTimeout: 30, ConnectorId: 1}
+
+	// Check if filename is empty
+	if fileName == "" {
+		return scenario, errors.New("Filename is empty")
+	}
+
+	// Read file context to the buffer
+	fileContentBytes, fileError := ioutil.ReadFile(fileName)
+	if fileError != nil {
+		return scenario, fileError
+	}
+
+	// Unmarshal the content of the file to the Scenario struct
+	if err := json.Unmarshal(fileContentBytes, &scenario); err != nil {
+		return scenario, err
+	}
+
+	return scenario, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : Scenario::Validate
+ *
+ *  Purpose : Check parameters of the scenario
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if scenario is not valid, nil otherwise
+ */
+func (scenario *Scenario) Validate() error {
+	if scenario.ServerURL == "" {
+		return errors.New("ServerURL is empty")
+	}
+
+	if scenario.Count < 0 || scenario.Cycles < 0 || scenario.MeterValues < 0 {
+		return errors.New("Count, Cycles and MeterValues cannot be negative")
+	}
+
+	if scenario.Count > len(scenario.Chargers) && scenario.ChargerNamePrefix == "" {
+		return errors.New(fmt.Sprintf("Count '%v' exceeds number of chargers '%v' and ChargerNamePrefix is empty", scenario.Count, len(scenario.Chargers)))
+	}
+
+	if len(scenario.IdTags) == 0 {
+		return errors.New("IdTags is empty")
+	}
+
+	if scenario.CallTimeout <= 0 {
+		return errors.New(fmt.Sprintf("Wrong CallTimeout '%v'", scenario.CallTimeout))
+	}
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : Scenario::ChargerNames
+ *
+ *  Purpose : Get names of the virtual chargers.
+ *			  Names from Chargers are used first, rest are generated with ChargerNamePrefix
+ *
+ *    Input : Nothing
+ *
+ *   Return : []string - names of the chargers
+ */
+func (scenario *Scenario) ChargerNames() []string {
+	if scenario.Count == 0 {
+		return scenario.Chargers
+	}
+
+	names := make([]string, 0, scenario.Count)
+	for index := 0; index < scenario.Count; index++ {
+		if index < len(scenario.Chargers) {
+			names = append(names, scenario.Chargers[index])
+			continue
+		}
+		names = append(names, fmt.Sprintf("%v%04d", scenario.ChargerNamePrefix, index+1))
+	}
+
+	return names
+}
+
+/****************************************************************************************
+ *
+ * Function : Scenario::IdTag
+ *
+ *  Purpose : Get identifier of the charger. Chargers sharing the identifier
+ *			  get ConcurrentTx while other transaction is active
+ *
+ *    Input : index int - index of the charger in the ChargerNames
+ *
+ *   Return : string - identifier
+ */
+func (scenario *Scenario) IdTag(index int) string {
+	return scenario.IdTags[index%len(scenario.IdTags)]
+}
+
+/****************************************************************************************
+ *
+ * Function : Scenario::TLSConfig
+ *
+ *  Purpose : Create TLS configuration of the chargers from the certificate files
+ *
+ *    Input : Nothing
+ *
+ *   Return : *tls.Config - nil when no certificates are set, system CAs are used then
+ *			  error - if files cannot be loaded
+ */
+func (scenario *Scenario) TLSConfig() (*tls.Config, error) {
+	if scenario.CAFile == "" && scenario.ClientCertFile == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if scenario.CAFile != "" {
+		caBytes, err := ioutil.ReadFile(scenario.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caBytes) {
+			return nil, errors.New(fmt.Sprintf("There are no certificates in CA file '%v'", scenario.CAFile))
+		}
+	}
+
+	if scenario.ClientCertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(scenario.ClientCertFile, scenario.ClientKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
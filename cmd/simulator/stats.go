@@ -0,0 +1,179 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: stats.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/cmd/simulator
+	Purpose: Latency and error counters of the simulation per action
+	=============================================================================
+*/
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/****************************************************************************************
+ *	Struct 	: ActionStats
+ *
+ * 	Purpose : Struct handles counters of the single action
+ *
+*****************************************************************************************/
+type ActionStats struct {
+	Count  int           // Number of attempts
+	Errors int           // Number of failed attempts
+	Total  time.Duration // Sum of the latency of the successful attempts
+	Min    time.Duration
+	Max    time.Duration
+}
+
+/****************************************************************************************
+ *	Struct 	: Stats
+ *
+ * 	Purpose : Struct handles counters of all actions, safe for the concurrent use
+ *
+*****************************************************************************************/
+type Stats struct {
+	actions  map[string]*ActionStats
+	statsMux sync.Mutex
+}
+
+/****************************************************************************************
+ *
+ * Function : StatsConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the Stats
+ *
+ *	  Input : Nothing
+ *
+ *	Return : *Stats object
+ */
+func StatsConstructor() *Stats {
+	stats := Stats{}
+	stats.actions = make(map[string]*ActionStats)
+	return &stats
+}
+
+/****************************************************************************************
+ *
+ * Function : Stats::Record
+ *
+ *  Purpose : Add result of the attempt to the counters of the action
+ *
+ *    Input : action string - name of the action
+ *			  latency time.Duration - time of the attempt
+ *			  err error - error of the attempt, nil when it is successful
+ *
+ *   Return : Nothing
+ */
+func (stats *Stats) Record(action string, latency time.Duration, err error) {
+	stats.statsMux.Lock()
+	defer stats.statsMux.Unlock()
+
+	actionStats, isKeyPresent := stats.actions[action]
+	if !isKeyPresent {
+		actionStats = &ActionStats{}
+		stats.actions[action] = actionStats
+	}
+
+	actionStats.Count++
+	if err != nil {
+		actionStats.Errors++
+		return
+	}
+
+	successful := actionStats.Count - actionStats.Errors
+	if successful == 1 || latency < actionStats.Min {
+		actionStats.Min = latency
+	}
+	if latency > actionStats.Max {
+		actionStats.Max = latency
+	}
+	actionStats.Total += latency
+}
+
+/****************************************************************************************
+ *
+ * Function : Stats::Get
+ *
+ *  Purpose : Get copy of the counters of the action
+ *
+ *    Input : action string - name of the action
+ *
+ *   Return : ActionStats - counters of the action
+ *			  bool - true if action was recorded, false otherwise
+ */
+func (stats *Stats) Get(action string) (ActionStats, bool) {
+	stats.statsMux.Lock()
+	defer stats.statsMux.Unlock()
+
+	actionStats, isKeyPresent := stats.actions[action]
+	if !isKeyPresent {
+		return ActionStats{}, false
+	}
+
+	return *actionStats, true
+}
+
+/****************************************************************************************
+ *
+ * Function : Stats::Errors
+ *
+ *  Purpose : Get number of failed attempts of all actions
+ *
+ *    Input : Nothing
+ *
+ *   Return : int - number of errors
+ */
+func (stats *Stats) Errors() int {
+	stats.statsMux.Lock()
+	defer stats.statsMux.Unlock()
+
+	errors := 0
+	for _, actionStats := range stats.actions {
+		errors += actionStats.Errors
+	}
+
+	return errors
+}
+
+/****************************************************************************************
+ *
+ * Function : Stats::Report
+ *
+ *  Purpose : Create table with counters of the actions sorted by name
+ *
+ *    Input : Nothing
+ *
+ *   Return : string - report
+ */
+func (stats *Stats) Report() string {
+	stats.statsMux.Lock()
+	defer stats.statsMux.Unlock()
+
+	names := make([]string, 0, len(stats.actions))
+	for name := range stats.actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var report strings.Builder
+	fmt.Fprintf(&report, "%-20v %8v %8v %12v %12v %12v\n", "Action", "Count", "Errors", "Min", "Avg", "Max")
+	for _, name := range names {
+		actionStats := stats.actions[name]
+
+		var average time.Duration
+		if successful := actionStats.Count - actionStats.Errors; successful > 0 {
+			average = actionStats.Total / time.Duration(successful)
+		}
+
+		fmt.Fprintf(&report, "%-20v %8v %8v %12v %12v %12v\n", name, actionStats.Count, actionStats.Errors,
+			actionStats.Min.Round(time.Microsecond), average.Round(time.Microsecond), actionStats.Max.Round(time.Microsecond))
+	}
+
+	return report.String()
+}
@@ -0,0 +1,83 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: stats_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/cmd/simulator
+	Purpose: File with test cases for the simulation counters
+	=============================================================================
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+/****************************************************************************************
+ *
+ * Function : TestStatsRecord
+ *
+ *  Purpose : Test latency and error counters of the action
+ *
+ *   Return : Nothing
+ */
+func TestStatsRecord(t *testing.T) {
+
+	stats := StatsConstructor()
+	stats.Record("Heartbeat", 30*time.Millisecond, nil)
+	stats.Record("Heartbeat", 10*time.Millisecond, nil)
+	stats.Record("Heartbeat", time.Second, errors.New("Call is timed out"))
+	stats.Record("Heartbeat", 20*time.Millisecond, nil)
+
+	heartbeatStats, exists := stats.Get("Heartbeat")
+	if !exists {
+		t.Fatal("Heartbeat is not recorded")
+	}
+
+	if heartbeatStats.Count != 4 || heartbeatStats.Errors != 1 {
+		t.Error(fmt.Sprintf("Wrong count '%v' or errors '%v'", heartbeatStats.Count, heartbeatStats.Errors))
+	}
+
+	// Latency of the failed attempt is not counted
+	if heartbeatStats.Min != 10*time.Millisecond || heartbeatStats.Max != 30*time.Millisecond || heartbeatStats.Total != 60*time.Millisecond {
+		t.Error(fmt.Sprintf("Wrong latency min '%v', max '%v', total '%v'", heartbeatStats.Min, heartbeatStats.Max, heartbeatStats.Total))
+	}
+
+	if stats.Errors() != 1 {
+		t.Error(fmt.Sprintf("Wrong number of errors '%v'", stats.Errors()))
+	}
+
+	if report := stats.Report(); !strings.Contains(report, "Heartbeat") || !strings.Contains(report, "20ms") {
+		t.Error(fmt.Sprintf("Report does not include average latency of the Heartbeat:\n%v", report))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestScenarioChargerNames
+ *
+ *  Purpose : Test names of the virtual chargers
+ *
+ *   Return : Nothing
+ */
+func TestScenarioChargerNames(t *testing.T) {
+
+	scenario := Scenario{ServerURL: "ws://localhost:9033/ocppj/1.6", Chargers: []string{"CP0001_V1"}, Count: 3, IdTags: []string{"TAG0001"}, CallTimeout: 30}
+	if err := scenario.Validate(); err == nil {
+		t.Error("Count exceeds chargers without ChargerNamePrefix, scenario must not be valid")
+	}
+
+	scenario.ChargerNamePrefix = "SIM"
+	if err := scenario.Validate(); err != nil {
+		t.Error(fmt.Sprintf("Unexpected error '%v'", err))
+	}
+
+	names := strings.Join(scenario.ChargerNames(), ",")
+	if names != "CP0001_V1,SIM0002,SIM0003" {
+		t.Error(fmt.Sprintf("Wrong names of the chargers '%v'", names))
+	}
+}
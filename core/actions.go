@@ -0,0 +1,46 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: actions.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: List of all actions defined by OCPP 1.6
+	=============================================================================
+*/
+
+package core
+
+const (
+	// Actions initiated by Charge Point
+	ACTION_AUTHORIZE                     string = "Authorize"
+	ACTION_BOOTNOTIFICATION              string = "BootNotification"
+	ACTION_DIAGNOSTICSSTATUSNOTIFICATION string = "DiagnosticsStatusNotification"
+	ACTION_FIRMWARESTATUSNOTIFICATION    string = "FirmwareStatusNotification"
+	ACTION_HEARTBEAT                     string = "Heartbeat"
+	ACTION_METERVALUES                   string = "MeterValues"
+	ACTION_STARTTRANSACTION              string = "StartTransaction"
+	ACTION_STATUSNOTIFICATION            string = "StatusNotification"
+	ACTION_STOPTRANSACTION               string = "StopTransaction"
+
+	// Actions initiated by Central System
+	ACTION_CANCELRESERVATION      string = "CancelReservation"
+	ACTION_CHANGEAVAILABILITY     string = "ChangeAvailability"
+	ACTION_CHANGECONFIGURATION    string = "ChangeConfiguration"
+	ACTION_CLEARCACHE             string = "ClearCache"
+	ACTION_CLEARCHARGINGPROFILE   string = "ClearChargingProfile"
+	ACTION_GETCOMPOSITESCHEDULE   string = "GetCompositeSchedule"
+	ACTION_GETCONFIGURATION       string = "GetConfiguration"
+	ACTION_GETDIAGNOSTICS         string = "GetDiagnostics"
+	ACTION_GETLOCALLISTVERSION    string = "GetLocalListVersion"
+	ACTION_REMOTESTARTTRANSACTION string = "RemoteStartTransaction"
+	ACTION_REMOTESTOPTRANSACTION  string = "RemoteStopTransaction"
+	ACTION_RESERVENOW             string = "ReserveNow"
+	ACTION_RESET                  string = "Reset"
+	ACTION_SENDLOCALLIST          string = "SendLocalList"
+	ACTION_SETCHARGINGPROFILE     string = "SetChargingProfile"
+	ACTION_TRIGGERMESSAGE         string = "TriggerMessage"
+	ACTION_UNLOCKCONNECTOR        string = "UnlockConnector"
+	ACTION_UPDATEFIRMWARE         string = "UpdateFirmware"
+
+	// Action can be initiated by both sides
+	ACTION_DATATRANSFER string = "DataTransfer"
+)
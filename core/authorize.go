@@ -0,0 +1,55 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: authorize.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Describe all methods to work with Authorize OCPP message
+	=============================================================================
+*/
+
+package core
+
+/****************************************************************************************
+ *	Struct 	: AuthorizeRequestPayload
+ *
+ * 	Purpose : Handles parameters of the Authorize request
+ *
+*****************************************************************************************/
+type AuthorizeRequestPayload struct {
+	IdTag string `json:"idTag"`
+}
+
+/****************************************************************************************
+ *	Struct 	: AuthorizeResponsePayload
+ *
+ * 	Purpose : Handles parameters of the Authorize response
+ *
+*****************************************************************************************/
+type AuthorizeResponsePayload struct {
+	IdTagInfo IdTagInfo `json:"idTagInfo"`
+}
+
+/****************************************************************************************
+ *	Interface : IdTagAuthorizer
+ *
+ * 	  Purpose : Interface to the backend which authorizes identifiers of the users.
+ *				Used by Authorize, StartTransaction and StopTransaction handlers
+ *
+*****************************************************************************************/
+type IdTagAuthorizer interface {
+	Authorize(idTag string) IdTagInfo
+}
+
+/****************************************************************************************
+ *
+ * Function : AuthorizeRequestPayload::Validate
+ *
+ *  Purpose : Check parameters of the Authorize request
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if payload is not valid, nil otherwise
+ */
+func (payload *AuthorizeRequestPayload) Validate() error {
+	return validateCiString("idTag", payload.IdTag, 20, true)
+}
@@ -19,21 +19,18 @@ const (
 	RegistrationStatusAccepted RegistrationStatus = "Accepted"
 	RegistrationStatusPending  RegistrationStatus = "Pending"
 	RegistrationStatusRejected RegistrationStatus = "Rejected"
-
-	ACTION_BOOTNOTIFICATION string = "BootNotification"
 )
 
 /****************************************************************************************
  *	Struct 	: BootNotificationResponsePayload
  *
- * 	Purpose :   Object stores callback handlers.
- *				Using different struct to isolate callback methods from RequestHandler
+ * 	Purpose : Handles parameters of the BootNotification response
  *
 *****************************************************************************************/
 type BootNotificationResponsePayload struct {
-	currentTime       string             //`json:"currentTime"`
-	heartbeatInterval int                //`json:"heartbeatInterval"`
-	status            RegistrationStatus //`json:"status"`
+	CurrentTime       string             `json:"currentTime"`
+	HeartbeatInterval int                `json:"interval"`
+	Status            RegistrationStatus `json:"status"`
 }
 
 /****************************************************************************************
@@ -49,9 +46,9 @@ type BootNotificationResponsePayload struct {
 func CreateBootNotificationResponsePayload(status RegistrationStatus) BootNotificationResponsePayload {
 	bootNotificationRespPayload := BootNotificationResponsePayload{}
 
-	bootNotificationRespPayload.status = status
-	bootNotificationRespPayload.heartbeatInterval = 300
-	bootNotificationRespPayload.currentTime = time.Now().Format("2006-01-02 15:04:05.000")
+	bootNotificationRespPayload.Status = status
+	bootNotificationRespPayload.HeartbeatInterval = 300
+	bootNotificationRespPayload.CurrentTime = FormatDateTime(time.Now())
 
 	return bootNotificationRespPayload
 }
@@ -64,14 +61,11 @@ func CreateBootNotificationResponsePayload(status RegistrationStatus) BootNotifi
  *
  *	  Input : Nothing
  *
- *	 Return : map[string]interface{} - map of the
+ *	 Return : map[string]interface{} - map of the payloads values
  */
 func (bootNotificationResPayload *BootNotificationResponsePayload) GetPayload() map[string]interface{} {
 
-	payload := make(map[string]interface{})
-	payload["status"] = string(bootNotificationResPayload.status)
-	payload["currentTime"] = bootNotificationResPayload.currentTime
-	payload["heartbeatInterval"] = bootNotificationResPayload.heartbeatInterval
+	payload, _ := MarshalPayload(bootNotificationResPayload)
 
 	return payload
 }
@@ -79,17 +73,54 @@ func (bootNotificationResPayload *BootNotificationResponsePayload) GetPayload()
 /****************************************************************************************
  *	Struct 	: BootNotificationRequestPayload
  *
- * 	Purpose :   Object stores callback handlers.
- *				Using different struct to isolate callback methods from RequestHandler
+ * 	Purpose : Handles parameters of the BootNotification request
  *
 *****************************************************************************************/
 type BootNotificationRequestPayload struct {
-	chargeBoxSerialNumber   string //`json:"chargeBoxSerialNumber"`
-	chargePointModel        string //`json:"chargePointModel"`
-	chargePointSerialNumber string //`json:"chargePointSerialNumber"`
-	chargePointVendor       string //`json:"chargePointVendor"`
-	firmwareVersion         string //`json:"firmwareVersion"`
-	iccid                   string //`json:"iccid"`
-	imsi                    string //`json:"imsi"`
-	meterSerialNumber       string //`json:"meterSerialNumber"`
+	ChargeBoxSerialNumber   string `json:"chargeBoxSerialNumber,omitempty"`
+	ChargePointModel        string `json:"chargePointModel"`
+	ChargePointSerialNumber string `json:"chargePointSerialNumber,omitempty"`
+	ChargePointVendor       string `json:"chargePointVendor"`
+	FirmwareVersion         string `json:"firmwareVersion,omitempty"`
+	Iccid                   string `json:"iccid,omitempty"`
+	Imsi                    string `json:"imsi,omitempty"`
+	MeterSerialNumber       string `json:"meterSerialNumber,omitempty"`
+	MeterType               string `json:"meterType,omitempty"`
+}
+
+/****************************************************************************************
+ *
+ * Function : BootNotificationRequestPayload::Validate
+ *
+ *  Purpose : Check parameters of the BootNotification request
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if payload is not valid, nil otherwise
+ */
+func (payload *BootNotificationRequestPayload) Validate() error {
+	fields := []struct {
+		name      string
+		value     string
+		maxLength int
+		required  bool
+	}{
+		{"chargePointVendor", payload.ChargePointVendor, 20, true},
+		{"chargePointModel", payload.ChargePointModel, 20, true},
+		{"chargePointSerialNumber", payload.ChargePointSerialNumber, 25, false},
+		{"chargeBoxSerialNumber", payload.ChargeBoxSerialNumber, 25, false},
+		{"firmwareVersion", payload.FirmwareVersion, 50, false},
+		{"iccid", payload.Iccid, 20, false},
+		{"imsi", payload.Imsi, 20, false},
+		{"meterType", payload.MeterType, 25, false},
+		{"meterSerialNumber", payload.MeterSerialNumber, 25, false},
+	}
+
+	for _, field := range fields {
+		if err := validateCiString(field.name, field.value, field.maxLength, field.required); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
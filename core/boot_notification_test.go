@@ -31,9 +31,9 @@ func TestBootNotificationResponse(t *testing.T) {
 
 	//Create payload
 	bootNotificationRespPayload := BootNotificationResponsePayload{
-		status:            RegistrationStatusPending,
-		heartbeatInterval: 300,
-		currentTime:       time.Now().Format("2006-01-02 15:04:05.000"),
+		Status:            RegistrationStatusPending,
+		HeartbeatInterval: 300,
+		CurrentTime:       FormatDateTime(time.Now()),
 	}
 
 	bootNotificationResp := messages.CreateCallResultMessage(uniqueID.String(), bootNotificationRespPayload.GetPayload())
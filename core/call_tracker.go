@@ -0,0 +1,325 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: call_tracker.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Tracking of the Calls sent by the Central System to the charger.
+			 OCPP-J allows only one outstanding Call per connection, tracker
+			 holds it until CallResult, CallError, timeout or disconnect
+	=============================================================================
+*/
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+	"sync"
+)
+
+var (
+	ErrCallTimeout      = errors.New("Call is timed out")
+	ErrCallDisconnected = errors.New("Charger is disconnected before Call is answered")
+)
+
+/****************************************************************************************
+ *	Struct 	: pendingCall
+ *
+ * 	Purpose : Struct handles outstanding Call waiting for the answer
+ *
+*****************************************************************************************/
+type pendingCall struct {
+	action   string
+	written  bool // Call is written to the connection, otherwise waits for the delivery
+	response chan callResponse
+}
+
+/****************************************************************************************
+ *	Struct 	: callResponse
+ *
+ * 	Purpose : Struct handles answer to the outstanding Call
+ *
+*****************************************************************************************/
+type callResponse struct {
+	callResult messages.CallResultMessage
+	err        error
+}
+
+/****************************************************************************************
+ *	Struct 	: CallTracker
+ *
+ * 	Purpose : Struct handles outstanding Call of the single charger
+ *
+*****************************************************************************************/
+type CallTracker struct {
+	slot       chan struct{} // Holds token while Call is outstanding
+	pending    map[string]*pendingCall
+	trackerMux sync.Mutex
+}
+
+/****************************************************************************************
+ *
+ * Function : CallTrackerConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the CallTracker
+ *
+ *	  Input : Nothing
+ *
+ *	Return : *CallTracker object
+ */
+func CallTrackerConstructor() *CallTracker {
+	tracker := CallTracker{}
+	tracker.slot = make(chan struct{}, 1)
+	tracker.pending = make(map[string]*pendingCall)
+	return &tracker
+}
+
+/****************************************************************************************
+ *
+ * Function : CallTracker::Call
+ *
+ *  Purpose : Send Call to the charger and wait for the answer.
+ *			  Waits while previous Call is outstanding, all waiting is limited by ctx
+ *
+ *    Input : ctx context.Context - context to limit time of the Call
+ *			  callMessage messages.CallMessage - Call to send
+ *			  send func(string) error - routine to deliver Call to the charger
+ *
+ *   Return : messages.CallResultMessage - answer of the charger
+ *			  error - messages.CallErrorMessage when charger answered with CallError,
+ *					  ErrCallTimeout, ErrCallDisconnected or error of the send routine
+ */
+func (tracker *CallTracker) Call(ctx context.Context, callMessage messages.CallMessage, send func(string) error) (messages.CallResultMessage, error) {
+	callMessageString, err := callMessage.ToString()
+	if err != nil {
+		return messages.CallResultMessage{}, err
+	}
+
+	// Wait till previous Call is answered
+	select {
+	case tracker.slot <- struct{}{}:
+	case <-ctx.Done():
+		return messages.CallResultMessage{}, tracker.contextError(ctx)
+	}
+	defer func() { <-tracker.slot }()
+
+	call := &pendingCall{action: callMessage.Action, response: make(chan callResponse, 1)}
+
+	tracker.trackerMux.Lock()
+	if _, isKeyPresent := tracker.pending[callMessage.UniqueID]; isKeyPresent {
+		tracker.trackerMux.Unlock()
+		return messages.CallResultMessage{}, errors.New(fmt.Sprintf("Call '%v' is outstanding already", callMessage.UniqueID))
+	}
+	tracker.pending[callMessage.UniqueID] = call
+	tracker.trackerMux.Unlock()
+
+	// Pending entry is not needed after the Call is finished in any way
+	defer tracker.remove(callMessage.UniqueID)
+
+	if err := send(callMessageString); err != nil {
+		return messages.CallResultMessage{}, err
+	}
+
+	select {
+	case response := <-call.response:
+		return response.callResult, response.err
+	case <-ctx.Done():
+		return messages.CallResultMessage{}, tracker.contextError(ctx)
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : CallTracker::GetAction
+ *
+ *  Purpose : Get action of the outstanding Call
+ *
+ *    Input : uniqueID string - id of the Call
+ *
+ *   Return : string - action of the Call
+ *			  bool - true when Call is outstanding, false otherwise
+ */
+func (tracker *CallTracker) GetAction(uniqueID string) (string, bool) {
+	tracker.trackerMux.Lock()
+	defer tracker.trackerMux.Unlock()
+
+	if call, isKeyPresent := tracker.pending[uniqueID]; isKeyPresent {
+		return call.action, true
+	}
+
+	return "", false
+}
+
+/****************************************************************************************
+ *
+ * Function : CallTracker::Resolve
+ *
+ *  Purpose : Pass CallResult to the waiting Call
+ *
+ *    Input : callResultMessage messages.CallResultMessage - answer of the charger
+ *
+ *   Return : bool - true when Call was outstanding, false otherwise
+ */
+func (tracker *CallTracker) Resolve(callResultMessage messages.CallResultMessage) bool {
+	return tracker.answer(callResultMessage.UniqueID, callResponse{callResult: callResultMessage})
+}
+
+/****************************************************************************************
+ *
+ * Function : CallTracker::Reject
+ *
+ *  Purpose : Pass CallError to the waiting Call
+ *
+ *    Input : callErrorMessage messages.CallErrorMessage - answer of the charger
+ *
+ *   Return : bool - true when Call was outstanding, false otherwise
+ */
+func (tracker *CallTracker) Reject(callErrorMessage messages.CallErrorMessage) bool {
+	return tracker.answer(callErrorMessage.UniqueID, callResponse{err: callErrorMessage})
+}
+
+/****************************************************************************************
+ *
+ * Function : CallTracker::Fail
+ *
+ *  Purpose : Pass error to the waiting Call, as example when answer is not valid
+ *
+ *    Input : uniqueID string - id of the Call
+ *			  err error - reason of the failure
+ *
+ *   Return : bool - true when Call was outstanding, false otherwise
+ */
+func (tracker *CallTracker) Fail(uniqueID string, err error) bool {
+	return tracker.answer(uniqueID, callResponse{err: err})
+}
+
+/****************************************************************************************
+ *
+ * Function : CallTracker::Cancel
+ *
+ *  Purpose : Fail all outstanding Calls when charger is disconnected
+ *
+ *    Input : Nothing
+ *
+ *   Return : Nothing
+ */
+func (tracker *CallTracker) Cancel() {
+	tracker.trackerMux.Lock()
+	defer tracker.trackerMux.Unlock()
+
+	for uniqueID, call := range tracker.pending {
+		call.response <- callResponse{err: ErrCallDisconnected}
+		delete(tracker.pending, uniqueID)
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : CallTracker::Written
+ *
+ *  Purpose : Set if Call is written to the connection. Call passed back to the
+ *			  delivery queue is not written anymore
+ *
+ *    Input : uniqueID string - id of the Call
+ *			  isWritten bool - true when Call is written to the connection
+ *
+ *   Return : bool - true when Call is outstanding, false otherwise
+ */
+func (tracker *CallTracker) Written(uniqueID string, isWritten bool) bool {
+	tracker.trackerMux.Lock()
+	defer tracker.trackerMux.Unlock()
+
+	call, isKeyPresent := tracker.pending[uniqueID]
+	if !isKeyPresent {
+		return false
+	}
+
+	call.written = isWritten
+	return true
+}
+
+/****************************************************************************************
+ *
+ * Function : CallTracker::CancelWritten
+ *
+ *  Purpose : Fail outstanding Calls written to the closed connection, they will not be
+ *			  answered. Calls waiting for the delivery are kept for the next connection
+ *
+ *    Input : Nothing
+ *
+ *   Return : Nothing
+ */
+func (tracker *CallTracker) CancelWritten() {
+	tracker.trackerMux.Lock()
+	defer tracker.trackerMux.Unlock()
+
+	for uniqueID, call := range tracker.pending {
+		if call.written {
+			call.response <- callResponse{err: ErrCallDisconnected}
+			delete(tracker.pending, uniqueID)
+		}
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : CallTracker::answer
+ *
+ *  Purpose : Pass answer to the waiting Call and remove it from the pending list
+ *
+ *    Input : uniqueID string - id of the Call
+ *			  response callResponse - answer of the charger
+ *
+ *   Return : bool - true when Call was outstanding, false otherwise
+ */
+func (tracker *CallTracker) answer(uniqueID string, response callResponse) bool {
+	tracker.trackerMux.Lock()
+	defer tracker.trackerMux.Unlock()
+
+	call, isKeyPresent := tracker.pending[uniqueID]
+	if !isKeyPresent {
+		return false
+	}
+
+	// Channel is buffered, waiting Call may be gone by timeout already
+	call.response <- response
+	delete(tracker.pending, uniqueID)
+
+	return true
+}
+
+/****************************************************************************************
+ *
+ * Function : CallTracker::remove
+ *
+ *  Purpose : Remove Call from the pending list
+ *
+ *    Input : uniqueID string - id of the Call
+ *
+ *   Return : Nothing
+ */
+func (tracker *CallTracker) remove(uniqueID string) {
+	tracker.trackerMux.Lock()
+	defer tracker.trackerMux.Unlock()
+
+	delete(tracker.pending, uniqueID)
+}
+
+/****************************************************************************************
+ *
+ * Function : CallTracker::contextError
+ *
+ *  Purpose : Convert error of the finished context
+ *
+ *    Input : ctx context.Context - finished context
+ *
+ *   Return : error - ErrCallTimeout when deadline is exceeded, error of the context otherwise
+ */
+func (tracker *CallTracker) contextError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrCallTimeout
+	}
+	return ctx.Err()
+}
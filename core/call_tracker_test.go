@@ -0,0 +1,176 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: call_tracker_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: File with test cases for CallTracker
+	=============================================================================
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+	"testing"
+	"time"
+)
+
+/****************************************************************************************
+ *
+ * Function : TestCallTrackerResolve
+ *
+ *  Purpose : Test that CallResult and CallError are passed to the waiting Call
+ *
+ *   Return : Nothing
+ */
+func TestCallTrackerResolve(t *testing.T) {
+
+	tracker := CallTrackerConstructor()
+	centralSystem := CentralSystemHandlerConstructor(&testHandlers{})
+	centralSystem.Calls = tracker
+
+	// Charger answers with CallResult as soon as Call is sent
+	callResult, err := tracker.Call(context.Background(), messages.CreateCallMessage("2001", ACTION_CLEARCACHE, map[string]interface{}{}), func(message string) error {
+		if action, _ := tracker.GetAction("2001"); action != ACTION_CLEARCACHE {
+			t.Error(fmt.Sprintf("Wrong action '%v' of the outstanding Call", action))
+		}
+		go centralSystem.HandleIncomeMessage("[3,\"2001\",{\"status\":\"Accepted\"}]")
+		return nil
+	})
+
+	if err != nil || callResult.Payload["status"] != "Accepted" {
+		t.Error(fmt.Sprintf("Wrong answer '%v' with error '%v'", callResult, err))
+	}
+
+	// Charger answers with CallError
+	_, err = tracker.Call(context.Background(), messages.CreateCallMessage("2002", ACTION_RESET, map[string]interface{}{"type": "Soft"}), func(message string) error {
+		go centralSystem.HandleIncomeMessage("[4,\"2002\",\"InternalError\",\"Reset failed\",{}]")
+		return nil
+	})
+
+	callError, isCallError := err.(messages.CallErrorMessage)
+	if !isCallError || callError.ErrorCode != "InternalError" {
+		t.Error(fmt.Sprintf("Expected CallError, got '%v'", err))
+	}
+
+	if _, exists := tracker.GetAction("2002"); exists {
+		t.Error("Answered Call must not be outstanding")
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestCallTrackerTimeout
+ *
+ *  Purpose : Test timeout of the Call and single outstanding Call per charger
+ *
+ *   Return : Nothing
+ */
+func TestCallTrackerTimeout(t *testing.T) {
+
+	tracker := CallTrackerConstructor()
+	sent := make(chan string, 2)
+	send := func(message string) error {
+		sent <- message
+		return nil
+	}
+
+	firstDone := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		_, err := tracker.Call(ctx, messages.CreateCallMessage("3001", ACTION_CLEARCACHE, map[string]interface{}{}), send)
+		firstDone <- err
+	}()
+	<-sent
+
+	// Second Call cannot be sent while first one is outstanding
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := tracker.Call(ctx, messages.CreateCallMessage("3002", ACTION_CLEARCACHE, map[string]interface{}{}), send); err != ErrCallTimeout {
+		t.Error(fmt.Sprintf("Expected timeout of the second Call, got '%v'", err))
+	}
+	if len(sent) != 0 {
+		t.Error("Second Call is sent while first one is outstanding")
+	}
+
+	if err := <-firstDone; err != ErrCallTimeout {
+		t.Error(fmt.Sprintf("Expected timeout of the first Call, got '%v'", err))
+	}
+
+	// Late answer is not matched to any Call
+	if tracker.Resolve(messages.CreateCallResultMessage("3001", map[string]interface{}{})) {
+		t.Error("Timed out Call must not be resolved")
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestCallTrackerCancel
+ *
+ *  Purpose : Test that outstanding Call is failed on disconnect
+ *
+ *   Return : Nothing
+ */
+func TestCallTrackerCancel(t *testing.T) {
+
+	tracker := CallTrackerConstructor()
+
+	_, err := tracker.Call(context.Background(), messages.CreateCallMessage("4001", ACTION_RESET, map[string]interface{}{"type": "Hard"}), func(message string) error {
+		go tracker.Cancel()
+		return nil
+	})
+
+	if err != ErrCallDisconnected {
+		t.Error(fmt.Sprintf("Expected disconnect error, got '%v'", err))
+	}
+
+	if _, exists := tracker.GetAction("4001"); exists {
+		t.Error("Cancelled Call must not be outstanding")
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestCallTrackerCancelWritten
+ *
+ *  Purpose : Test that only Call written to the connection is failed on disconnect,
+ *			  Call waiting for the delivery is kept
+ *
+ *   Return : Nothing
+ */
+func TestCallTrackerCancelWritten(t *testing.T) {
+
+	tracker := CallTrackerConstructor()
+	written := make(chan struct{})
+	result := make(chan error, 1)
+
+	go func() {
+		_, err := tracker.Call(context.Background(), messages.CreateCallMessage("5001", ACTION_RESET, map[string]interface{}{"type": "Hard"}), func(message string) error {
+			close(written)
+			return nil
+		})
+		result <- err
+	}()
+	<-written
+
+	// Call is not written yet, disconnect keeps it
+	tracker.CancelWritten()
+	if _, exists := tracker.GetAction("5001"); !exists {
+		t.Error("Call waiting for the delivery must be outstanding")
+	}
+
+	// Call written to the next connection is failed on disconnect
+	if !tracker.Written("5001", true) {
+		t.Error("Outstanding Call is not marked as written")
+	}
+	tracker.CancelWritten()
+	if err := <-result; err != ErrCallDisconnected {
+		t.Error(fmt.Sprintf("Expected disconnect error, got '%v'", err))
+	}
+	if tracker.Written("5001", true) {
+		t.Error("Cancelled Call must not be outstanding")
+	}
+}
@@ -0,0 +1,72 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: change_availability.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Describe all methods to work with ChangeAvailability OCPP message
+	=============================================================================
+*/
+
+package core
+
+import (
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+)
+
+type AvailabilityType string
+type AvailabilityStatus string
+
+const (
+	// AvailabilityTypes
+	AvailabilityTypeInoperative AvailabilityType = "Inoperative"
+	AvailabilityTypeOperative   AvailabilityType = "Operative"
+	// AvailabilityStatuses
+	AvailabilityStatusAccepted  AvailabilityStatus = "Accepted"
+	AvailabilityStatusRejected  AvailabilityStatus = "Rejected"
+	AvailabilityStatusScheduled AvailabilityStatus = "Scheduled"
+)
+
+/****************************************************************************************
+ *	Struct 	: ChangeAvailabilityRequestPayload
+ *
+ * 	Purpose : Handles parameters of the ChangeAvailability request
+ *
+*****************************************************************************************/
+type ChangeAvailabilityRequestPayload struct {
+	ConnectorId int              `json:"connectorId"`
+	Type        AvailabilityType `json:"type"`
+}
+
+/****************************************************************************************
+ *	Struct 	: ChangeAvailabilityResponsePayload
+ *
+ * 	Purpose : Handles parameters of the ChangeAvailability response
+ *
+*****************************************************************************************/
+type ChangeAvailabilityResponsePayload struct {
+	Status AvailabilityStatus `json:"status"`
+}
+
+/****************************************************************************************
+ *
+ * Function : ChangeAvailabilityRequestPayload::Validate
+ *
+ *  Purpose : Check parameters of the ChangeAvailability request
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if payload is not valid, nil otherwise
+ */
+func (payload *ChangeAvailabilityRequestPayload) Validate() error {
+	if payload.ConnectorId < 0 {
+		return newPayloadError(messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, fmt.Sprintf("Wrong connectorId '%v'", payload.ConnectorId))
+	}
+
+	switch payload.Type {
+	case AvailabilityTypeInoperative, AvailabilityTypeOperative:
+		return nil
+	}
+
+	return newPayloadError(messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, fmt.Sprintf("Wrong availability type '%v'", payload.Type))
+}
@@ -0,0 +1,59 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: change_configuration.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Describe all methods to work with ChangeConfiguration OCPP message
+	=============================================================================
+*/
+
+package core
+
+type ConfigurationStatus string
+
+const (
+	// ConfigurationStatuses
+	ConfigurationStatusAccepted       ConfigurationStatus = "Accepted"
+	ConfigurationStatusRejected       ConfigurationStatus = "Rejected"
+	ConfigurationStatusRebootRequired ConfigurationStatus = "RebootRequired"
+	ConfigurationStatusNotSupported   ConfigurationStatus = "NotSupported"
+)
+
+/****************************************************************************************
+ *	Struct 	: ChangeConfigurationRequestPayload
+ *
+ * 	Purpose : Handles parameters of the ChangeConfiguration request
+ *
+*****************************************************************************************/
+type ChangeConfigurationRequestPayload struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+/****************************************************************************************
+ *	Struct 	: ChangeConfigurationResponsePayload
+ *
+ * 	Purpose : Handles parameters of the ChangeConfiguration response
+ *
+*****************************************************************************************/
+type ChangeConfigurationResponsePayload struct {
+	Status ConfigurationStatus `json:"status"`
+}
+
+/****************************************************************************************
+ *
+ * Function : ChangeConfigurationRequestPayload::Validate
+ *
+ *  Purpose : Check parameters of the ChangeConfiguration request
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if payload is not valid, nil otherwise
+ */
+func (payload *ChangeConfigurationRequestPayload) Validate() error {
+	if err := validateCiString("key", payload.Key, 50, true); err != nil {
+		return err
+	}
+
+	return validateCiString("value", payload.Value, 500, false)
+}
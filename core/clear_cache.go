@@ -0,0 +1,50 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: clear_cache.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Describe all methods to work with ClearCache OCPP message
+	=============================================================================
+*/
+
+package core
+
+type ClearCacheStatus string
+
+const (
+	// ClearCacheStatuses
+	ClearCacheStatusAccepted ClearCacheStatus = "Accepted"
+	ClearCacheStatusRejected ClearCacheStatus = "Rejected"
+)
+
+/****************************************************************************************
+ *	Struct 	: ClearCacheRequestPayload
+ *
+ * 	Purpose : ClearCache request has no parameters
+ *
+*****************************************************************************************/
+type ClearCacheRequestPayload struct{}
+
+/****************************************************************************************
+ *	Struct 	: ClearCacheResponsePayload
+ *
+ * 	Purpose : Handles parameters of the ClearCache response
+ *
+*****************************************************************************************/
+type ClearCacheResponsePayload struct {
+	Status ClearCacheStatus `json:"status"`
+}
+
+/****************************************************************************************
+ *
+ * Function : ClearCacheRequestPayload::Validate
+ *
+ *  Purpose : Check parameters of the ClearCache request. Request has no parameters
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if payload is not valid, nil otherwise
+ */
+func (payload *ClearCacheRequestPayload) Validate() error {
+	return nil
+}
@@ -0,0 +1,61 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: data_transfer.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Describe all methods to work with DataTransfer OCPP message
+	=============================================================================
+*/
+
+package core
+
+type DataTransferStatus string
+
+const (
+	// DataTransferStatuses
+	DataTransferStatusAccepted         DataTransferStatus = "Accepted"
+	DataTransferStatusRejected         DataTransferStatus = "Rejected"
+	DataTransferStatusUnknownMessageId DataTransferStatus = "UnknownMessageId"
+	DataTransferStatusUnknownVendorId  DataTransferStatus = "UnknownVendorId"
+)
+
+/****************************************************************************************
+ *	Struct 	: DataTransferRequestPayload
+ *
+ * 	Purpose : Handles parameters of the DataTransfer request
+ *
+*****************************************************************************************/
+type DataTransferRequestPayload struct {
+	VendorId  string `json:"vendorId"`
+	MessageId string `json:"messageId,omitempty"`
+	Data      string `json:"data,omitempty"`
+}
+
+/****************************************************************************************
+ *	Struct 	: DataTransferResponsePayload
+ *
+ * 	Purpose : Handles parameters of the DataTransfer response
+ *
+*****************************************************************************************/
+type DataTransferResponsePayload struct {
+	Status DataTransferStatus `json:"status"`
+	Data   string             `json:"data,omitempty"`
+}
+
+/****************************************************************************************
+ *
+ * Function : DataTransferRequestPayload::Validate
+ *
+ *  Purpose : Check parameters of the DataTransfer request
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if payload is not valid, nil otherwise
+ */
+func (payload *DataTransferRequestPayload) Validate() error {
+	if err := validateCiString("vendorId", payload.VendorId, 255, true); err != nil {
+		return err
+	}
+
+	return validateCiString("messageId", payload.MessageId, 50, false)
+}
@@ -0,0 +1,64 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: get_configuration.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Describe all methods to work with GetConfiguration OCPP message
+	=============================================================================
+*/
+
+package core
+
+/****************************************************************************************
+ *	Struct 	: GetConfigurationRequestPayload
+ *
+ * 	Purpose : Handles parameters of the GetConfiguration request.
+ *			  Empty key list requests all configuration keys
+ *
+*****************************************************************************************/
+type GetConfigurationRequestPayload struct {
+	Key []string `json:"key,omitempty"`
+}
+
+/****************************************************************************************
+ *	Struct 	: KeyValue
+ *
+ * 	Purpose : Configuration key of the Charge Point with value
+ *
+*****************************************************************************************/
+type KeyValue struct {
+	Key      string `json:"key"`
+	Readonly bool   `json:"readonly"`
+	Value    string `json:"value,omitempty"`
+}
+
+/****************************************************************************************
+ *	Struct 	: GetConfigurationResponsePayload
+ *
+ * 	Purpose : Handles parameters of the GetConfiguration response
+ *
+*****************************************************************************************/
+type GetConfigurationResponsePayload struct {
+	ConfigurationKey []KeyValue `json:"configurationKey,omitempty"`
+	UnknownKey       []string   `json:"unknownKey,omitempty"`
+}
+
+/****************************************************************************************
+ *
+ * Function : GetConfigurationRequestPayload::Validate
+ *
+ *  Purpose : Check parameters of the GetConfiguration request
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if payload is not valid, nil otherwise
+ */
+func (payload *GetConfigurationRequestPayload) Validate() error {
+	for _, key := range payload.Key {
+		if err := validateCiString("key", key, 50, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
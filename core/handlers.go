@@ -13,40 +13,65 @@ import (
 	"errors"
 	"fmt"
 	"github.com/CoderSergiy/ocpp16-go/messages"
-	"reflect"
 )
 
-const (
-	REQUEST_TYPE_HANDLER  string = "RequestHandler"
-	RESPONSE_TYPE_HANDLER string = "ResponseHandler"
-	ERROR_TYPE_HANDLER    string = "OCPPErrorHandler"
-
-	GET_ACTION_HANDLER string = "GetActionHandler"
-)
-
-type CallHandlers interface{}
-
 /****************************************************************************************
- *	Struct 	: CallbackRoutine
+ *	Interface : CentralSystemHandlers
  *
- * 	Purpose :   Object stores callback handlers.
- *				Using different struct to isolate callback methods from RequestHandler
- *
-*****************************************************************************************/
-type CallbackRoutine struct {
-	CallHandlers
-}
-
-/****************************************************************************************
+ * 	  Purpose : Callbacks the Central System has to implement to handle OCPP messages.
+ *				Request handlers serve Calls initiated by the Charge Point,
+ *				response handlers serve CallResults for Calls sent by the Central System.
+ *				Embed CentralSystemHandlersBase to get NotImplemented defaults.
  *
- * Function : CallbackRoutine::getHandler
+ *	Request handlers return:
+ *			  string - response message in string format
+ *			  error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
  *
- *  Purpose : Get handler struct by provided name
+ *	Response and error handlers return:
+ *			  error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
  *
- *	 Return : Reflect value
- */
-func (callbackRoutine *CallbackRoutine) getHandler(methodName string) reflect.Value {
-	return (reflect.ValueOf(callbackRoutine.CallHandlers).MethodByName(methodName))
+*****************************************************************************************/
+type CentralSystemHandlers interface {
+	// Call handlers for the Charge Point initiated actions
+	AuthorizeRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	BootNotificationRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	DataTransferRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	DiagnosticsStatusNotificationRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	FirmwareStatusNotificationRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	HeartbeatRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	MeterValuesRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	StartTransactionRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	StatusNotificationRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+	StopTransactionRequestHandler(callMessage messages.CallMessage) (string, error, bool)
+
+	// CallResult handlers for the Central System initiated actions
+	CancelReservationResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	ChangeAvailabilityResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	ChangeConfigurationResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	ClearCacheResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	ClearChargingProfileResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	DataTransferResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	GetCompositeScheduleResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	GetConfigurationResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	GetDiagnosticsResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	GetLocalListVersionResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	RemoteStartTransactionResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	RemoteStopTransactionResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	ReserveNowResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	ResetResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	SendLocalListResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	SetChargingProfileResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	TriggerMessageResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	UnlockConnectorResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+	UpdateFirmwareResponseHandler(callResultMessage messages.CallResultMessage) (error, bool)
+
+	// CallError handler
+	OCPPErrorHandler(callErrorMessage messages.CallErrorMessage) (error, bool)
+
+	// Get action of the sent Call message by uniqueID, as CallResult does not include one
+	GetActionHandler(uniqueID string) string
 }
 
 /****************************************************************************************
@@ -56,7 +81,8 @@ func (callbackRoutine *CallbackRoutine) getHandler(methodName string) reflect.Va
  *
 *****************************************************************************************/
 type RequestHandler struct {
-	APIhadlers CallbackRoutine
+	APIhadlers CentralSystemHandlers
+	Calls      *CallTracker // Optional tracker of the Calls sent to the charger
 }
 
 /****************************************************************************************
@@ -65,13 +91,13 @@ type RequestHandler struct {
  *
  *  Purpose : Creates a new instance of the RequestHandler
  *
- *	  Input : callbackRoutines interface{} - routines to handle OCPP requests
+ *	  Input : callbackRoutines CentralSystemHandlers - routines to handle OCPP requests
  *
  *	Return : RequestHandler object
  */
-func CentralSystemHandlerConstructor(callbackRoutines interface{}) RequestHandler {
+func CentralSystemHandlerConstructor(callbackRoutines CentralSystemHandlers) RequestHandler {
 	rh := RequestHandler{}
-	rh.APIhadlers.CallHandlers = callbackRoutines
+	rh.APIhadlers = callbackRoutines
 	return rh
 }
 
@@ -79,90 +105,230 @@ func CentralSystemHandlerConstructor(callbackRoutines interface{}) RequestHandle
  *
  * Function : RequestHandler::callRequestHandler
  *
- *  Purpose : Call method by provided name in CallbackRoutine
+ *  Purpose : Call request handler for the action of the Call message
  *
- *	  Input : data interface{} - data to pass into handler
- *			  handlerMethodName string - name of the method
+ *	  Input : callMessage messages.CallMessage - income Call message
  *
  *	Return : string - response
  *			 error - if happened, nil otherwise
  *			 bool - true if needs to keep websocket open, false otherwise
  */
-func (requestHandler *RequestHandler) callRequestHandler(data interface{}, handlerMethodName string) (string, error, bool) {
+func (requestHandler *RequestHandler) callRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
 
-	methodCall := requestHandler.APIhadlers.getHandler(handlerMethodName)
-	if !methodCall.IsValid() {
-		return "", errors.New("Cannot find CallRequest handler"), true
-	}
-
-	// Call Request method from APIhadlers and return value
-	in := make([]reflect.Value, methodCall.Type().NumIn())
-	in[0] = reflect.ValueOf(data)
-	response := methodCall.Call(in)
+	handlers := requestHandler.APIhadlers
 
-	// Detect if error value is nil
-	if response[1].IsNil() {
-		return response[0].String(), nil, response[2].Bool()
+	switch callMessage.Action {
+	case ACTION_AUTHORIZE:
+		return handlers.AuthorizeRequestHandler(callMessage)
+	case ACTION_BOOTNOTIFICATION:
+		return handlers.BootNotificationRequestHandler(callMessage)
+	case ACTION_DATATRANSFER:
+		return handlers.DataTransferRequestHandler(callMessage)
+	case ACTION_DIAGNOSTICSSTATUSNOTIFICATION:
+		return handlers.DiagnosticsStatusNotificationRequestHandler(callMessage)
+	case ACTION_FIRMWARESTATUSNOTIFICATION:
+		return handlers.FirmwareStatusNotificationRequestHandler(callMessage)
+	case ACTION_HEARTBEAT:
+		return handlers.HeartbeatRequestHandler(callMessage)
+	case ACTION_METERVALUES:
+		return handlers.MeterValuesRequestHandler(callMessage)
+	case ACTION_STARTTRANSACTION:
+		return handlers.StartTransactionRequestHandler(callMessage)
+	case ACTION_STATUSNOTIFICATION:
+		return handlers.StatusNotificationRequestHandler(callMessage)
+	case ACTION_STOPTRANSACTION:
+		return handlers.StopTransactionRequestHandler(callMessage)
 	}
 
-	// When error present in response
-	return response[0].String(), response[1].Interface().(error), response[2].Bool()
+	// Action is not known by the Central System
+	err := errors.New(fmt.Sprintf("Cannot find CallRequest handler for action '%v'", callMessage.Action))
+	return CreateCallError(callMessage.UniqueID, messages.ERROR_CODE_NOT_IMPLEMENTED, err.Error()), err, true
 }
 
 /****************************************************************************************
  *
- * Function : RequestHandler::callResponseHandler
+ * Function : RequestHandler::handleCall
  *
- *  Purpose : Call method by provided name in CallbackRoutine
+ *  Purpose : Validate payload of the Call message and call request handler.
+ *			  Charger always gets an answer: CallError is created when payload
+ *			  is not valid or handler failed without response
  *
- *	  Input : data interface{} - data to pass into handler
- *			  handlerMethodName string - name of the method
+ *	  Input : callMessage messages.CallMessage - income Call message
  *
  *	Return : string - response
  *			 error - if happened, nil otherwise
  *			 bool - true if needs to keep websocket open, false otherwise
  */
-func (requestHandler *RequestHandler) callResponseHandler(data interface{}, handlerMethodName string) (string, error, bool) {
+func (requestHandler *RequestHandler) handleCall(callMessage messages.CallMessage) (string, error, bool) {
+
+	// Validate payload by schema and by the payload struct before it reaches the handler
+	if err := callMessage.Validate(); err != nil {
+		return CreateCallErrorFromError(callMessage.UniqueID, err), err, true
+	}
+
+	if payloadStruct := newRequestPayload(callMessage.Action); payloadStruct != nil {
+		if err := DecodePayload(callMessage.Payload, payloadStruct); err != nil {
+			return CreateCallErrorFromError(callMessage.UniqueID, err), err, true
+		}
+	}
 
-	methodCall := requestHandler.APIhadlers.getHandler(handlerMethodName)
-	if !methodCall.IsValid() {
-		return "", errors.New("Cannot find CallResponse handler"), true
+	response, err, socketStatus := requestHandler.callRequestHandler(callMessage)
+	if response == "" && err != nil {
+		return CreateCallErrorFromError(callMessage.UniqueID, err), err, socketStatus
+	}
+
+	// Charger must not get CallResult which is not valid by schema
+	if messageType, _, _ := messages.GetMessageTypeFromRaw(response); messageType == int(messages.MESSAGE_TYPE_CALL_RESULT) {
+		callResult := messages.CallResultMessageCreator(response)
+		if errSchema := messages.ValidateResponsePayload(callMessage.Action, callResult.Payload); errSchema != nil {
+			errResponse := errors.New(fmt.Sprintf("CallResult for action '%v' is not valid: %v", callMessage.Action, errSchema))
+			return CreateCallError(callMessage.UniqueID, messages.ERROR_CODE_INTERNAL_ERROR, errResponse.Error()), errResponse, socketStatus
+		}
+	}
+
+	return response, err, socketStatus
+}
+
+/****************************************************************************************
+ *
+ * Function : newRequestPayload
+ *
+ *  Purpose : Create payload struct of the Charge Point initiated action
+ *
+ *	  Input : action string - action of the Call message
+ *
+ *	Return : interface{} - pointer to the empty payload struct, nil when there is no struct for action
+ */
+func newRequestPayload(action string) interface{} {
+	switch action {
+	case ACTION_AUTHORIZE:
+		return &AuthorizeRequestPayload{}
+	case ACTION_BOOTNOTIFICATION:
+		return &BootNotificationRequestPayload{}
+	case ACTION_DATATRANSFER:
+		return &DataTransferRequestPayload{}
+	case ACTION_HEARTBEAT:
+		return &HeartbeatRequestPayload{}
+	case ACTION_METERVALUES:
+		return &MeterValuesRequestPayload{}
+	case ACTION_STARTTRANSACTION:
+		return &StartTransactionRequestPayload{}
+	case ACTION_STATUSNOTIFICATION:
+		return &StatusNotificationRequestPayload{}
+	case ACTION_STOPTRANSACTION:
+		return &StopTransactionRequestPayload{}
 	}
 
-	// Call Response method from APIhadlers and return value
-	in := make([]reflect.Value, methodCall.Type().NumIn())
-	in[0] = reflect.ValueOf(data)
-	response := methodCall.Call(in)
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : CreateCallError
+ *
+ *  Purpose : Create CallError message in string format
+ *
+ *	  Input : uniqueID string - id of the Call message
+ *			  errorCode messages.ErrorCode - code of the error
+ *			  errorDescription string - description of the error
+ *
+ *	Return : string - CallError message, empty if cannot be created
+ */
+func CreateCallError(uniqueID string, errorCode messages.ErrorCode, errorDescription string) string {
+	callErrorMessage := messages.CreateCallErrorMessage(uniqueID, errorCode, errorDescription, nil)
+	messageStr, _ := callErrorMessage.ToString()
+	return messageStr
+}
 
-	// Detect if error value is nil
-	if response[0].IsNil() {
-		return "", nil, response[1].Bool()
+/****************************************************************************************
+ *
+ * Function : CreateCallErrorFromError
+ *
+ *  Purpose : Create CallError message for the error. Code is taken from PayloadError,
+ *			  SchemaError or CallErrorMessage, InternalError is used otherwise
+ *
+ *	  Input : uniqueID string - id of the Call message
+ *			  err error - error happened on handling of the Call
+ *
+ *	Return : string - CallError message, empty if cannot be created
+ */
+func CreateCallErrorFromError(uniqueID string, err error) string {
+	switch typedError := err.(type) {
+	case *PayloadError:
+		return CreateCallError(uniqueID, typedError.ErrorCode, typedError.Description)
+	case *messages.SchemaError:
+		return CreateCallError(uniqueID, typedError.ErrorCode, typedError.Error())
+	case messages.CallErrorMessage:
+		return CreateCallError(uniqueID, typedError.ErrorCode, typedError.ErrorDescription)
 	}
 
-	return "", response[0].Interface().(error), response[1].Bool()
+	return CreateCallError(uniqueID, messages.ERROR_CODE_INTERNAL_ERROR, err.Error())
 }
 
 /****************************************************************************************
  *
- * Function : RequestHandler::getActionByUniqueID
+ * Function : RequestHandler::callResponseHandler
  *
- *  Purpose : Call method to obtain action by provided uniqueID of the message
+ *  Purpose : Call response handler for the action of the sent Call message
  *
- *	  Input : uniqueID string - message uniqueID
+ *	  Input : callResultMessage messages.CallResultMessage - income CallResult message
+ *			  action string - action of the sent Call message
  *
- *	 Return : string - Action name
+ *	Return : string - response
+ *			 error - if happened, nil otherwise
+ *			 bool - true if needs to keep websocket open, false otherwise
  */
-func (requestHandler *RequestHandler) getActionByUniqueID(uniqueID string) string {
-	methodCall := requestHandler.APIhadlers.getHandler(GET_ACTION_HANDLER)
-	if !methodCall.IsValid() {
-		return ""
+func (requestHandler *RequestHandler) callResponseHandler(callResultMessage messages.CallResultMessage, action string) (string, error, bool) {
+
+	handlers := requestHandler.APIhadlers
+
+	var err error
+	socketStatus := true
+
+	switch action {
+	case ACTION_CANCELRESERVATION:
+		err, socketStatus = handlers.CancelReservationResponseHandler(callResultMessage)
+	case ACTION_CHANGEAVAILABILITY:
+		err, socketStatus = handlers.ChangeAvailabilityResponseHandler(callResultMessage)
+	case ACTION_CHANGECONFIGURATION:
+		err, socketStatus = handlers.ChangeConfigurationResponseHandler(callResultMessage)
+	case ACTION_CLEARCACHE:
+		err, socketStatus = handlers.ClearCacheResponseHandler(callResultMessage)
+	case ACTION_CLEARCHARGINGPROFILE:
+		err, socketStatus = handlers.ClearChargingProfileResponseHandler(callResultMessage)
+	case ACTION_DATATRANSFER:
+		err, socketStatus = handlers.DataTransferResponseHandler(callResultMessage)
+	case ACTION_GETCOMPOSITESCHEDULE:
+		err, socketStatus = handlers.GetCompositeScheduleResponseHandler(callResultMessage)
+	case ACTION_GETCONFIGURATION:
+		err, socketStatus = handlers.GetConfigurationResponseHandler(callResultMessage)
+	case ACTION_GETDIAGNOSTICS:
+		err, socketStatus = handlers.GetDiagnosticsResponseHandler(callResultMessage)
+	case ACTION_GETLOCALLISTVERSION:
+		err, socketStatus = handlers.GetLocalListVersionResponseHandler(callResultMessage)
+	case ACTION_REMOTESTARTTRANSACTION:
+		err, socketStatus = handlers.RemoteStartTransactionResponseHandler(callResultMessage)
+	case ACTION_REMOTESTOPTRANSACTION:
+		err, socketStatus = handlers.RemoteStopTransactionResponseHandler(callResultMessage)
+	case ACTION_RESERVENOW:
+		err, socketStatus = handlers.ReserveNowResponseHandler(callResultMessage)
+	case ACTION_RESET:
+		err, socketStatus = handlers.ResetResponseHandler(callResultMessage)
+	case ACTION_SENDLOCALLIST:
+		err, socketStatus = handlers.SendLocalListResponseHandler(callResultMessage)
+	case ACTION_SETCHARGINGPROFILE:
+		err, socketStatus = handlers.SetChargingProfileResponseHandler(callResultMessage)
+	case ACTION_TRIGGERMESSAGE:
+		err, socketStatus = handlers.TriggerMessageResponseHandler(callResultMessage)
+	case ACTION_UNLOCKCONNECTOR:
+		err, socketStatus = handlers.UnlockConnectorResponseHandler(callResultMessage)
+	case ACTION_UPDATEFIRMWARE:
+		err, socketStatus = handlers.UpdateFirmwareResponseHandler(callResultMessage)
+	default:
+		return "", errors.New(fmt.Sprintf("Cannot find CallResponse handler for action '%v'", action)), true
 	}
 
-	// Call method to get action by uniqueID
-	in := make([]reflect.Value, methodCall.Type().NumIn())
-	in[0] = reflect.ValueOf(uniqueID)
-	response := methodCall.Call(in)
-	return response[0].String()
+	return "", err, socketStatus
 }
 
 /****************************************************************************************
@@ -185,19 +351,21 @@ func (requestHandler *RequestHandler) HandleIncomeMessage(rawMessage string) (st
 	}
 
 	// Get message type from the raw text
-	messageType, _, errMessageType := messages.GetMessageTypeFromRaw(rawMessage)
+	messageType, uniqueID, errMessageType := messages.GetMessageTypeFromRaw(rawMessage)
 	if errMessageType != nil {
-		return "", errMessageType, true
+		// Message is not OCPP-J frame
+		return CreateCallError(uniqueID, messages.ERROR_CODE_FORMATION_VIOLATION, errMessageType.Error()), errMessageType, true
 	}
 
 	// Handle Call message
 	if messageType == int(messages.MESSAGE_TYPE_CALL) {
 		// Create CallMessage obj from raw message
-		callMessageObj := messages.CreateCallMessageCreator(rawMessage)
+		callMessageObj, errCallMessage := messages.CallMessageParser(rawMessage)
+		if errCallMessage != nil {
+			return CreateCallError(uniqueID, messages.ERROR_CODE_FORMATION_VIOLATION, errCallMessage.Error()), errCallMessage, true
+		}
 
-		return requestHandler.callRequestHandler(
-			callMessageObj,
-			callMessageObj.Action+REQUEST_TYPE_HANDLER)
+		return requestHandler.handleCall(callMessageObj)
 	}
 
 	// Handle Call Result message
@@ -207,22 +375,43 @@ func (requestHandler *RequestHandler) HandleIncomeMessage(rawMessage string) (st
 
 		// To call correct CallResult handler we need action.
 		// Action is not exist in CallResult message.
-		// We are getting it from sent messages queue
-		action := requestHandler.getActionByUniqueID(callResultObj.UniqueID)
+		// We are getting it from sent messages queue or from the outstanding Call
+		action := requestHandler.APIhadlers.GetActionHandler(callResultObj.UniqueID)
+		if action == "" && requestHandler.Calls != nil {
+			action, _ = requestHandler.Calls.GetAction(callResultObj.UniqueID)
+		}
+
+		// CallResult which is not valid by schema is not passed to the handler
+		if errSchema := messages.ValidateResponsePayload(action, callResultObj.Payload); errSchema != nil {
+			err := errors.New(fmt.Sprintf("CallResult '%v' for action '%v' is not valid: %v", callResultObj.UniqueID, action, errSchema))
+			if requestHandler.Calls != nil {
+				requestHandler.Calls.Fail(callResultObj.UniqueID, err)
+			}
+			return "", err, true
+		}
+
+		response, err, socketStatus := requestHandler.callResponseHandler(callResultObj, action)
 
-		// Get Action from the queue by uniqueID, as call result has not included one
-		return requestHandler.callResponseHandler(
-			callResultObj,
-			action+RESPONSE_TYPE_HANDLER)
+		// Pass CallResult to the waiting Call
+		if requestHandler.Calls != nil {
+			requestHandler.Calls.Resolve(callResultObj)
+		}
+
+		return response, err, socketStatus
 	}
 
 	// Handle Call Error message
 	if messageType == int(messages.MESSAGE_TYPE_CALL_ERROR) {
 		// Create CallErrorMessage obj from raw message
 		callErrorObj := messages.CallErrorMessageCreator(rawMessage)
-		return requestHandler.callResponseHandler(
-			callErrorObj,
-			ERROR_TYPE_HANDLER)
+		err, socketStatus := requestHandler.APIhadlers.OCPPErrorHandler(callErrorObj)
+
+		// Pass CallError to the waiting Call
+		if requestHandler.Calls != nil {
+			requestHandler.Calls.Reject(callErrorObj)
+		}
+
+		return "", err, socketStatus
 	}
 
 	return "", errors.New(fmt.Sprintf("Handler for Type Message '%v' is not found", messageType)), true
@@ -0,0 +1,255 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: handlers_base.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Default implementation of the CentralSystemHandlers interface.
+			 Every handler replies that action is not implemented
+	=============================================================================
+*/
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+)
+
+/****************************************************************************************
+ *	Struct 	: CentralSystemHandlersBase
+ *
+ * 	Purpose : Object implements CentralSystemHandlers with NotImplemented defaults.
+ *			  Embed it to the own handlers struct and override required handlers only
+ *
+*****************************************************************************************/
+type CentralSystemHandlersBase struct{}
+
+// Make sure that base struct implements all handlers
+var _ CentralSystemHandlers = (*CentralSystemHandlersBase)(nil)
+
+/****************************************************************************************
+ *
+ * Function : notImplementedRequest
+ *
+ *  Purpose : Create NotImplemented CallError as response on the Call message
+ *
+ *    Input : callMessage messages.CallMessage - original Call message
+ *
+ *   Return : string - response message in string format
+ *			  error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ */
+func notImplementedRequest(callMessage messages.CallMessage) (string, error, bool) {
+	callErrorMessage := messages.CreateCallErrorMessage(
+		callMessage.UniqueID,
+		messages.ERROR_CODE_NOT_IMPLEMENTED,
+		fmt.Sprintf("Action '%v' is not implemented", callMessage.Action),
+		nil,
+	)
+
+	messageStr, err := callErrorMessage.ToString()
+	return messageStr, err, true
+}
+
+/****************************************************************************************
+ *
+ * Function : notImplementedResponse
+ *
+ *  Purpose : Report that CallResult for the action is not handled
+ *
+ *    Input : callResultMessage messages.CallResultMessage - income CallResult message
+ *			  action string - action of the sent Call message
+ *
+ *   Return : error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ */
+func notImplementedResponse(callResultMessage messages.CallResultMessage, action string) (error, bool) {
+	return errors.New(fmt.Sprintf("Response handler for action '%v' is not implemented, uniqueID '%v'", action, callResultMessage.UniqueID)), true
+}
+
+/* Define Call Handlers =========================================================================================
+=================================================================================================================
+*/
+
+// AuthorizeRequestHandler replies NotImplemented
+func (base *CentralSystemHandlersBase) AuthorizeRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// BootNotificationRequestHandler replies NotImplemented
+func (base *CentralSystemHandlersBase) BootNotificationRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// DataTransferRequestHandler replies NotImplemented
+func (base *CentralSystemHandlersBase) DataTransferRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// DiagnosticsStatusNotificationRequestHandler replies NotImplemented
+func (base *CentralSystemHandlersBase) DiagnosticsStatusNotificationRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// FirmwareStatusNotificationRequestHandler replies NotImplemented
+func (base *CentralSystemHandlersBase) FirmwareStatusNotificationRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// HeartbeatRequestHandler replies NotImplemented
+func (base *CentralSystemHandlersBase) HeartbeatRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// MeterValuesRequestHandler replies NotImplemented
+func (base *CentralSystemHandlersBase) MeterValuesRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// StartTransactionRequestHandler replies NotImplemented
+func (base *CentralSystemHandlersBase) StartTransactionRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// StatusNotificationRequestHandler replies NotImplemented
+func (base *CentralSystemHandlersBase) StatusNotificationRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+// StopTransactionRequestHandler replies NotImplemented
+func (base *CentralSystemHandlersBase) StopTransactionRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	return notImplementedRequest(callMessage)
+}
+
+/* Define Response Handlers ===================================================================================
+===============================================================================================================
+*/
+
+// CancelReservationResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) CancelReservationResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_CANCELRESERVATION)
+}
+
+// ChangeAvailabilityResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) ChangeAvailabilityResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_CHANGEAVAILABILITY)
+}
+
+// ChangeConfigurationResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) ChangeConfigurationResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_CHANGECONFIGURATION)
+}
+
+// ClearCacheResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) ClearCacheResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_CLEARCACHE)
+}
+
+// ClearChargingProfileResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) ClearChargingProfileResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_CLEARCHARGINGPROFILE)
+}
+
+// DataTransferResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) DataTransferResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_DATATRANSFER)
+}
+
+// GetCompositeScheduleResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) GetCompositeScheduleResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_GETCOMPOSITESCHEDULE)
+}
+
+// GetConfigurationResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) GetConfigurationResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_GETCONFIGURATION)
+}
+
+// GetDiagnosticsResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) GetDiagnosticsResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_GETDIAGNOSTICS)
+}
+
+// GetLocalListVersionResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) GetLocalListVersionResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_GETLOCALLISTVERSION)
+}
+
+// RemoteStartTransactionResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) RemoteStartTransactionResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_REMOTESTARTTRANSACTION)
+}
+
+// RemoteStopTransactionResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) RemoteStopTransactionResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_REMOTESTOPTRANSACTION)
+}
+
+// ReserveNowResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) ReserveNowResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_RESERVENOW)
+}
+
+// ResetResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) ResetResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_RESET)
+}
+
+// SendLocalListResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) SendLocalListResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_SENDLOCALLIST)
+}
+
+// SetChargingProfileResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) SetChargingProfileResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_SETCHARGINGPROFILE)
+}
+
+// TriggerMessageResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) TriggerMessageResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_TRIGGERMESSAGE)
+}
+
+// UnlockConnectorResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) UnlockConnectorResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_UNLOCKCONNECTOR)
+}
+
+// UpdateFirmwareResponseHandler reports that response is not handled
+func (base *CentralSystemHandlersBase) UpdateFirmwareResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	return notImplementedResponse(callResultMessage, ACTION_UPDATEFIRMWARE)
+}
+
+/* Define Error Handler ==============================================================================
+======================================================================================================
+*/
+
+/****************************************************************************************
+ *
+ * Function : CentralSystemHandlersBase::OCPPErrorHandler
+ *
+ *  Purpose : Handle OCPP Error. Base implementation ignores it
+ *
+ *    Input : callErrorMessage messages.CallErrorMessage - income CallError message
+ *
+ *   Return : error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ */
+func (base *CentralSystemHandlersBase) OCPPErrorHandler(callErrorMessage messages.CallErrorMessage) (error, bool) {
+	return nil, true
+}
+
+/****************************************************************************************
+ *
+ * Function : CentralSystemHandlersBase::GetActionHandler
+ *
+ *  Purpose : Get Action of the sent message by unique ID. Base implementation does not track messages
+ *
+ *    Input : uniqueID string - message's unique ID
+ *
+ *   Return : message's action in string format, empty when not found
+ */
+func (base *CentralSystemHandlersBase) GetActionHandler(uniqueID string) string {
+	return ""
+}
@@ -0,0 +1,123 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: handlers_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: File with test cases for RequestHandler
+	=============================================================================
+*/
+
+package core
+
+import (
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+	"testing"
+)
+
+/****************************************************************************************
+ *	Struct 	: testHandlers
+ *
+ * 	Purpose : Handlers overrides Heartbeat only, rest are from the base struct
+ *
+*****************************************************************************************/
+type testHandlers struct {
+	CentralSystemHandlersBase
+}
+
+func (th *testHandlers) HeartbeatRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	heartBeatResponse := HeartBeatResponse{CurrentTime: "2022-07-01T10:00:00.000Z"}
+	callResultMessage := messages.CreateCallResultMessage(callMessage.UniqueID, heartBeatResponse.GetPayload())
+	messageStr, err := callResultMessage.ToString()
+	return messageStr, err, true
+}
+
+/****************************************************************************************
+ *
+ * Function : TestHandleIncomeMessage
+ *
+ *  Purpose : Test dispatching of the Call message to the overridden handler
+ *
+ *   Return : Nothing
+ */
+func TestHandleIncomeMessage(t *testing.T) {
+
+	centralSystem := CentralSystemHandlerConstructor(&testHandlers{})
+
+	response, err, socketStatus := centralSystem.HandleIncomeMessage("[2,\"1001\",\"Heartbeat\",{}]")
+	if err != nil {
+		t.Error(fmt.Sprintf("Unexpected error '%v'", err))
+	}
+
+	expected := "[3,\"1001\",{\"currentTime\":\"2022-07-01T10:00:00.000Z\"}]"
+	if response != expected || !socketStatus {
+		t.Error(fmt.Sprintf("Response '%v' is not matched expected '%v'", response, expected))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestHandleIncomeMessageNotImplemented
+ *
+ *  Purpose : Test that base handlers reply with NotImplemented CallError
+ *
+ *   Return : Nothing
+ */
+func TestHandleIncomeMessageNotImplemented(t *testing.T) {
+
+	centralSystem := CentralSystemHandlerConstructor(&testHandlers{})
+
+	response, err, _ := centralSystem.HandleIncomeMessage("[2,\"1002\",\"Authorize\",{\"idTag\":\"TAG1\"}]")
+	if err != nil {
+		t.Error(fmt.Sprintf("Unexpected error '%v'", err))
+	}
+
+	callError := messages.CallErrorMessageCreator(response)
+	if callError.UniqueID != "1002" || callError.ErrorCode != "NotImplemented" {
+		t.Error(fmt.Sprintf("Response '%v' is not NotImplemented CallError", response))
+	}
+
+	// CallResult for unknown message has no action and cannot be handled
+	if _, err, _ := centralSystem.HandleIncomeMessage("[3,\"1003\",{}]"); err == nil {
+		t.Error("Expected error for CallResult without action")
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestHandleIncomeMessageCallError
+ *
+ *  Purpose : Test that charger gets CallError with OCPP-J error code
+ *			  when Call cannot be handled
+ *
+ *   Return : Nothing
+ */
+func TestHandleIncomeMessageCallError(t *testing.T) {
+
+	centralSystem := CentralSystemHandlerConstructor(&testHandlers{})
+
+	testCases := []struct {
+		rawMessage string
+		uniqueID   string
+		errorCode  messages.ErrorCode
+	}{
+		{"[2,\"5001\",\"Reset\",{\"type\":\"Soft\"}]", "5001", messages.ERROR_CODE_NOT_IMPLEMENTED},
+		{"[2,\"5002\",\"Heartbeat\",[]]", "5002", messages.ERROR_CODE_FORMATION_VIOLATION},
+		{"{\"uniqueId\":\"5003\"}", "", messages.ERROR_CODE_FORMATION_VIOLATION},
+		{"[2,\"5004\",\"Authorize\",{\"idTag\":1234}]", "5004", messages.ERROR_CODE_TYPE_CONSTRAINT_VIOLATION},
+		{"[2,\"5005\",\"Authorize\",{}]", "5005", messages.ERROR_CODE_OCCURENCE_CONSTRAINT_VIOLATION},
+		{"[2,\"5006\",\"StatusNotification\",{\"connectorId\":1,\"errorCode\":\"NoError\",\"status\":\"Sleeping\"}]", "5006", messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION},
+	}
+
+	for _, testCase := range testCases {
+		response, err, socketStatus := centralSystem.HandleIncomeMessage(testCase.rawMessage)
+		if err == nil || !socketStatus {
+			t.Error(fmt.Sprintf("Message '%v' expected error and open socket", testCase.rawMessage))
+		}
+
+		callError := messages.CallErrorMessageCreator(response)
+		if callError.UniqueID != testCase.uniqueID || callError.ErrorCode != testCase.errorCode {
+			t.Error(fmt.Sprintf("Response '%v' on '%v' is not '%v' CallError", response, testCase.rawMessage, testCase.errorCode))
+		}
+	}
+}
@@ -13,9 +13,13 @@ import (
 	"time"
 )
 
-const (
-	ACTION_HEARTBEAT string = "Heartbeat"
-)
+/****************************************************************************************
+ *	Struct 	: HeartbeatRequestPayload
+ *
+ * 	Purpose : Heartbeat request has no parameters
+ *
+*****************************************************************************************/
+type HeartbeatRequestPayload struct{}
 
 /****************************************************************************************
  *	Struct 	: HeartBeatResponse
@@ -24,7 +28,7 @@ const (
  *
 *****************************************************************************************/
 type HeartBeatResponse struct {
-	CurrentTime string //`json:"currentTime"`
+	CurrentTime string `json:"currentTime"`
 }
 
 /****************************************************************************************
@@ -38,7 +42,7 @@ type HeartBeatResponse struct {
  *	 Return : Nothing
  */
 func (heartBeatResponse *HeartBeatResponse) Init() {
-	heartBeatResponse.CurrentTime = time.Now().Format("2006-01-02 15:04:05.000")
+	heartBeatResponse.CurrentTime = FormatDateTime(time.Now())
 }
 
 /****************************************************************************************
@@ -49,13 +53,11 @@ func (heartBeatResponse *HeartBeatResponse) Init() {
  *
  *	  Input : Nothing
  *
- *	 Return : map[string]interface{} - map of the
+ *	 Return : map[string]interface{} - map of the payloads values
  */
 func (heartBeatResponse *HeartBeatResponse) GetPayload() map[string]interface{} {
 
-	payload := make(map[string]interface{})
-	payload["currentTime"] = heartBeatResponse.CurrentTime
+	payload, _ := MarshalPayload(heartBeatResponse)
 
 	return payload
-
 }
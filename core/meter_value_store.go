@@ -0,0 +1,216 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: meter_value_store.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Storage of the readings reported by MeterValues and StopTransaction
+			 Includes MeterValueStore interface and in-memory implementation
+	=============================================================================
+*/
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+/****************************************************************************************
+ *	Struct 	: MeterReading
+ *
+ * 	Purpose : Single sampled value with charger, connector and transaction it belongs to.
+ *			  Value is set for Raw format, SignedData keeps value of the signed readings
+ *
+*****************************************************************************************/
+type MeterReading struct {
+	ChargerName   string         `json:"chargerName"`
+	ConnectorId   int            `json:"connectorId"`
+	TransactionId int            `json:"transactionId,omitempty"`
+	Timestamp     time.Time      `json:"timestamp"`
+	Value         float64        `json:"value"`
+	SignedData    string         `json:"signedData,omitempty"`
+	Context       ReadingContext `json:"context"`
+	Format        ValueFormat    `json:"format"`
+	Measurand     Measurand      `json:"measurand"`
+	Phase         Phase          `json:"phase,omitempty"`
+	Location      Location       `json:"location"`
+	Unit          UnitOfMeasure  `json:"unit"`
+}
+
+/****************************************************************************************
+ *	Struct 	: MeterReadingFilter
+ *
+ * 	Purpose : Parameters to select readings from the store.
+ *			  Negative ConnectorId and zero TransactionId, From or To are not filtering
+ *
+*****************************************************************************************/
+type MeterReadingFilter struct {
+	ChargerName   string
+	ConnectorId   int
+	TransactionId int
+	From          time.Time
+	To            time.Time
+}
+
+/****************************************************************************************
+ *	Interface : MeterValueStore
+ *
+ * 	  Purpose : Interface to persist meter readings of the chargers
+ *
+*****************************************************************************************/
+type MeterValueStore interface {
+	AddMeterValues(chargerName string, connectorId int, transactionId int, meterValues []MeterValue) error
+	GetMeterReadings(filter MeterReadingFilter) []MeterReading
+}
+
+/****************************************************************************************
+ *
+ * Function : CreateMeterReadings
+ *
+ *  Purpose : Convert meter values from the OCPP message to the list of readings
+ *
+ *    Input : chargerName string - name of the charger reported values
+ *			  connectorId int - connector of the charger
+ *			  transactionId int - transaction of the values, 0 when values are not related to transaction
+ *			  meterValues []MeterValue - meter values from the message
+ *
+ *   Return : []MeterReading - readings which are parsed successfully
+ *			  error - first error happened, nil otherwise
+ */
+func CreateMeterReadings(chargerName string, connectorId int, transactionId int, meterValues []MeterValue) ([]MeterReading, error) {
+	var readings []MeterReading
+	var firstErr error
+
+	for _, meterValue := range meterValues {
+		timestamp, err := ParseDateTime(meterValue.Timestamp)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = errors.New(fmt.Sprintf("Wrong timestamp '%v' of meter value", meterValue.Timestamp))
+			}
+			continue
+		}
+
+		for _, sampledValue := range meterValue.SampledValue {
+			sampledValue = sampledValue.Normalized()
+
+			reading := MeterReading{
+				ChargerName:   chargerName,
+				ConnectorId:   connectorId,
+				TransactionId: transactionId,
+				Timestamp:     timestamp,
+				Context:       sampledValue.Context,
+				Format:        sampledValue.Format,
+				Measurand:     sampledValue.Measurand,
+				Phase:         sampledValue.Phase,
+				Location:      sampledValue.Location,
+				Unit:          sampledValue.Unit,
+			}
+
+			if sampledValue.Format == ValueFormatSignedData {
+				reading.SignedData = sampledValue.Value
+			} else {
+				value, err := sampledValue.NumericValue()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = errors.New(fmt.Sprintf("Wrong value '%v' of measurand '%v'", sampledValue.Value, sampledValue.Measurand))
+					}
+					continue
+				}
+				reading.Value = value
+			}
+
+			readings = append(readings, reading)
+		}
+	}
+
+	return readings, firstErr
+}
+
+/****************************************************************************************
+ *	Struct 	: MemoryMeterValueStore
+ *
+ * 	Purpose : In-memory implementation of the MeterValueStore
+ *
+*****************************************************************************************/
+type MemoryMeterValueStore struct {
+	readings []MeterReading
+	storeMux sync.Mutex
+}
+
+/****************************************************************************************
+ *
+ * Function : MemoryMeterValueStoreConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the MemoryMeterValueStore
+ *
+ *	  Input : Nothing
+ *
+ *	Return : *MemoryMeterValueStore object
+ */
+func MemoryMeterValueStoreConstructor() *MemoryMeterValueStore {
+	return &MemoryMeterValueStore{}
+}
+
+/****************************************************************************************
+ *
+ * Function : MemoryMeterValueStore::AddMeterValues
+ *
+ *  Purpose : Parse and store meter values. Values which cannot be parsed are skipped
+ *
+ *    Input : chargerName string - name of the charger reported values
+ *			  connectorId int - connector of the charger
+ *			  transactionId int - transaction of the values, 0 when values are not related to transaction
+ *			  meterValues []MeterValue - meter values from the message
+ *
+ *   Return : error - if any value is skipped, nil otherwise
+ */
+func (store *MemoryMeterValueStore) AddMeterValues(chargerName string, connectorId int, transactionId int, meterValues []MeterValue) error {
+	readings, err := CreateMeterReadings(chargerName, connectorId, transactionId, meterValues)
+
+	// Lock the store before any changes
+	store.storeMux.Lock()
+	defer store.storeMux.Unlock()
+
+	store.readings = append(store.readings, readings...)
+
+	return err
+}
+
+/****************************************************************************************
+ *
+ * Function : MemoryMeterValueStore::GetMeterReadings
+ *
+ *  Purpose : Get readings matched the filter
+ *
+ *    Input : filter MeterReadingFilter - parameters to select readings
+ *
+ *   Return : []MeterReading - list of the readings in the order they are stored
+ */
+func (store *MemoryMeterValueStore) GetMeterReadings(filter MeterReadingFilter) []MeterReading {
+	store.storeMux.Lock()
+	defer store.storeMux.Unlock()
+
+	readings := []MeterReading{}
+	for _, reading := range store.readings {
+		if filter.ChargerName != "" && reading.ChargerName != filter.ChargerName {
+			continue
+		}
+		if filter.ConnectorId >= 0 && reading.ConnectorId != filter.ConnectorId {
+			continue
+		}
+		if filter.TransactionId != 0 && reading.TransactionId != filter.TransactionId {
+			continue
+		}
+		if !filter.From.IsZero() && reading.Timestamp.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && reading.Timestamp.After(filter.To) {
+			continue
+		}
+		readings = append(readings, reading)
+	}
+
+	return readings
+}
@@ -0,0 +1,73 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: meter_value_store_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: File with test cases for MemoryMeterValueStore
+	=============================================================================
+*/
+
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+/****************************************************************************************
+ *
+ * Function : TestMemoryMeterValueStore
+ *
+ *  Purpose : Test parsing of the sampled values and selecting readings by time range
+ *
+ *   Return : Nothing
+ */
+func TestMemoryMeterValueStore(t *testing.T) {
+
+	store := MemoryMeterValueStoreConstructor()
+
+	err := store.AddMeterValues("CP0001", 1, 5, []MeterValue{
+		{
+			Timestamp: "2022-07-01T10:00:00Z",
+			SampledValue: []SampledValue{
+				{Value: "1000"},
+				{Value: "16.2", Measurand: MeasurandCurrentImport, Phase: PhaseL1, Unit: UnitOfMeasureA},
+				{Value: "AB01CD", Format: ValueFormatSignedData, Context: ReadingContextTransactionBegin},
+			},
+		},
+		{
+			Timestamp:    "2022-07-01T10:15:00Z",
+			SampledValue: []SampledValue{{Value: "1500"}, {Value: "not a number"}},
+		},
+	})
+	if err == nil {
+		t.Error("Expected error for value which is not a number")
+	}
+
+	readings := store.GetMeterReadings(MeterReadingFilter{ChargerName: "CP0001", ConnectorId: -1})
+	if len(readings) != 4 {
+		t.Error(fmt.Sprintf("Stored %v readings instead of 4", len(readings)))
+		return
+	}
+
+	// Omitted fields have to be set to default values
+	if readings[0].Measurand != MeasurandEnergyActiveImportRegister || readings[0].Unit != UnitOfMeasureWh || readings[0].Value != 1000 {
+		t.Error(fmt.Sprintf("Wrong default reading '%v'", readings[0]))
+	}
+
+	if readings[2].SignedData != "AB01CD" || readings[2].Context != ReadingContextTransactionBegin {
+		t.Error(fmt.Sprintf("Wrong signed reading '%v'", readings[2]))
+	}
+
+	// Select readings by time range
+	from, _ := ParseDateTime("2022-07-01T10:10:00Z")
+	readings = store.GetMeterReadings(MeterReadingFilter{ChargerName: "CP0001", ConnectorId: 1, From: from, To: from.Add(time.Hour)})
+	if len(readings) != 1 || readings[0].Value != 1500 {
+		t.Error(fmt.Sprintf("Wrong readings in time range '%v'", readings))
+	}
+
+	if readings = store.GetMeterReadings(MeterReadingFilter{ChargerName: "CP0002", ConnectorId: -1}); len(readings) != 0 {
+		t.Error(fmt.Sprintf("Wrong readings for other charger '%v'", readings))
+	}
+}
@@ -0,0 +1,253 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: meter_values.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Describe all methods to work with MeterValues OCPP message
+	=============================================================================
+*/
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ReadingContext string
+type ValueFormat string
+type Measurand string
+type Phase string
+type Location string
+type UnitOfMeasure string
+
+const (
+	// ReadingContexts
+	ReadingContextInterruptionBegin ReadingContext = "Interruption.Begin"
+	ReadingContextInterruptionEnd   ReadingContext = "Interruption.End"
+	ReadingContextOther             ReadingContext = "Other"
+	ReadingContextSampleClock       ReadingContext = "Sample.Clock"
+	ReadingContextSamplePeriodic    ReadingContext = "Sample.Periodic"
+	ReadingContextTransactionBegin  ReadingContext = "Transaction.Begin"
+	ReadingContextTransactionEnd    ReadingContext = "Transaction.End"
+	ReadingContextTrigger           ReadingContext = "Trigger"
+	// ValueFormats
+	ValueFormatRaw        ValueFormat = "Raw"
+	ValueFormatSignedData ValueFormat = "SignedData"
+	// Measurands
+	MeasurandCurrentExport                Measurand = "Current.Export"
+	MeasurandCurrentImport                Measurand = "Current.Import"
+	MeasurandCurrentOffered               Measurand = "Current.Offered"
+	MeasurandEnergyActiveExportRegister   Measurand = "Energy.Active.Export.Register"
+	MeasurandEnergyActiveImportRegister   Measurand = "Energy.Active.Import.Register"
+	MeasurandEnergyReactiveExportRegister Measurand = "Energy.Reactive.Export.Register"
+	MeasurandEnergyReactiveImportRegister Measurand = "Energy.Reactive.Import.Register"
+	MeasurandEnergyActiveExportInterval   Measurand = "Energy.Active.Export.Interval"
+	MeasurandEnergyActiveImportInterval   Measurand = "Energy.Active.Import.Interval"
+	MeasurandEnergyReactiveExportInterval Measurand = "Energy.Reactive.Export.Interval"
+	MeasurandEnergyReactiveImportInterval Measurand = "Energy.Reactive.Import.Interval"
+	MeasurandFrequency                    Measurand = "Frequency"
+	MeasurandPowerActiveExport            Measurand = "Power.Active.Export"
+	MeasurandPowerActiveImport            Measurand = "Power.Active.Import"
+	MeasurandPowerFactor                  Measurand = "Power.Factor"
+	MeasurandPowerOffered                 Measurand = "Power.Offered"
+	MeasurandPowerReactiveExport          Measurand = "Power.Reactive.Export"
+	MeasurandPowerReactiveImport          Measurand = "Power.Reactive.Import"
+	MeasurandRPM                          Measurand = "RPM"
+	MeasurandSoC                          Measurand = "SoC"
+	MeasurandTemperature                  Measurand = "Temperature"
+	MeasurandVoltage                      Measurand = "Voltage"
+	// Phases
+	PhaseL1   Phase = "L1"
+	PhaseL2   Phase = "L2"
+	PhaseL3   Phase = "L3"
+	PhaseN    Phase = "N"
+	PhaseL1N  Phase = "L1-N"
+	PhaseL2N  Phase = "L2-N"
+	PhaseL3N  Phase = "L3-N"
+	PhaseL1L2 Phase = "L1-L2"
+	PhaseL2L3 Phase = "L2-L3"
+	PhaseL3L1 Phase = "L3-L1"
+	// Locations
+	LocationBody   Location = "Body"
+	LocationCable  Location = "Cable"
+	LocationEV     Location = "EV"
+	LocationInlet  Location = "Inlet"
+	LocationOutlet Location = "Outlet"
+	// UnitOfMeasures
+	UnitOfMeasureWh         UnitOfMeasure = "Wh"
+	UnitOfMeasureKWh        UnitOfMeasure = "kWh"
+	UnitOfMeasureVarh       UnitOfMeasure = "varh"
+	UnitOfMeasureKvarh      UnitOfMeasure = "kvarh"
+	UnitOfMeasureW          UnitOfMeasure = "W"
+	UnitOfMeasureKW         UnitOfMeasure = "kW"
+	UnitOfMeasureVA         UnitOfMeasure = "VA"
+	UnitOfMeasureKVA        UnitOfMeasure = "kVA"
+	UnitOfMeasureVar        UnitOfMeasure = "var"
+	UnitOfMeasureKvar       UnitOfMeasure = "kvar"
+	UnitOfMeasureA          UnitOfMeasure = "A"
+	UnitOfMeasureV          UnitOfMeasure = "V"
+	UnitOfMeasureCelsius    UnitOfMeasure = "Celsius"
+	UnitOfMeasureFahrenheit UnitOfMeasure = "Fahrenheit"
+	UnitOfMeasureK          UnitOfMeasure = "K"
+	UnitOfMeasurePercent    UnitOfMeasure = "Percent"
+)
+
+/****************************************************************************************
+ *	Struct 	: SampledValue
+ *
+ * 	Purpose : Single sampled value in MeterValue.
+ *			  Value is a raw number or signed data, depending on the format
+ *
+*****************************************************************************************/
+type SampledValue struct {
+	Value     string         `json:"value"`
+	Context   ReadingContext `json:"context,omitempty"`
+	Format    ValueFormat    `json:"format,omitempty"`
+	Measurand Measurand      `json:"measurand,omitempty"`
+	Phase     Phase          `json:"phase,omitempty"`
+	Location  Location       `json:"location,omitempty"`
+	Unit      UnitOfMeasure  `json:"unit,omitempty"`
+}
+
+/****************************************************************************************
+ *	Struct 	: MeterValue
+ *
+ * 	Purpose : Collection of the sampled values taken at the same point of time
+ *
+*****************************************************************************************/
+type MeterValue struct {
+	Timestamp    string         `json:"timestamp"`
+	SampledValue []SampledValue `json:"sampledValue"`
+}
+
+/****************************************************************************************
+ *	Struct 	: MeterValuesRequestPayload
+ *
+ * 	Purpose : Handles parameters of the MeterValues request
+ *
+*****************************************************************************************/
+type MeterValuesRequestPayload struct {
+	ConnectorId   int          `json:"connectorId"`
+	TransactionId int          `json:"transactionId,omitempty"`
+	MeterValue    []MeterValue `json:"meterValue"`
+}
+
+/****************************************************************************************
+ *	Struct 	: MeterValuesResponsePayload
+ *
+ * 	Purpose : MeterValues response has no parameters
+ *
+*****************************************************************************************/
+type MeterValuesResponsePayload struct{}
+
+/****************************************************************************************
+ *
+ * Function : SampledValue::Normalized
+ *
+ *  Purpose : Get copy of the sampled value with default values of the omitted fields,
+ *			  as they are defined in OCPP 1.6 specification
+ *
+ *	  Input : Nothing
+ *
+ *	 Return : SampledValue with all optional fields set except phase
+ */
+func (sampledValue SampledValue) Normalized() SampledValue {
+	if sampledValue.Context == "" {
+		sampledValue.Context = ReadingContextSamplePeriodic
+	}
+	if sampledValue.Format == "" {
+		sampledValue.Format = ValueFormatRaw
+	}
+	if sampledValue.Measurand == "" {
+		sampledValue.Measurand = MeasurandEnergyActiveImportRegister
+	}
+	if sampledValue.Location == "" {
+		sampledValue.Location = LocationOutlet
+	}
+	if sampledValue.Unit == "" {
+		sampledValue.Unit = UnitOfMeasureWh
+	}
+
+	return sampledValue
+}
+
+/****************************************************************************************
+ *
+ * Function : SampledValue::NumericValue
+ *
+ *  Purpose : Parse value of the sampled value as a number.
+ *			  Only Raw format has numeric value, SignedData is an opaque string
+ *
+ *	  Input : Nothing
+ *
+ *	 Return : float64 - value of the sampled value
+ *			  error - if happened, nil otherwise
+ */
+func (sampledValue SampledValue) NumericValue() (float64, error) {
+	if sampledValue.Format == ValueFormatSignedData {
+		return 0, errors.New("Sampled value in SignedData format has no numeric value")
+	}
+
+	return strconv.ParseFloat(strings.TrimSpace(sampledValue.Value), 64)
+}
+
+/****************************************************************************************
+ *
+ * Function : ParseDateTime
+ *
+ *  Purpose : Parse dateTime field of the OCPP message.
+ *			  Timestamps without timezone are treated as UTC
+ *
+ *	  Input : dateTime string - dateTime in the message
+ *
+ *	 Return : time.Time - parsed time
+ *			  error - if happened, nil otherwise
+ */
+func ParseDateTime(dateTime string) (time.Time, error) {
+	if parsedTime, err := time.Parse(time.RFC3339Nano, dateTime); err == nil {
+		return parsedTime, nil
+	}
+
+	return time.Parse("2006-01-02T15:04:05.999999999", dateTime)
+}
+
+/****************************************************************************************
+ *
+ * Function : FormatDateTime
+ *
+ *  Purpose : Format time to the dateTime of the OCPP messages, UTC with milliseconds
+ *
+ *    Input : dateTime time.Time - time to format
+ *
+ *   Return : string - formatted time, as example "2022-07-01T10:00:00.000Z"
+ */
+func FormatDateTime(dateTime time.Time) string {
+	return dateTime.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+/****************************************************************************************
+ *
+ * Function : MeterValuesRequestPayload::Validate
+ *
+ *  Purpose : Check parameters of the MeterValues request
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if payload is not valid, nil otherwise
+ */
+func (payload *MeterValuesRequestPayload) Validate() error {
+	if payload.ConnectorId < 0 {
+		return newPayloadError(messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, fmt.Sprintf("Wrong connectorId '%v'", payload.ConnectorId))
+	}
+
+	if len(payload.MeterValue) == 0 {
+		return newPayloadError(messages.ERROR_CODE_OCCURENCE_CONSTRAINT_VIOLATION, "Field 'meterValue' requires at least one element")
+	}
+
+	return validateMeterValues("meterValue", payload.MeterValue)
+}
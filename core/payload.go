@@ -0,0 +1,231 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: payload.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Helpers to convert payload of the OCPP messages to typed structs and back
+	=============================================================================
+*/
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+)
+
+/****************************************************************************************
+ *	Interface : RequestPayload
+ *
+ * 	  Purpose : Payload of the Call which can be checked before sending to the charger
+ *
+*****************************************************************************************/
+type RequestPayload interface {
+	Validate() error
+}
+
+/****************************************************************************************
+ *	Struct 	: PayloadError
+ *
+ * 	Purpose : Error of the payload with OCPP-J error code to reply by CallError
+ *
+*****************************************************************************************/
+type PayloadError struct {
+	ErrorCode   messages.ErrorCode
+	Description string
+}
+
+/****************************************************************************************
+ *
+ * Function : newPayloadError (Constructor)
+ *
+ *  Purpose : Creates a new instance of the PayloadError
+ *
+ *    Input : errorCode messages.ErrorCode - code of the CallError
+ *			  description string - description of the error
+ *
+ *   Return : *PayloadError object
+ */
+func newPayloadError(errorCode messages.ErrorCode, description string) *PayloadError {
+	return &PayloadError{ErrorCode: errorCode, Description: description}
+}
+
+/****************************************************************************************
+ *
+ * Function : PayloadError::Error
+ *
+ *  Purpose : Implement error interface
+ *
+ *    Input : Nothing
+ *
+ *   Return : string - description of the error
+ */
+func (payloadError *PayloadError) Error() string {
+	return payloadError.Description
+}
+
+/****************************************************************************************
+ *
+ * Function : MarshalPayload
+ *
+ *  Purpose : Convert typed payload struct to the payload map of the OCPP message
+ *
+ *    Input : payloadStruct interface{} - payload struct, as example BootNotificationResponsePayload
+ *
+ *   Return : map[string]interface{} - payload to use in Call or CallResult message
+ *			  error - if happened, nil otherwise
+ */
+func MarshalPayload(payloadStruct interface{}) (map[string]interface{}, error) {
+	jsonPayload, err := json.Marshal(payloadStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := make(map[string]interface{})
+	if err := json.Unmarshal(jsonPayload, &payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : UnmarshalPayload
+ *
+ *  Purpose : Decode payload map of the OCPP message to the typed payload struct
+ *
+ *    Input : payload map[string]interface{} - payload from Call or CallResult message
+ *			  payloadStruct interface{} - pointer to the payload struct to fill in
+ *
+ *   Return : error - if happened, nil otherwise
+ */
+func UnmarshalPayload(payload map[string]interface{}, payloadStruct interface{}) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(jsonPayload, payloadStruct)
+}
+
+/****************************************************************************************
+ *
+ * Function : DecodePayload
+ *
+ *  Purpose : Decode payload map to the typed payload struct and validate it
+ *			  when struct implements RequestPayload
+ *
+ *    Input : payload map[string]interface{} - payload from Call or CallResult message
+ *			  payloadStruct interface{} - pointer to the payload struct to fill in
+ *
+ *   Return : error - *PayloadError with code of the CallError if happened, nil otherwise
+ */
+func DecodePayload(payload map[string]interface{}, payloadStruct interface{}) error {
+	if err := UnmarshalPayload(payload, payloadStruct); err != nil {
+		var typeError *json.UnmarshalTypeError
+		if errors.As(err, &typeError) {
+			return newPayloadError(messages.ERROR_CODE_TYPE_CONSTRAINT_VIOLATION, fmt.Sprintf("Field '%v' must be '%v', got '%v'", typeError.Field, typeError.Type, typeError.Value))
+		}
+		return newPayloadError(messages.ERROR_CODE_FORMATION_VIOLATION, err.Error())
+	}
+
+	requestPayload, isRequestPayload := payloadStruct.(RequestPayload)
+	if !isRequestPayload {
+		return nil
+	}
+
+	if err := requestPayload.Validate(); err != nil {
+		if _, isPayloadError := err.(*PayloadError); isPayloadError {
+			return err
+		}
+		return newPayloadError(messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, err.Error())
+	}
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : validateCiString
+ *
+ *  Purpose : Check string field of the payload against CiString type of the OCPP
+ *
+ *    Input : name string - name of the field
+ *			  value string - value of the field
+ *			  maxLength int - max length of the CiString type
+ *			  required bool - true if field is required
+ *
+ *   Return : error - if value is not valid, nil otherwise
+ */
+func validateCiString(name string, value string, maxLength int, required bool) error {
+	if required && value == "" {
+		return newPayloadError(messages.ERROR_CODE_OCCURENCE_CONSTRAINT_VIOLATION, fmt.Sprintf("Field '%v' is required", name))
+	}
+
+	if len(value) > maxLength {
+		return newPayloadError(messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, fmt.Sprintf("Field '%v' exceeds max length %v", name, maxLength))
+	}
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : validateDateTime
+ *
+ *  Purpose : Check dateTime field of the payload
+ *
+ *    Input : name string - name of the field
+ *			  value string - value of the field
+ *			  required bool - true if field is required
+ *
+ *   Return : error - if value is not valid, nil otherwise
+ */
+func validateDateTime(name string, value string, required bool) error {
+	if value == "" {
+		if required {
+			return newPayloadError(messages.ERROR_CODE_OCCURENCE_CONSTRAINT_VIOLATION, fmt.Sprintf("Field '%v' is required", name))
+		}
+		return nil
+	}
+
+	if _, err := ParseDateTime(value); err != nil {
+		return newPayloadError(messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, fmt.Sprintf("Field '%v' is not valid dateTime '%v'", name, value))
+	}
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : validateMeterValues
+ *
+ *  Purpose : Check list of the meter values in the payload
+ *
+ *    Input : name string - name of the field
+ *			  meterValues []MeterValue - value of the field
+ *
+ *   Return : error - if value is not valid, nil otherwise
+ */
+func validateMeterValues(name string, meterValues []MeterValue) error {
+	for _, meterValue := range meterValues {
+		if err := validateDateTime(name+".timestamp", meterValue.Timestamp, true); err != nil {
+			return err
+		}
+
+		if len(meterValue.SampledValue) == 0 {
+			return newPayloadError(messages.ERROR_CODE_OCCURENCE_CONSTRAINT_VIOLATION, fmt.Sprintf("Field '%v.sampledValue' requires at least one element", name))
+		}
+
+		for _, sampledValue := range meterValue.SampledValue {
+			if sampledValue.Value == "" {
+				return newPayloadError(messages.ERROR_CODE_OCCURENCE_CONSTRAINT_VIOLATION, fmt.Sprintf("Field '%v.sampledValue.value' is required", name))
+			}
+		}
+	}
+
+	return nil
+}
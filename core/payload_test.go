@@ -0,0 +1,139 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: payload_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: File with test cases for payload helpers
+	=============================================================================
+*/
+
+package core
+
+import (
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+	"testing"
+)
+
+/****************************************************************************************
+ *
+ * Function : TestUnmarshalPayload
+ *
+ *  Purpose : Test decoding of the Call message payload to the typed struct
+ *
+ *   Return : Nothing
+ */
+func TestUnmarshalPayload(t *testing.T) {
+
+	callMessage := messages.CreateCallMessageCreator("[2,\"1001\",\"MeterValues\",{\"connectorId\":1,\"transactionId\":7," +
+		"\"meterValue\":[{\"timestamp\":\"2022-07-01T10:00:00Z\",\"sampledValue\":[{\"value\":\"1200\"," +
+		"\"measurand\":\"Energy.Active.Import.Register\",\"unit\":\"Wh\"}]}]}]")
+
+	meterValuesRequest := MeterValuesRequestPayload{}
+	if err := UnmarshalPayload(callMessage.Payload, &meterValuesRequest); err != nil {
+		t.Error(fmt.Sprintf("Error when decoding payload '%v'", err))
+		return
+	}
+
+	if meterValuesRequest.ConnectorId != 1 || meterValuesRequest.TransactionId != 7 {
+		t.Error(fmt.Sprintf("Wrong connectorId '%v' or transactionId '%v'", meterValuesRequest.ConnectorId, meterValuesRequest.TransactionId))
+	}
+
+	if len(meterValuesRequest.MeterValue) != 1 || len(meterValuesRequest.MeterValue[0].SampledValue) != 1 {
+		t.Error("Wrong number of meter values")
+		return
+	}
+
+	sampledValue := meterValuesRequest.MeterValue[0].SampledValue[0]
+	if sampledValue.Measurand != MeasurandEnergyActiveImportRegister || sampledValue.Unit != UnitOfMeasureWh {
+		t.Error(fmt.Sprintf("Wrong sampled value '%v'", sampledValue))
+	}
+
+	// Wrong type of the field has to be reported
+	callMessage = messages.CreateCallMessageCreator("[2,\"1002\",\"StartTransaction\",{\"connectorId\":\"one\"}]")
+	if err := UnmarshalPayload(callMessage.Payload, &StartTransactionRequestPayload{}); err == nil {
+		t.Error("Expected error for wrong connectorId type")
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestMarshalPayload
+ *
+ *  Purpose : Test generating CallResult message from the typed struct
+ *
+ *   Return : Nothing
+ */
+func TestMarshalPayload(t *testing.T) {
+
+	startTransactionResponse := StartTransactionResponsePayload{
+		IdTagInfo:     IdTagInfo{Status: AuthorizationStatusAccepted},
+		TransactionId: 42,
+	}
+
+	payload, err := MarshalPayload(startTransactionResponse)
+	if err != nil {
+		t.Error(fmt.Sprintf("Error when encoding payload '%v'", err))
+		return
+	}
+
+	callResult := messages.CreateCallResultMessage("1003", payload)
+	messageStr, err := callResult.ToString()
+	if err != nil {
+		t.Error(fmt.Sprintf("Error when generating message '%v'", err))
+		return
+	}
+
+	expected := "[3,\"1003\",{\"idTagInfo\":{\"status\":\"Accepted\"},\"transactionId\":42}]"
+	if messageStr != expected {
+		t.Error(fmt.Sprintf("Generated message '%v' is not matched expected '%v'", messageStr, expected))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestValidateRequestPayload
+ *
+ *  Purpose : Test validation of the Central System initiated requests
+ *
+ *   Return : Nothing
+ */
+func TestValidateRequestPayload(t *testing.T) {
+
+	validPayloads := []RequestPayload{
+		&RemoteStartTransactionRequestPayload{ConnectorId: 1, IdTag: "TAG0001"},
+		&RemoteStopTransactionRequestPayload{TransactionId: 5},
+		&ResetRequestPayload{Type: ResetTypeSoft},
+		&UnlockConnectorRequestPayload{ConnectorId: 2},
+		&ChangeAvailabilityRequestPayload{ConnectorId: 0, Type: AvailabilityTypeInoperative},
+		&ChangeConfigurationRequestPayload{Key: "HeartbeatInterval", Value: "60"},
+		&GetConfigurationRequestPayload{},
+		&ClearCacheRequestPayload{},
+		&DataTransferRequestPayload{VendorId: "com.example"},
+	}
+
+	for _, payload := range validPayloads {
+		if err := payload.Validate(); err != nil {
+			t.Error(fmt.Sprintf("Payload '%+v' must be valid, got error '%v'", payload, err))
+		}
+	}
+
+	invalidPayloads := []RequestPayload{
+		&RemoteStartTransactionRequestPayload{ConnectorId: 1},
+		&RemoteStartTransactionRequestPayload{IdTag: "TAG00010001000100010001"},
+		&RemoteStartTransactionRequestPayload{IdTag: "TAG0001", ChargingProfile: &ChargingProfile{ChargingProfilePurpose: ChargingProfilePurposeTxDefaultProfile}},
+		&RemoteStopTransactionRequestPayload{},
+		&ResetRequestPayload{Type: "Warm"},
+		&UnlockConnectorRequestPayload{ConnectorId: 0},
+		&ChangeAvailabilityRequestPayload{ConnectorId: 1, Type: "Offline"},
+		&ChangeConfigurationRequestPayload{Value: "60"},
+		&GetConfigurationRequestPayload{Key: []string{""}},
+		&DataTransferRequestPayload{MessageId: "Ping"},
+	}
+
+	for _, payload := range invalidPayloads {
+		if err := payload.Validate(); err == nil {
+			t.Error(fmt.Sprintf("Payload '%+v' must not be valid", payload))
+		}
+	}
+}
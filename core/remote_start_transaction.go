@@ -0,0 +1,72 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: remote_start_transaction.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Describe all methods to work with RemoteStartTransaction OCPP message
+	=============================================================================
+*/
+
+package core
+
+import (
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+)
+
+type RemoteStartStopStatus string
+
+const (
+	// RemoteStartStopStatuses
+	RemoteStartStopStatusAccepted RemoteStartStopStatus = "Accepted"
+	RemoteStartStopStatusRejected RemoteStartStopStatus = "Rejected"
+)
+
+/****************************************************************************************
+ *	Struct 	: RemoteStartTransactionRequestPayload
+ *
+ * 	Purpose : Handles parameters of the RemoteStartTransaction request
+ *
+*****************************************************************************************/
+type RemoteStartTransactionRequestPayload struct {
+	ConnectorId     int              `json:"connectorId,omitempty"`
+	IdTag           string           `json:"idTag"`
+	ChargingProfile *ChargingProfile `json:"chargingProfile,omitempty"`
+}
+
+/****************************************************************************************
+ *	Struct 	: RemoteStartTransactionResponsePayload
+ *
+ * 	Purpose : Handles parameters of the RemoteStartTransaction response
+ *
+*****************************************************************************************/
+type RemoteStartTransactionResponsePayload struct {
+	Status RemoteStartStopStatus `json:"status"`
+}
+
+/****************************************************************************************
+ *
+ * Function : RemoteStartTransactionRequestPayload::Validate
+ *
+ *  Purpose : Check parameters of the RemoteStartTransaction request
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if payload is not valid, nil otherwise
+ */
+func (payload *RemoteStartTransactionRequestPayload) Validate() error {
+	if payload.ConnectorId < 0 {
+		return newPayloadError(messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, fmt.Sprintf("Wrong connectorId '%v'", payload.ConnectorId))
+	}
+
+	if err := validateCiString("idTag", payload.IdTag, 20, true); err != nil {
+		return err
+	}
+
+	// Only TxProfile can be used to start transaction
+	if payload.ChargingProfile != nil && payload.ChargingProfile.ChargingProfilePurpose != ChargingProfilePurposeTxProfile {
+		return newPayloadError(messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, fmt.Sprintf("Wrong chargingProfilePurpose '%v', must be '%v'", payload.ChargingProfile.ChargingProfilePurpose, ChargingProfilePurposeTxProfile))
+	}
+
+	return nil
+}
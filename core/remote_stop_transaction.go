@@ -0,0 +1,54 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: remote_stop_transaction.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Describe all methods to work with RemoteStopTransaction OCPP message
+	=============================================================================
+*/
+
+package core
+
+import (
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+)
+
+/****************************************************************************************
+ *	Struct 	: RemoteStopTransactionRequestPayload
+ *
+ * 	Purpose : Handles parameters of the RemoteStopTransaction request
+ *
+*****************************************************************************************/
+type RemoteStopTransactionRequestPayload struct {
+	TransactionId int `json:"transactionId"`
+}
+
+/****************************************************************************************
+ *	Struct 	: RemoteStopTransactionResponsePayload
+ *
+ * 	Purpose : Handles parameters of the RemoteStopTransaction response
+ *
+*****************************************************************************************/
+type RemoteStopTransactionResponsePayload struct {
+	Status RemoteStartStopStatus `json:"status"`
+}
+
+/****************************************************************************************
+ *
+ * Function : RemoteStopTransactionRequestPayload::Validate
+ *
+ *  Purpose : Check parameters of the RemoteStopTransaction request
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if payload is not valid, nil otherwise
+ */
+func (payload *RemoteStopTransactionRequestPayload) Validate() error {
+	// Transactions are allocated by the Central System starting from 1
+	if payload.TransactionId <= 0 {
+		return newPayloadError(messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, fmt.Sprintf("Wrong transactionId '%v'", payload.TransactionId))
+	}
+
+	return nil
+}
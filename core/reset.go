@@ -0,0 +1,66 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: reset.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Describe all methods to work with Reset OCPP message
+	=============================================================================
+*/
+
+package core
+
+import (
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+)
+
+type ResetType string
+type ResetStatus string
+
+const (
+	// ResetTypes
+	ResetTypeHard ResetType = "Hard"
+	ResetTypeSoft ResetType = "Soft"
+	// ResetStatuses
+	ResetStatusAccepted ResetStatus = "Accepted"
+	ResetStatusRejected ResetStatus = "Rejected"
+)
+
+/****************************************************************************************
+ *	Struct 	: ResetRequestPayload
+ *
+ * 	Purpose : Handles parameters of the Reset request
+ *
+*****************************************************************************************/
+type ResetRequestPayload struct {
+	Type ResetType `json:"type"`
+}
+
+/****************************************************************************************
+ *	Struct 	: ResetResponsePayload
+ *
+ * 	Purpose : Handles parameters of the Reset response
+ *
+*****************************************************************************************/
+type ResetResponsePayload struct {
+	Status ResetStatus `json:"status"`
+}
+
+/****************************************************************************************
+ *
+ * Function : ResetRequestPayload::Validate
+ *
+ *  Purpose : Check parameters of the Reset request
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if payload is not valid, nil otherwise
+ */
+func (payload *ResetRequestPayload) Validate() error {
+	switch payload.Type {
+	case ResetTypeHard, ResetTypeSoft:
+		return nil
+	}
+
+	return newPayloadError(messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, fmt.Sprintf("Wrong reset type '%v'", payload.Type))
+}
@@ -0,0 +1,114 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: security.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Security Profile 1 of the OCPP 1.6 security whitepaper.
+			 Charge Point sends AuthorizationKey as password of the HTTP Basic
+			 authentication, Central System keeps only salted hash of it
+			 in format "sha256:{salt}:{hex of sha256(salt + password)}"
+	=============================================================================
+*/
+
+package core
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	AUTHORIZATION_KEY_HASH_PREFIX string = "sha256"
+	// Length of the AuthorizationKey regarding Security Profile 1
+	AUTHORIZATION_KEY_MIN_LENGTH int = 16
+	AUTHORIZATION_KEY_MAX_LENGTH int = 40
+)
+
+/****************************************************************************************
+ *
+ * Function : HashAuthorizationKey
+ *
+ *  Purpose : Create salted hash of the Charge Point password to store in configs
+ *
+ *    Input : password string - AuthorizationKey of the Charge Point
+ *
+ *   Return : string - hash in format "sha256:{salt}:{hash}"
+ *			  error - if password is not valid or salt cannot be generated
+ */
+func HashAuthorizationKey(password string) (string, error) {
+	if len(password) < AUTHORIZATION_KEY_MIN_LENGTH || len(password) > AUTHORIZATION_KEY_MAX_LENGTH {
+		return "", errors.New(fmt.Sprintf("AuthorizationKey length must be from %v to %v", AUTHORIZATION_KEY_MIN_LENGTH, AUTHORIZATION_KEY_MAX_LENGTH))
+	}
+
+	saltBytes := make([]byte, 16)
+	if _, err := rand.Read(saltBytes); err != nil {
+		return "", err
+	}
+	salt := hex.EncodeToString(saltBytes)
+
+	return formatAuthorizationKeyHash(salt, password), nil
+}
+
+/****************************************************************************************
+ *
+ * Function : VerifyAuthorizationKey
+ *
+ *  Purpose : Check password of the Charge Point against the stored hash.
+ *			  Hashes are compared in constant time
+ *
+ *    Input : hashedKey string - hash created by HashAuthorizationKey
+ *			  password string - password sent by the Charge Point
+ *
+ *   Return : bool - true when password matches the hash, false otherwise
+ */
+func VerifyAuthorizationKey(hashedKey string, password string) bool {
+	if !IsHashedAuthorizationKey(hashedKey) {
+		return false
+	}
+
+	salt := strings.Split(hashedKey, ":")[1]
+	expected := formatAuthorizationKeyHash(salt, password)
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(hashedKey)) == 1
+}
+
+/****************************************************************************************
+ *
+ * Function : IsHashedAuthorizationKey
+ *
+ *  Purpose : Check that value is in the format created by HashAuthorizationKey
+ *
+ *    Input : hashedKey string - value to check
+ *
+ *   Return : bool - true when value is a hash, false otherwise
+ */
+func IsHashedAuthorizationKey(hashedKey string) bool {
+	parts := strings.Split(hashedKey, ":")
+	if len(parts) != 3 || parts[0] != AUTHORIZATION_KEY_HASH_PREFIX || parts[1] == "" {
+		return false
+	}
+
+	hash, err := hex.DecodeString(parts[2])
+	return err == nil && len(hash) == sha256.Size
+}
+
+/****************************************************************************************
+ *
+ * Function : formatAuthorizationKeyHash
+ *
+ *  Purpose : Hash password with salt
+ *
+ *    Input : salt string - salt of the hash
+ *			  password string - password to hash
+ *
+ *   Return : string - hash in format "sha256:{salt}:{hash}"
+ */
+func formatAuthorizationKeyHash(salt string, password string) string {
+	hash := sha256.Sum256([]byte(salt + password))
+	return AUTHORIZATION_KEY_HASH_PREFIX + ":" + salt + ":" + hex.EncodeToString(hash[:])
+}
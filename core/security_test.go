@@ -0,0 +1,59 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: security_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: File with test cases for hashing of the AuthorizationKey
+	=============================================================================
+*/
+
+package core
+
+import (
+	"fmt"
+	"testing"
+)
+
+/****************************************************************************************
+ *
+ * Function : TestAuthorizationKey
+ *
+ *  Purpose : Test hashing and verification of the Charge Point password
+ *
+ *   Return : Nothing
+ */
+func TestAuthorizationKey(t *testing.T) {
+
+	password := "NIOERVB8REBOTIEBNRQ=="
+
+	hashedKey, err := HashAuthorizationKey(password)
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Unexpected error '%v'", err))
+	}
+
+	if !IsHashedAuthorizationKey(hashedKey) {
+		t.Error(fmt.Sprintf("Hash '%v' is not in expected format", hashedKey))
+	}
+
+	if !VerifyAuthorizationKey(hashedKey, password) {
+		t.Error("Password does not match own hash")
+	}
+
+	if VerifyAuthorizationKey(hashedKey, "NIOERVB8REBOTIEBNRQ=!") {
+		t.Error("Wrong password matches the hash")
+	}
+
+	// Same password gets different salt every time
+	if otherHashedKey, _ := HashAuthorizationKey(password); otherHashedKey == hashedKey {
+		t.Error("Hash is created without random salt")
+	}
+
+	// Plain password in the configs is not accepted as hash
+	if IsHashedAuthorizationKey(password) || VerifyAuthorizationKey(password, password) {
+		t.Error("Plain password must not be accepted as hash")
+	}
+
+	if _, err := HashAuthorizationKey("short"); err == nil {
+		t.Error("Password shorter than 16 characters must not be hashed")
+	}
+}
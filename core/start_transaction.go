@@ -0,0 +1,62 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: start_transaction.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Describe all methods to work with StartTransaction OCPP message
+	=============================================================================
+*/
+
+package core
+
+import (
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+)
+
+/****************************************************************************************
+ *	Struct 	: StartTransactionRequestPayload
+ *
+ * 	Purpose : Handles parameters of the StartTransaction request
+ *
+*****************************************************************************************/
+type StartTransactionRequestPayload struct {
+	ConnectorId   int    `json:"connectorId"`
+	IdTag         string `json:"idTag"`
+	MeterStart    int    `json:"meterStart"`
+	ReservationId int    `json:"reservationId,omitempty"`
+	Timestamp     string `json:"timestamp"`
+}
+
+/****************************************************************************************
+ *	Struct 	: StartTransactionResponsePayload
+ *
+ * 	Purpose : Handles parameters of the StartTransaction response
+ *
+*****************************************************************************************/
+type StartTransactionResponsePayload struct {
+	IdTagInfo     IdTagInfo `json:"idTagInfo"`
+	TransactionId int       `json:"transactionId"`
+}
+
+/****************************************************************************************
+ *
+ * Function : StartTransactionRequestPayload::Validate
+ *
+ *  Purpose : Check parameters of the StartTransaction request
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if payload is not valid, nil otherwise
+ */
+func (payload *StartTransactionRequestPayload) Validate() error {
+	if payload.ConnectorId <= 0 {
+		return newPayloadError(messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, fmt.Sprintf("Wrong connectorId '%v'", payload.ConnectorId))
+	}
+
+	if err := validateCiString("idTag", payload.IdTag, 20, true); err != nil {
+		return err
+	}
+
+	return validateDateTime("timestamp", payload.Timestamp, true)
+}
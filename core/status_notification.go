@@ -0,0 +1,212 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: status_notification.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Describe all methods to work with StatusNotification OCPP message
+	=============================================================================
+*/
+
+package core
+
+import (
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+)
+
+type ChargePointErrorCode string
+type ChargePointStatus string
+
+const (
+	// ChargePointErrorCodes
+	ChargePointErrorCodeConnectorLockFailure ChargePointErrorCode = "ConnectorLockFailure"
+	ChargePointErrorCodeEVCommunicationError ChargePointErrorCode = "EVCommunicationError"
+	ChargePointErrorCodeGroundFailure        ChargePointErrorCode = "GroundFailure"
+	ChargePointErrorCodeHighTemperature      ChargePointErrorCode = "HighTemperature"
+	ChargePointErrorCodeInternalError        ChargePointErrorCode = "InternalError"
+	ChargePointErrorCodeLocalListConflict    ChargePointErrorCode = "LocalListConflict"
+	ChargePointErrorCodeNoError              ChargePointErrorCode = "NoError"
+	ChargePointErrorCodeOtherError           ChargePointErrorCode = "OtherError"
+	ChargePointErrorCodeOverCurrentFailure   ChargePointErrorCode = "OverCurrentFailure"
+	ChargePointErrorCodeOverVoltage          ChargePointErrorCode = "OverVoltage"
+	ChargePointErrorCodePowerMeterFailure    ChargePointErrorCode = "PowerMeterFailure"
+	ChargePointErrorCodePowerSwitchFailure   ChargePointErrorCode = "PowerSwitchFailure"
+	ChargePointErrorCodeReaderFailure        ChargePointErrorCode = "ReaderFailure"
+	ChargePointErrorCodeResetFailure         ChargePointErrorCode = "ResetFailure"
+	ChargePointErrorCodeUnderVoltage         ChargePointErrorCode = "UnderVoltage"
+	ChargePointErrorCodeWeakSignal           ChargePointErrorCode = "WeakSignal"
+	// ChargePointStatuses
+	ChargePointStatusAvailable     ChargePointStatus = "Available"
+	ChargePointStatusPreparing     ChargePointStatus = "Preparing"
+	ChargePointStatusCharging      ChargePointStatus = "Charging"
+	ChargePointStatusSuspendedEVSE ChargePointStatus = "SuspendedEVSE"
+	ChargePointStatusSuspendedEV   ChargePointStatus = "SuspendedEV"
+	ChargePointStatusFinishing     ChargePointStatus = "Finishing"
+	ChargePointStatusReserved      ChargePointStatus = "Reserved"
+	ChargePointStatusUnavailable   ChargePointStatus = "Unavailable"
+	ChargePointStatusFaulted       ChargePointStatus = "Faulted"
+)
+
+/****************************************************************************************
+ *	Struct 	: StatusNotificationRequestPayload
+ *
+ * 	Purpose : Handles parameters of the StatusNotification request
+ *
+*****************************************************************************************/
+type StatusNotificationRequestPayload struct {
+	ConnectorId     int                  `json:"connectorId"`
+	ErrorCode       ChargePointErrorCode `json:"errorCode"`
+	Info            string               `json:"info,omitempty"`
+	Status          ChargePointStatus    `json:"status"`
+	Timestamp       string               `json:"timestamp,omitempty"`
+	VendorId        string               `json:"vendorId,omitempty"`
+	VendorErrorCode string               `json:"vendorErrorCode,omitempty"`
+}
+
+/****************************************************************************************
+ *	Struct 	: StatusNotificationResponsePayload
+ *
+ * 	Purpose : StatusNotification response has no parameters
+ *
+*****************************************************************************************/
+type StatusNotificationResponsePayload struct{}
+
+// Permitted transitions of the connector status, regarding OCPP 1.6 section 4.9
+var statusTransitions = map[ChargePointStatus][]ChargePointStatus{
+	ChargePointStatusAvailable:     {ChargePointStatusPreparing, ChargePointStatusCharging, ChargePointStatusSuspendedEV, ChargePointStatusSuspendedEVSE, ChargePointStatusReserved, ChargePointStatusUnavailable, ChargePointStatusFaulted},
+	ChargePointStatusPreparing:     {ChargePointStatusAvailable, ChargePointStatusCharging, ChargePointStatusSuspendedEV, ChargePointStatusSuspendedEVSE, ChargePointStatusFinishing, ChargePointStatusFaulted},
+	ChargePointStatusCharging:      {ChargePointStatusAvailable, ChargePointStatusSuspendedEV, ChargePointStatusSuspendedEVSE, ChargePointStatusFinishing, ChargePointStatusUnavailable, ChargePointStatusFaulted},
+	ChargePointStatusSuspendedEV:   {ChargePointStatusAvailable, ChargePointStatusCharging, ChargePointStatusSuspendedEVSE, ChargePointStatusFinishing, ChargePointStatusUnavailable, ChargePointStatusFaulted},
+	ChargePointStatusSuspendedEVSE: {ChargePointStatusAvailable, ChargePointStatusCharging, ChargePointStatusSuspendedEV, ChargePointStatusFinishing, ChargePointStatusUnavailable, ChargePointStatusFaulted},
+	ChargePointStatusFinishing:     {ChargePointStatusAvailable, ChargePointStatusPreparing, ChargePointStatusUnavailable, ChargePointStatusFaulted},
+	ChargePointStatusReserved:      {ChargePointStatusAvailable, ChargePointStatusPreparing, ChargePointStatusUnavailable, ChargePointStatusFaulted},
+	ChargePointStatusUnavailable:   {ChargePointStatusAvailable, ChargePointStatusPreparing, ChargePointStatusCharging, ChargePointStatusSuspendedEV, ChargePointStatusSuspendedEVSE, ChargePointStatusFaulted},
+	ChargePointStatusFaulted:       {ChargePointStatusAvailable, ChargePointStatusPreparing, ChargePointStatusCharging, ChargePointStatusSuspendedEV, ChargePointStatusSuspendedEVSE, ChargePointStatusFinishing, ChargePointStatusReserved, ChargePointStatusUnavailable},
+}
+
+/****************************************************************************************
+ *
+ * Function : IsValidChargePointStatus
+ *
+ *  Purpose : Check if status is one of the statuses defined by OCPP 1.6
+ *
+ *	  Input : status ChargePointStatus - status to check
+ *			  connectorId int - connector of the status, 0 is the Charge Point itself
+ *
+ *	 Return : true - when status is valid for the connector, otherwise false
+ */
+func IsValidChargePointStatus(status ChargePointStatus, connectorId int) bool {
+	// Charge Point main controller reports only Available, Unavailable and Faulted
+	if connectorId == 0 {
+		return status == ChargePointStatusAvailable || status == ChargePointStatusUnavailable || status == ChargePointStatusFaulted
+	}
+
+	_, isKeyPresent := statusTransitions[status]
+	return isKeyPresent
+}
+
+/****************************************************************************************
+ *
+ * Function : IsValidStatusTransition
+ *
+ *  Purpose : Check if connector can change status from one to other.
+ *			  Repeated status and first status of the connector are always valid
+ *
+ *	  Input : from ChargePointStatus - current status, empty when status is unknown
+ *			  to ChargePointStatus - new status
+ *
+ *	 Return : true - when transition is permitted, otherwise false
+ */
+func IsValidStatusTransition(from ChargePointStatus, to ChargePointStatus) bool {
+	if from == "" || from == to {
+		return true
+	}
+
+	for _, status := range statusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+/****************************************************************************************
+ *
+ * Function : StatusNotificationRequestPayload::Validate
+ *
+ *  Purpose : Check parameters of the StatusNotification request
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if payload is not valid, nil otherwise
+ */
+func (payload *StatusNotificationRequestPayload) Validate() error {
+	if payload.ConnectorId < 0 {
+		return newPayloadError(messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, fmt.Sprintf("Wrong connectorId '%v'", payload.ConnectorId))
+	}
+
+	if payload.ErrorCode == "" {
+		return newPayloadError(messages.ERROR_CODE_OCCURENCE_CONSTRAINT_VIOLATION, "Field 'errorCode' is required")
+	}
+
+	if !isValidChargePointErrorCode(payload.ErrorCode) {
+		return newPayloadError(messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, fmt.Sprintf("Wrong errorCode '%v'", payload.ErrorCode))
+	}
+
+	if payload.Status == "" {
+		return newPayloadError(messages.ERROR_CODE_OCCURENCE_CONSTRAINT_VIOLATION, "Field 'status' is required")
+	}
+
+	if !IsValidChargePointStatus(payload.Status, payload.ConnectorId) {
+		return newPayloadError(messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, fmt.Sprintf("Status '%v' is not valid for connector '%v'", payload.Status, payload.ConnectorId))
+	}
+
+	if err := validateCiString("info", payload.Info, 50, false); err != nil {
+		return err
+	}
+
+	if err := validateCiString("vendorId", payload.VendorId, 255, false); err != nil {
+		return err
+	}
+
+	if err := validateCiString("vendorErrorCode", payload.VendorErrorCode, 50, false); err != nil {
+		return err
+	}
+
+	return validateDateTime("timestamp", payload.Timestamp, false)
+}
+
+/****************************************************************************************
+ *
+ * Function : isValidChargePointErrorCode
+ *
+ *  Purpose : Check if error code is one of the codes defined by OCPP 1.6
+ *
+ *	  Input : errorCode ChargePointErrorCode - error code to check
+ *
+ *	 Return : true - when error code is valid, otherwise false
+ */
+func isValidChargePointErrorCode(errorCode ChargePointErrorCode) bool {
+	switch errorCode {
+	case ChargePointErrorCodeConnectorLockFailure,
+		ChargePointErrorCodeEVCommunicationError,
+		ChargePointErrorCodeGroundFailure,
+		ChargePointErrorCodeHighTemperature,
+		ChargePointErrorCodeInternalError,
+		ChargePointErrorCodeLocalListConflict,
+		ChargePointErrorCodeNoError,
+		ChargePointErrorCodeOtherError,
+		ChargePointErrorCodeOverCurrentFailure,
+		ChargePointErrorCodeOverVoltage,
+		ChargePointErrorCodePowerMeterFailure,
+		ChargePointErrorCodePowerSwitchFailure,
+		ChargePointErrorCodeReaderFailure,
+		ChargePointErrorCodeResetFailure,
+		ChargePointErrorCodeUnderVoltage,
+		ChargePointErrorCodeWeakSignal:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,58 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: status_notification_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: File with test cases for connector status transitions
+	=============================================================================
+*/
+
+package core
+
+import (
+	"fmt"
+	"testing"
+)
+
+/****************************************************************************************
+ *
+ * Function : TestStatusTransitions
+ *
+ *  Purpose : Test permitted and not permitted transitions of the connector status
+ *
+ *   Return : Nothing
+ */
+func TestStatusTransitions(t *testing.T) {
+
+	transitions := []struct {
+		from     ChargePointStatus
+		to       ChargePointStatus
+		expected bool
+	}{
+		{"", ChargePointStatusCharging, true},
+		{ChargePointStatusAvailable, ChargePointStatusPreparing, true},
+		{ChargePointStatusPreparing, ChargePointStatusCharging, true},
+		{ChargePointStatusCharging, ChargePointStatusCharging, true},
+		{ChargePointStatusCharging, ChargePointStatusFinishing, true},
+		{ChargePointStatusFinishing, ChargePointStatusAvailable, true},
+		{ChargePointStatusAvailable, ChargePointStatusFinishing, false},
+		{ChargePointStatusCharging, ChargePointStatusReserved, false},
+		{ChargePointStatusReserved, ChargePointStatusCharging, false},
+		{ChargePointStatusFinishing, ChargePointStatusCharging, false},
+	}
+
+	for _, transition := range transitions {
+		if IsValidStatusTransition(transition.from, transition.to) != transition.expected {
+			t.Error(fmt.Sprintf("Transition from '%v' to '%v' has to be %v", transition.from, transition.to, transition.expected))
+		}
+	}
+
+	// Main controller reports limited set of statuses
+	if IsValidChargePointStatus(ChargePointStatusCharging, 0) || !IsValidChargePointStatus(ChargePointStatusFaulted, 0) {
+		t.Error("Wrong statuses for the connector 0")
+	}
+
+	if IsValidChargePointStatus("Occupied", 1) {
+		t.Error("Status 'Occupied' is not defined by OCPP 1.6")
+	}
+}
@@ -0,0 +1,74 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: stop_transaction.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Describe all methods to work with StopTransaction OCPP message
+	=============================================================================
+*/
+
+package core
+
+type Reason string
+
+const (
+	// Reasons
+	ReasonDeAuthorized   Reason = "DeAuthorized"
+	ReasonEmergencyStop  Reason = "EmergencyStop"
+	ReasonEVDisconnected Reason = "EVDisconnected"
+	ReasonHardReset      Reason = "HardReset"
+	ReasonLocal          Reason = "Local"
+	ReasonOther          Reason = "Other"
+	ReasonPowerLoss      Reason = "PowerLoss"
+	ReasonReboot         Reason = "Reboot"
+	ReasonRemote         Reason = "Remote"
+	ReasonSoftReset      Reason = "SoftReset"
+	ReasonUnlockCommand  Reason = "UnlockCommand"
+)
+
+/****************************************************************************************
+ *	Struct 	: StopTransactionRequestPayload
+ *
+ * 	Purpose : Handles parameters of the StopTransaction request
+ *
+*****************************************************************************************/
+type StopTransactionRequestPayload struct {
+	IdTag           string       `json:"idTag,omitempty"`
+	MeterStop       int          `json:"meterStop"`
+	Timestamp       string       `json:"timestamp"`
+	TransactionId   int          `json:"transactionId"`
+	Reason          Reason       `json:"reason,omitempty"`
+	TransactionData []MeterValue `json:"transactionData,omitempty"`
+}
+
+/****************************************************************************************
+ *	Struct 	: StopTransactionResponsePayload
+ *
+ * 	Purpose : Handles parameters of the StopTransaction response
+ *
+*****************************************************************************************/
+type StopTransactionResponsePayload struct {
+	IdTagInfo *IdTagInfo `json:"idTagInfo,omitempty"`
+}
+
+/****************************************************************************************
+ *
+ * Function : StopTransactionRequestPayload::Validate
+ *
+ *  Purpose : Check parameters of the StopTransaction request
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if payload is not valid, nil otherwise
+ */
+func (payload *StopTransactionRequestPayload) Validate() error {
+	if err := validateCiString("idTag", payload.IdTag, 20, false); err != nil {
+		return err
+	}
+
+	if err := validateDateTime("timestamp", payload.Timestamp, true); err != nil {
+		return err
+	}
+
+	return validateMeterValues("transactionData", payload.TransactionData)
+}
@@ -0,0 +1,42 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: subprotocol.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: WebSocket subprotocol negotiation regarding OCPP-J section 3.
+			 Client offers subprotocols in Sec-WebSocket-Protocol header,
+			 server answers with the one it is going to talk
+	=============================================================================
+*/
+
+package core
+
+const (
+	// WebSocket subprotocol of the OCPP-J 1.6
+	SUBPROTOCOL_OCPP16 string = "ocpp1.6"
+)
+
+/****************************************************************************************
+ *
+ * Function : NegotiateSubprotocol
+ *
+ *  Purpose : Select subprotocol supported by both sides.
+ *			  Order of the supported list is the preference of the server
+ *
+ *    Input : supported []string - subprotocols supported by the server
+ *			  offered []string - subprotocols offered by the client
+ *
+ *   Return : string - agreed subprotocol
+ *			  bool - true when subprotocol is agreed, false otherwise
+ */
+func NegotiateSubprotocol(supported []string, offered []string) (string, bool) {
+	for _, subprotocol := range supported {
+		for _, offeredSubprotocol := range offered {
+			if subprotocol == offeredSubprotocol {
+				return subprotocol, true
+			}
+		}
+	}
+
+	return "", false
+}
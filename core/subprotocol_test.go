@@ -0,0 +1,45 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: subprotocol_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: File with test cases for subprotocol negotiation
+	=============================================================================
+*/
+
+package core
+
+import (
+	"fmt"
+	"testing"
+)
+
+/****************************************************************************************
+ *
+ * Function : TestNegotiateSubprotocol
+ *
+ *  Purpose : Test that subprotocol is selected by preference of the server
+ *
+ *   Return : Nothing
+ */
+func TestNegotiateSubprotocol(t *testing.T) {
+
+	testCases := []struct {
+		supported   []string
+		offered     []string
+		subprotocol string
+		isAgreed    bool
+	}{
+		{[]string{SUBPROTOCOL_OCPP16}, []string{"ocpp1.5", SUBPROTOCOL_OCPP16}, SUBPROTOCOL_OCPP16, true},
+		{[]string{"ocpp2.0.1", SUBPROTOCOL_OCPP16}, []string{SUBPROTOCOL_OCPP16, "ocpp2.0.1"}, "ocpp2.0.1", true},
+		{[]string{SUBPROTOCOL_OCPP16}, []string{"ocpp2.0.1"}, "", false},
+		{[]string{SUBPROTOCOL_OCPP16}, nil, "", false},
+	}
+
+	for _, testCase := range testCases {
+		subprotocol, isAgreed := NegotiateSubprotocol(testCase.supported, testCase.offered)
+		if subprotocol != testCase.subprotocol || isAgreed != testCase.isAgreed {
+			t.Error(fmt.Sprintf("Supported '%v', offered '%v': expected '%v', got '%v'", testCase.supported, testCase.offered, testCase.subprotocol, subprotocol))
+		}
+	}
+}
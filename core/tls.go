@@ -0,0 +1,174 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: tls.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Security Profiles 2 and 3 of the OCPP 1.6 security whitepaper.
+			 Central System serves wss:// with the server certificate (Profile 2),
+			 optionally Charge Points are authenticated by the client certificate
+			 signed by the trusted CA, common name of it is the charger name (Profile 3).
+			 Certificates can be reloaded from the files while server is running
+	=============================================================================
+*/
+
+package core
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+/****************************************************************************************
+ *	Struct 	: CertificateStore
+ *
+ * 	Purpose : Struct keeps server certificate and CA pool of the client certificates.
+ *			  Values are swapped by Reload, new handshakes use them immediately
+ *
+*****************************************************************************************/
+type CertificateStore struct {
+	CertFile          string // Server certificate in PEM format
+	KeyFile           string // Private key of the server certificate in PEM format
+	ClientCAFile      string // CA bundle to verify client certificates, empty - client certificates are not requested
+	RequireClientCert bool   // Handshake without valid client certificate is rejected (Profile 3)
+	certificate       *tls.Certificate
+	clientCAs         *x509.CertPool
+	mux               sync.RWMutex
+}
+
+/****************************************************************************************
+ *
+ * Function : CertificateStoreConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the CertificateStore and loads certificates from files
+ *
+ *	  Input : certFile string - server certificate in PEM format
+ *			  keyFile string - private key of the server certificate in PEM format
+ *			  clientCAFile string - CA bundle to verify client certificates, can be empty
+ *			  requireClientCert bool - reject handshake without valid client certificate
+ *
+ *	 Return : *CertificateStore object
+ *			  error - if files cannot be loaded
+ */
+func CertificateStoreConstructor(certFile string, keyFile string, clientCAFile string, requireClientCert bool) (*CertificateStore, error) {
+	if requireClientCert && clientCAFile == "" {
+		return nil, errors.New("ClientCAFile is required to verify client certificates")
+	}
+
+	store := CertificateStore{}
+	store.CertFile = certFile
+	store.KeyFile = keyFile
+	store.ClientCAFile = clientCAFile
+	store.RequireClientCert = requireClientCert
+
+	if err := store.Reload(); err != nil {
+		return nil, err
+	}
+
+	return &store, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : CertificateStore::Reload
+ *
+ *  Purpose : Load certificates from the files again. On error previous certificates
+ *			  stay in use
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if files cannot be loaded, nil otherwise
+ */
+func (store *CertificateStore) Reload() error {
+	certificate, err := tls.LoadX509KeyPair(store.CertFile, store.KeyFile)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Cannot load server certificate '%v' with error '%v'", store.CertFile, err))
+	}
+
+	var clientCAs *x509.CertPool
+	if store.ClientCAFile != "" {
+		caBytes, err := ioutil.ReadFile(store.ClientCAFile)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Cannot read CA file '%v' with error '%v'", store.ClientCAFile, err))
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caBytes) {
+			return errors.New(fmt.Sprintf("There are no certificates in CA file '%v'", store.ClientCAFile))
+		}
+	}
+
+	store.mux.Lock()
+	store.certificate = &certificate
+	store.clientCAs = clientCAs
+	store.mux.Unlock()
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : CertificateStore::TLSConfig
+ *
+ *  Purpose : Create configuration for the listener. Certificates are taken from
+ *			  the store on every handshake, so reloaded ones are used without restart
+ *
+ *    Input : Nothing
+ *
+ *   Return : *tls.Config
+ */
+func (store *CertificateStore) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12, // Regarding OCPP 1.6 security whitepaper
+		// http.Server.ServeTLS of Go 1.18 requires certificate in the base config
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			store.mux.RLock()
+			defer store.mux.RUnlock()
+
+			return store.certificate, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			store.mux.RLock()
+			defer store.mux.RUnlock()
+
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*store.certificate},
+				ClientAuth:   tls.NoClientCert,
+			}
+
+			if store.clientCAs != nil {
+				config.ClientCAs = store.clientCAs
+				config.ClientAuth = tls.VerifyClientCertIfGiven
+				if store.RequireClientCert {
+					config.ClientAuth = tls.RequireAndVerifyClientCert
+				}
+			}
+
+			return config, nil
+		},
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : ClientCertificateName
+ *
+ *  Purpose : Get charger name from the verified client certificate of the request
+ *
+ *    Input : request *http.Request - http request object
+ *
+ *   Return : string - common name of the client certificate
+ *			  bool - false when request has no verified client certificate
+ */
+func ClientCertificateName(request *http.Request) (string, bool) {
+	if request.TLS == nil || len(request.TLS.VerifiedChains) == 0 || len(request.TLS.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+
+	return request.TLS.VerifiedChains[0][0].Subject.CommonName, true
+}
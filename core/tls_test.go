@@ -0,0 +1,238 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: tls_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: File with test cases for TLS listener and client certificates
+	=============================================================================
+*/
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+/****************************************************************************************
+ *
+ * Function : createTestCertificate
+ *
+ *  Purpose : Create certificate signed by parent, or self-signed CA when parent is nil
+ *
+ *    Input : t *testing.T - test object
+ *			  commonName string - common name of the certificate
+ *			  serial int64 - serial number of the certificate
+ *			  parent *x509.Certificate - issuer certificate
+ *			  parentKey *ecdsa.PrivateKey - key of the issuer
+ *
+ *   Return : *x509.Certificate, *ecdsa.PrivateKey, tls.Certificate
+ */
+func createTestCertificate(t *testing.T, commonName string, serial int64, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, tls.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot generate key with error '%v'", err))
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot create certificate with error '%v'", err))
+	}
+
+	certificate, _ := x509.ParseCertificate(certDER)
+	return certificate, key, tls.Certificate{Certificate: [][]byte{certDER}, PrivateKey: key}
+}
+
+/****************************************************************************************
+ *
+ * Function : writeTestPEM
+ *
+ *  Purpose : Write certificate and key to the files in PEM format
+ *
+ *   Return : Nothing
+ */
+func writeTestPEM(t *testing.T, certFile string, keyFile string, certificate *x509.Certificate, key *ecdsa.PrivateKey) {
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if key != nil {
+		keyDER, _ := x509.MarshalECPrivateKey(key)
+		if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestCertificateStore
+ *
+ *  Purpose : Test mutual TLS with charger name from the client certificate and reload
+ *			  of the server certificate
+ *
+ *   Return : Nothing
+ */
+func TestCertificateStore(t *testing.T) {
+
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.pem")
+
+	caCert, caKey, _ := createTestCertificate(t, "Test CA", 1, nil, nil)
+	serverCert, serverKey, _ := createTestCertificate(t, "localhost", 2, caCert, caKey)
+	_, _, clientCert := createTestCertificate(t, "CP001", 3, caCert, caKey)
+	writeTestPEM(t, caFile, "", caCert, nil)
+	writeTestPEM(t, certFile, keyFile, serverCert, serverKey)
+
+	if _, err := CertificateStoreConstructor(certFile, keyFile, "", true); err == nil {
+		t.Error("Client certificates cannot be required without CA file")
+	}
+
+	store, err := CertificateStoreConstructor(certFile, keyFile, caFile, true)
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot create store with error '%v'", err))
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chargerName, _ := ClientCertificateName(r)
+		fmt.Fprint(w, chargerName)
+	}))
+	server.TLS = store.TLSConfig()
+	server.StartTLS()
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(caCert)
+
+	// New connection per request to get handshake every time
+	request := func(certificates []tls.Certificate) (*http.Response, string, error) {
+		client := http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs, Certificates: certificates}}}
+		response, err := client.Get(server.URL)
+		if err != nil {
+			return nil, "", err
+		}
+		defer response.Body.Close()
+		body, _ := ioutil.ReadAll(response.Body)
+		return response, string(body), nil
+	}
+
+	response, chargerName, err := request([]tls.Certificate{clientCert})
+	if err != nil || chargerName != "CP001" {
+		t.Error(fmt.Sprintf("Expected charger name 'CP001', got '%v' with error '%v'", chargerName, err))
+	}
+
+	// Client certificate is required
+	if _, _, err := request(nil); err == nil {
+		t.Error("Connection without client certificate is accepted")
+	}
+
+	// Reload server certificate without restart of the listener
+	newServerCert, newServerKey, _ := createTestCertificate(t, "localhost", 4, caCert, caKey)
+	writeTestPEM(t, certFile, keyFile, newServerCert, newServerKey)
+	if err := store.Reload(); err != nil {
+		t.Fatal(fmt.Sprintf("Cannot reload certificates with error '%v'", err))
+	}
+
+	response, _, err = request([]tls.Certificate{clientCert})
+	if err != nil || response.TLS.PeerCertificates[0].SerialNumber.Int64() != 4 {
+		t.Error(fmt.Sprintf("Reloaded certificate is not used, error '%v'", err))
+	}
+
+	// Broken file keeps previous certificate
+	ioutil.WriteFile(keyFile, []byte("broken"), 0600)
+	if err := store.Reload(); err == nil {
+		t.Error("Reload of the broken key is not failed")
+	}
+
+	if _, _, err := request([]tls.Certificate{clientCert}); err != nil {
+		t.Error(fmt.Sprintf("Previous certificate is not kept after failed reload, error '%v'", err))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestServeTLS
+ *
+ *  Purpose : Test store with http.Server.ServeTLS without certificate files,
+ *			  as wss:// listener of the server is started
+ *
+ *   Return : Nothing
+ */
+func TestServeTLS(t *testing.T) {
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+
+	serverCert, serverKey, _ := createTestCertificate(t, "localhost", 1, nil, nil)
+	writeTestPEM(t, certFile, keyFile, serverCert, serverKey)
+
+	store, err := CertificateStoreConstructor(certFile, keyFile, "", false)
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot create store with error '%v'", err))
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot listen with error '%v'", err))
+	}
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	})}
+	server.TLSConfig = store.TLSConfig()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ServeTLS(listener, "", "")
+	}()
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(serverCert)
+	client := http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs}}, Timeout: time.Second}
+
+	response, err := client.Get("https://" + listener.Addr().String())
+	if err != nil {
+		select {
+		case serveErr := <-serverErr:
+			t.Fatal(fmt.Sprintf("Server is not started with error '%v'", serveErr))
+		default:
+			t.Fatal(fmt.Sprintf("Request is failed with error '%v'", err))
+		}
+	}
+	defer response.Body.Close()
+
+	if body, _ := ioutil.ReadAll(response.Body); string(body) != "ok" {
+		t.Error(fmt.Sprintf("Wrong response '%v'", string(body)))
+	}
+}
@@ -0,0 +1,203 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: transaction_store.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Storage of the charging transactions reported by Start/StopTransaction
+			 Includes TransactionStore interface and in-memory implementation
+	=============================================================================
+*/
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+/****************************************************************************************
+ *	Struct 	: Transaction
+ *
+ * 	Purpose : Struct handles parameters of the charging transaction
+ *
+*****************************************************************************************/
+type Transaction struct {
+	TransactionId  int    `json:"transactionId"`
+	ChargerName    string `json:"chargerName"`
+	ConnectorId    int    `json:"connectorId"`
+	IdTag          string `json:"idTag"`
+	ReservationId  int    `json:"reservationId,omitempty"`
+	MeterStart     int    `json:"meterStart"`
+	StartTimestamp string `json:"startTimestamp"`
+	MeterStop      int    `json:"meterStop,omitempty"`
+	StopTimestamp  string `json:"stopTimestamp,omitempty"`
+	StopIdTag      string `json:"stopIdTag,omitempty"`
+	StopReason     Reason `json:"stopReason,omitempty"`
+	Stopped        bool   `json:"stopped"`
+}
+
+/****************************************************************************************
+ *	Interface : TransactionStore
+ *
+ * 	  Purpose : Interface to persist transactions. Store allocates transactionId
+ *
+*****************************************************************************************/
+type TransactionStore interface {
+	StartTransaction(chargerName string, request StartTransactionRequestPayload) (Transaction, error)
+	StopTransaction(chargerName string, request StopTransactionRequestPayload) (Transaction, error)
+	GetTransaction(transactionId int) (Transaction, bool)
+	GetActiveTransactionByIdTag(idTag string) (Transaction, bool)
+}
+
+/****************************************************************************************
+ *	Struct 	: MemoryTransactionStore
+ *
+ * 	Purpose : In-memory implementation of the TransactionStore
+ *
+*****************************************************************************************/
+type MemoryTransactionStore struct {
+	lastTransactionId int
+	transactions      map[int]*Transaction
+	storeMux          sync.Mutex
+}
+
+/****************************************************************************************
+ *
+ * Function : MemoryTransactionStoreConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the MemoryTransactionStore
+ *
+ *	  Input : Nothing
+ *
+ *	Return : *MemoryTransactionStore object
+ */
+func MemoryTransactionStoreConstructor() *MemoryTransactionStore {
+	store := MemoryTransactionStore{}
+	store.transactions = make(map[int]*Transaction)
+	return &store
+}
+
+/****************************************************************************************
+ *
+ * Function : MemoryTransactionStore::StartTransaction
+ *
+ *  Purpose : Allocate new transactionId and store started transaction
+ *
+ *    Input : chargerName string - name of the charger started transaction
+ *			  request StartTransactionRequestPayload - payload of the StartTransaction request
+ *
+ *   Return : Transaction - stored transaction with allocated transactionId
+ *			  error - if happened, nil otherwise
+ */
+func (store *MemoryTransactionStore) StartTransaction(chargerName string, request StartTransactionRequestPayload) (Transaction, error) {
+	// Lock the store before any changes
+	store.storeMux.Lock()
+	defer store.storeMux.Unlock()
+
+	store.lastTransactionId++
+
+	transaction := Transaction{
+		TransactionId:  store.lastTransactionId,
+		ChargerName:    chargerName,
+		ConnectorId:    request.ConnectorId,
+		IdTag:          request.IdTag,
+		ReservationId:  request.ReservationId,
+		MeterStart:     request.MeterStart,
+		StartTimestamp: request.Timestamp,
+	}
+	store.transactions[transaction.TransactionId] = &transaction
+
+	return transaction, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : MemoryTransactionStore::StopTransaction
+ *
+ *  Purpose : Store meter value, timestamp and reason of the stopped transaction
+ *
+ *    Input : chargerName string - name of the charger stopped transaction
+ *			  request StopTransactionRequestPayload - payload of the StopTransaction request
+ *
+ *   Return : Transaction - stopped transaction
+ *			  error - if happened, nil otherwise
+ */
+func (store *MemoryTransactionStore) StopTransaction(chargerName string, request StopTransactionRequestPayload) (Transaction, error) {
+	// Lock the store before any changes
+	store.storeMux.Lock()
+	defer store.storeMux.Unlock()
+
+	transaction, isKeyPresent := store.transactions[request.TransactionId]
+	if !isKeyPresent {
+		return Transaction{}, errors.New(fmt.Sprintf("Transaction '%v' is not exists", request.TransactionId))
+	}
+
+	if transaction.ChargerName != chargerName {
+		return Transaction{}, errors.New(fmt.Sprintf("Transaction '%v' belongs to other charger", request.TransactionId))
+	}
+
+	if transaction.Stopped {
+		return *transaction, errors.New(fmt.Sprintf("Transaction '%v' is stopped already", request.TransactionId))
+	}
+
+	// Reason is omitted by the charger when transaction is stopped locally
+	reason := request.Reason
+	if reason == "" {
+		reason = ReasonLocal
+	}
+
+	transaction.MeterStop = request.MeterStop
+	transaction.StopTimestamp = request.Timestamp
+	transaction.StopIdTag = request.IdTag
+	transaction.StopReason = reason
+	transaction.Stopped = true
+
+	return *transaction, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : MemoryTransactionStore::GetTransaction
+ *
+ *  Purpose : Get transaction from the store by transactionId
+ *
+ *    Input : transactionId int - id of the transaction
+ *
+ *   Return : Transaction
+ *			  bool - true when transaction exists, false otherwise
+ */
+func (store *MemoryTransactionStore) GetTransaction(transactionId int) (Transaction, bool) {
+	store.storeMux.Lock()
+	defer store.storeMux.Unlock()
+
+	if transaction, isKeyPresent := store.transactions[transactionId]; isKeyPresent {
+		return *transaction, true
+	}
+
+	return Transaction{}, false
+}
+
+/****************************************************************************************
+ *
+ * Function : MemoryTransactionStore::GetActiveTransactionByIdTag
+ *
+ *  Purpose : Get not stopped transaction started by the idTag
+ *
+ *    Input : idTag string - identifier which started transaction
+ *
+ *   Return : Transaction
+ *			  bool - true when active transaction exists, false otherwise
+ */
+func (store *MemoryTransactionStore) GetActiveTransactionByIdTag(idTag string) (Transaction, bool) {
+	store.storeMux.Lock()
+	defer store.storeMux.Unlock()
+
+	for _, transaction := range store.transactions {
+		if !transaction.Stopped && transaction.IdTag == idTag {
+			return *transaction, true
+		}
+	}
+
+	return Transaction{}, false
+}
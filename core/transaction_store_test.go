@@ -0,0 +1,71 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: transaction_store_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: File with test cases for MemoryTransactionStore
+	=============================================================================
+*/
+
+package core
+
+import (
+	"fmt"
+	"testing"
+)
+
+/****************************************************************************************
+ *
+ * Function : TestMemoryTransactionStore
+ *
+ *  Purpose : Test allocation of transactionId, start and stop of the transaction
+ *
+ *   Return : Nothing
+ */
+func TestMemoryTransactionStore(t *testing.T) {
+
+	store := MemoryTransactionStoreConstructor()
+
+	first, _ := store.StartTransaction("CP0001", StartTransactionRequestPayload{ConnectorId: 1, IdTag: "TAG1", MeterStart: 100, Timestamp: "2022-07-01T10:00:00Z"})
+	second, _ := store.StartTransaction("CP0001", StartTransactionRequestPayload{ConnectorId: 2, IdTag: "TAG2", MeterStart: 200, Timestamp: "2022-07-01T10:05:00Z"})
+
+	if first.TransactionId == second.TransactionId {
+		t.Error(fmt.Sprintf("TransactionId '%v' allocated twice", first.TransactionId))
+	}
+
+	// Charger is not owning the transaction
+	if _, err := store.StopTransaction("CP0002", StopTransactionRequestPayload{TransactionId: first.TransactionId}); err == nil {
+		t.Error("Expected error when other charger stops transaction")
+	}
+
+	stopped, err := store.StopTransaction("CP0001", StopTransactionRequestPayload{TransactionId: first.TransactionId, MeterStop: 1500, Timestamp: "2022-07-01T11:00:00Z"})
+	if err != nil {
+		t.Error(fmt.Sprintf("Unexpected error '%v'", err))
+		return
+	}
+
+	if !stopped.Stopped || stopped.MeterStop != 1500 || stopped.StopReason != ReasonLocal {
+		t.Error(fmt.Sprintf("Wrong stopped transaction '%v'", stopped))
+	}
+
+	// Transaction cannot be stopped twice
+	if _, err := store.StopTransaction("CP0001", StopTransactionRequestPayload{TransactionId: first.TransactionId}); err == nil {
+		t.Error("Expected error when transaction is stopped twice")
+	}
+
+	if transaction, exists := store.GetTransaction(second.TransactionId); !exists || transaction.Stopped {
+		t.Error(fmt.Sprintf("Wrong state of the transaction '%v'", transaction))
+	}
+
+	if active, exists := store.GetActiveTransactionByIdTag("TAG2"); !exists || active.TransactionId != second.TransactionId {
+		t.Error(fmt.Sprintf("Wrong active transaction of the idTag 'TAG2' '%v'", active))
+	}
+
+	if _, exists := store.GetActiveTransactionByIdTag("TAG1"); exists {
+		t.Error("IdTag 'TAG1' has no active transactions")
+	}
+
+	if _, exists := store.GetTransaction(100); exists {
+		t.Error("Transaction 100 must not exist")
+	}
+}
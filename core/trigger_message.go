@@ -3,7 +3,7 @@
 	Filename: trigger_message.go
 	Owner: Sergiy Safronov
 	Source : github.com/CoderSergiy/ocpp16-go/core
-	Purpose: Describe all methods to work with BootNotification OCPP message
+	Purpose: Describe all methods to work with TriggerMessage OCPP message
 	=============================================================================
 */
 
@@ -24,8 +24,6 @@ const (
 	TriggerMessageTypeHeartbeat                     TriggerMessageType = "Heartbeat"
 	TriggerMessageTypeMeterValues                   TriggerMessageType = "MeterValues"
 	TriggerMessageTypeStatusNotification            TriggerMessageType = "StatusNotification"
-
-	ACTION_TRIGGERMESSAGE string = "TriggerMessage"
 )
 
 /****************************************************************************************
@@ -35,8 +33,8 @@ const (
  *
 *****************************************************************************************/
 type TriggerMessageRequestPayload struct {
-	requestedMessage TriggerMessageType //`json:"requestedMessage"`
-	connectorId      int                //`json:"connectorId"`
+	RequestedMessage TriggerMessageType `json:"requestedMessage"`
+	ConnectorId      int                `json:"connectorId,omitempty"`
 }
 
 /****************************************************************************************
@@ -53,8 +51,8 @@ type TriggerMessageRequestPayload struct {
 func CreateTriggerMessageRequestPayload(reqMessageType TriggerMessageType, connectorId int) TriggerMessageRequestPayload {
 	triggerMessageRequestPayload := TriggerMessageRequestPayload{}
 
-	triggerMessageRequestPayload.requestedMessage = reqMessageType
-	triggerMessageRequestPayload.connectorId = connectorId
+	triggerMessageRequestPayload.RequestedMessage = reqMessageType
+	triggerMessageRequestPayload.ConnectorId = connectorId
 
 	return triggerMessageRequestPayload
 }
@@ -71,11 +69,7 @@ func CreateTriggerMessageRequestPayload(reqMessageType TriggerMessageType, conne
  */
 func (triggerMessageRequestPayload *TriggerMessageRequestPayload) GetPayload() map[string]interface{} {
 
-	payload := make(map[string]interface{})
-	payload["requestedMessage"] = string(triggerMessageRequestPayload.requestedMessage)
-	if triggerMessageRequestPayload.connectorId > 0 {
-		payload["connectorId"] = triggerMessageRequestPayload.connectorId
-	}
+	payload, _ := MarshalPayload(triggerMessageRequestPayload)
 
 	return payload
 }
@@ -87,7 +81,7 @@ func (triggerMessageRequestPayload *TriggerMessageRequestPayload) GetPayload() m
  *
 *****************************************************************************************/
 type TriggerMessageResponsePayload struct {
-	status TriggerMessageStatus //`json:"requestedMessage"`
+	Status TriggerMessageStatus `json:"status"`
 }
 
 /****************************************************************************************
@@ -0,0 +1,95 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: types.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Data types shared by several OCPP messages
+	=============================================================================
+*/
+
+package core
+
+type AuthorizationStatus string
+type ChargingProfilePurposeType string
+type ChargingProfileKindType string
+type RecurrencyKindType string
+type ChargingRateUnitType string
+
+const (
+	// AuthorizationStatuses
+	AuthorizationStatusAccepted     AuthorizationStatus = "Accepted"
+	AuthorizationStatusBlocked      AuthorizationStatus = "Blocked"
+	AuthorizationStatusExpired      AuthorizationStatus = "Expired"
+	AuthorizationStatusInvalid      AuthorizationStatus = "Invalid"
+	AuthorizationStatusConcurrentTx AuthorizationStatus = "ConcurrentTx"
+	// ChargingProfilePurposeTypes
+	ChargingProfilePurposeChargePointMaxProfile ChargingProfilePurposeType = "ChargePointMaxProfile"
+	ChargingProfilePurposeTxDefaultProfile      ChargingProfilePurposeType = "TxDefaultProfile"
+	ChargingProfilePurposeTxProfile             ChargingProfilePurposeType = "TxProfile"
+	// ChargingProfileKindTypes
+	ChargingProfileKindAbsolute  ChargingProfileKindType = "Absolute"
+	ChargingProfileKindRecurring ChargingProfileKindType = "Recurring"
+	ChargingProfileKindRelative  ChargingProfileKindType = "Relative"
+	// RecurrencyKindTypes
+	RecurrencyKindDaily  RecurrencyKindType = "Daily"
+	RecurrencyKindWeekly RecurrencyKindType = "Weekly"
+	// ChargingRateUnitTypes
+	ChargingRateUnitWatts   ChargingRateUnitType = "W"
+	ChargingRateUnitAmperes ChargingRateUnitType = "A"
+)
+
+/****************************************************************************************
+ *	Struct 	: IdTagInfo
+ *
+ * 	Purpose : Status information about an identifier
+ *
+*****************************************************************************************/
+type IdTagInfo struct {
+	ExpiryDate  string              `json:"expiryDate,omitempty"`
+	ParentIdTag string              `json:"parentIdTag,omitempty"`
+	Status      AuthorizationStatus `json:"status"`
+}
+
+/****************************************************************************************
+ *	Struct 	: ChargingSchedulePeriod
+ *
+ * 	Purpose : Defines a time period in a charging schedule
+ *
+*****************************************************************************************/
+type ChargingSchedulePeriod struct {
+	StartPeriod  int     `json:"startPeriod"`
+	Limit        float64 `json:"limit"`
+	NumberPhases int     `json:"numberPhases,omitempty"`
+}
+
+/****************************************************************************************
+ *	Struct 	: ChargingSchedule
+ *
+ * 	Purpose : Defines a list of charging periods
+ *
+*****************************************************************************************/
+type ChargingSchedule struct {
+	Duration               int                      `json:"duration,omitempty"`
+	StartSchedule          string                   `json:"startSchedule,omitempty"`
+	ChargingRateUnit       ChargingRateUnitType     `json:"chargingRateUnit"`
+	ChargingSchedulePeriod []ChargingSchedulePeriod `json:"chargingSchedulePeriod"`
+	MinChargingRate        float64                  `json:"minChargingRate,omitempty"`
+}
+
+/****************************************************************************************
+ *	Struct 	: ChargingProfile
+ *
+ * 	Purpose : Charging profile with a charging schedule, used in RemoteStartTransaction
+ *
+*****************************************************************************************/
+type ChargingProfile struct {
+	ChargingProfileId      int                        `json:"chargingProfileId"`
+	TransactionId          int                        `json:"transactionId,omitempty"`
+	StackLevel             int                        `json:"stackLevel"`
+	ChargingProfilePurpose ChargingProfilePurposeType `json:"chargingProfilePurpose"`
+	ChargingProfileKind    ChargingProfileKindType    `json:"chargingProfileKind"`
+	RecurrencyKind         RecurrencyKindType         `json:"recurrencyKind,omitempty"`
+	ValidFrom              string                     `json:"validFrom,omitempty"`
+	ValidTo                string                     `json:"validTo,omitempty"`
+	ChargingSchedule       ChargingSchedule           `json:"chargingSchedule"`
+}
@@ -0,0 +1,63 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: unlock_connector.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/core
+	Purpose: Describe all methods to work with UnlockConnector OCPP message
+	=============================================================================
+*/
+
+package core
+
+import (
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+)
+
+type UnlockStatus string
+
+const (
+	// UnlockStatuses
+	UnlockStatusUnlocked     UnlockStatus = "Unlocked"
+	UnlockStatusUnlockFailed UnlockStatus = "UnlockFailed"
+	UnlockStatusNotSupported UnlockStatus = "NotSupported"
+)
+
+/****************************************************************************************
+ *	Struct 	: UnlockConnectorRequestPayload
+ *
+ * 	Purpose : Handles parameters of the UnlockConnector request
+ *
+*****************************************************************************************/
+type UnlockConnectorRequestPayload struct {
+	ConnectorId int `json:"connectorId"`
+}
+
+/****************************************************************************************
+ *	Struct 	: UnlockConnectorResponsePayload
+ *
+ * 	Purpose : Handles parameters of the UnlockConnector response
+ *
+*****************************************************************************************/
+type UnlockConnectorResponsePayload struct {
+	Status UnlockStatus `json:"status"`
+}
+
+/****************************************************************************************
+ *
+ * Function : UnlockConnectorRequestPayload::Validate
+ *
+ *  Purpose : Check parameters of the UnlockConnector request
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if payload is not valid, nil otherwise
+ */
+func (payload *UnlockConnectorRequestPayload) Validate() error {
+	// Connector 0 is the Charge Point itself and cannot be unlocked
+	if payload.ConnectorId <= 0 {
+		return newPayloadError(messages.ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, fmt.Sprintf("Wrong connectorId '%v'", payload.ConnectorId))
+	}
+
+	return nil
+}
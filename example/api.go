@@ -7,20 +7,27 @@
 			 File includes APIs:
 				- messageStatusHandler
 				- triggerActionHandler
+				- commandHandler
 				- chargerStatusHandler
+				- meterValuesHandler
+				- chargersHandlers (list, create, update, delete, rotate AuthToken)
 	=============================================================================
 */
 
 package example
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"github.com/CoderSergiy/golib/logging"
 	"github.com/CoderSergiy/ocpp16-go/core"
 	"github.com/CoderSergiy/ocpp16-go/messages"
-	"github.com/google/uuid"
 	"github.com/julienschmidt/httprouter"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 )
 
 /****************************************************************************************
@@ -69,6 +76,89 @@ func GetChargerStatusAPI(chargerName string, serverConfigs *Configs, log *loggin
 	w.Write(jsonResult)
 }
 
+/****************************************************************************************
+ *
+ * Function : GetMeterValuesAPI
+ *
+ *  Purpose : Get meter readings of the charger for the time range and send to the client by http
+ *
+ *    Input : chargerName string - charger name
+ *            query url.Values - query parameters: from, to (RFC3339), connectorId, transactionId
+ *            serverConfigs *Configs - pointer to the chargers arrays
+ *            meterValues core.MeterValueStore - store of the meter readings
+ *            log *logging.Log - pointer to the log
+ *            w http.ResponseWriter - http response
+ *
+ *   Return : Nothing
+ */
+func GetMeterValuesAPI(chargerName string, query url.Values, serverConfigs *Configs, meterValues core.MeterValueStore, log *logging.Log, w http.ResponseWriter) {
+	log.Info_Log("GetMeterValuesAPI")
+
+	if chargerName == "" {
+		log.Error_Log("chargerName parameter is empty")
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	// Check if charger exists in the Configs
+	if _, err := serverConfigs.GetChargerObj(chargerName); err != nil {
+		log.Error_Log("GetChargerObj for '%v' returns error '%v'", chargerName, err)
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	// Create filter from the query parameters
+	filter := core.MeterReadingFilter{ChargerName: chargerName, ConnectorId: -1}
+	var err error
+
+	if from := query.Get("from"); from != "" {
+		if filter.From, err = core.ParseDateTime(from); err != nil {
+			log.Error_Log("[%s] Wrong 'from' parameter '%v'", chargerName, from)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if to := query.Get("to"); to != "" {
+		if filter.To, err = core.ParseDateTime(to); err != nil {
+			log.Error_Log("[%s] Wrong 'to' parameter '%v'", chargerName, to)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if connectorId := query.Get("connectorId"); connectorId != "" {
+		if filter.ConnectorId, err = strconv.Atoi(connectorId); err != nil || filter.ConnectorId < 0 {
+			log.Error_Log("[%s] Wrong 'connectorId' parameter '%v'", chargerName, connectorId)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if transactionId := query.Get("transactionId"); transactionId != "" {
+		if filter.TransactionId, err = strconv.Atoi(transactionId); err != nil {
+			log.Error_Log("[%s] Wrong 'transactionId' parameter '%v'", chargerName, transactionId)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	readings := meterValues.GetMeterReadings(filter)
+	log.Info_Log("[%s] Found %v meter readings", chargerName, len(readings))
+
+	jsonResult, err := json.Marshal(readings)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		log.Error_Log("[%s] Cannot marshal meter readings", chargerName)
+		return
+	}
+
+	// Send response in json format
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonResult)
+}
+
 /****************************************************************************************
  *
  * Function : GetMessageStatusAPI
@@ -76,13 +166,14 @@ func GetChargerStatusAPI(chargerName string, serverConfigs *Configs, log *loggin
  *  Purpose : Get message from the queue and send to the client by http
  *
  *    Input : reference string - unique reference
- *            MQueue *SimpleMessageQueue - pointer to the Message Queue
+ *            chargerName string - charger of the message, empty - any charger
+ *            MQueue MessageQueue - queue of the messages
  *            log *logging.Log - pointer to the log
  *            w http.ResponseWriter - http response
  *
  *   Return : Nothing
  */
-func GetMessageStatusAPI(reference string, MQueue *SimpleMessageQueue, log *logging.Log, w http.ResponseWriter) {
+func GetMessageStatusAPI(reference string, chargerName string, MQueue MessageQueue, log *logging.Log, w http.ResponseWriter) {
 	log.Info_Log("[%s] GetMessageStatus", reference)
 
 	if reference == "" {
@@ -91,12 +182,24 @@ func GetMessageStatusAPI(reference string, MQueue *SimpleMessageQueue, log *logg
 		return
 	}
 
-	message, success := MQueue.GetMessage(reference)
-	if !success {
+	// Chargers choose unique IDs independently, the same ID can be used by several chargers
+	found := make([]Message, 0)
+	for _, message := range MQueue.List(MessageFilter{ChargerName: chargerName}) {
+		if message.UniqueID == reference {
+			found = append(found, message)
+		}
+	}
+	if len(found) == 0 {
 		http.Error(w, string(CreateFailResponse("Message is not exist")), http.StatusOK)
 		log.Error_Log("[%s] Message is not exists in the queue with uniqueID", reference)
 		return
 	}
+	if len(found) > 1 {
+		http.Error(w, string(CreateFailResponse("Message is exchanged with several chargers, set charger parameter")), http.StatusOK)
+		log.Error_Log("[%s] Message is exchanged with %v chargers", reference, len(found))
+		return
+	}
+	message := found[0]
 
 	log.Info_Log("[%v] Message with action '%s'", reference, message.Action)
 
@@ -113,21 +216,118 @@ func GetMessageStatusAPI(reference string, MQueue *SimpleMessageQueue, log *logg
 	w.Write(jsonResult)
 }
 
+/****************************************************************************************
+ *
+ * Function : GetMessagesAPI
+ *
+ *  Purpose : Get messages of the queue selected by the query and send to the client by http
+ *
+ *    Input : query url.Values - query parameters: charger, direction, action, status, from, to (RFC3339)
+ *            MQueue MessageQueue - queue of the messages
+ *            log *logging.Log - pointer to the log
+ *            w http.ResponseWriter - http response
+ *
+ *   Return : Nothing
+ */
+func GetMessagesAPI(query url.Values, MQueue MessageQueue, log *logging.Log, w http.ResponseWriter) {
+	log.Info_Log("GetMessagesAPI")
+
+	// Create filter from the query parameters
+	filter := MessageFilter{ChargerName: query.Get("charger"), Action: query.Get("action")}
+	var err error
+
+	switch direction := query.Get("direction"); direction {
+	case "", MESSAGE_DIRECTION_INCOMING, MESSAGE_DIRECTION_OUTGOING:
+		filter.Direction = direction
+	default:
+		log.Error_Log("Wrong 'direction' parameter '%v'", direction)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if status := query.Get("status"); status != "" {
+		if filter.Status, err = ParseQueueMessageType(status); err != nil {
+			log.Error_Log("Wrong 'status' parameter '%v'", status)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if from := query.Get("from"); from != "" {
+		if filter.From, err = core.ParseDateTime(from); err != nil {
+			log.Error_Log("Wrong 'from' parameter '%v'", from)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if to := query.Get("to"); to != "" {
+		if filter.To, err = core.ParseDateTime(to); err != nil {
+			log.Error_Log("Wrong 'to' parameter '%v'", to)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	messages := MQueue.List(filter)
+	log.Info_Log("Found %v messages", len(messages))
+
+	jsonResult, err := json.Marshal(messages)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		log.Error_Log("Cannot marshal messages")
+		return
+	}
+
+	// Send response in json format
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonResult)
+}
+
+/****************************************************************************************
+ *
+ * Function : GetQueueStatsAPI
+ *
+ *  Purpose : Send statistics of the message queue to the client by http
+ *
+ *    Input : MQueue MessageQueue - queue of the messages
+ *            log *logging.Log - pointer to the log
+ *            w http.ResponseWriter - http response
+ *
+ *   Return : Nothing
+ */
+func GetQueueStatsAPI(MQueue MessageQueue, log *logging.Log, w http.ResponseWriter) {
+	stats := MQueue.Stats()
+	log.Info_Log("Queue has %v messages, max size is %v", stats.Size, stats.MaxSize)
+
+	jsonResult, err := json.Marshal(stats)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		log.Error_Log("Cannot marshal queue statistics")
+		return
+	}
+
+	// Send response in json format
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonResult)
+}
+
 /****************************************************************************************
  *
  * Function : TriggerActionAPI
  *
  *  Purpose : Handles TriggerAction API request
  *
- *    Input : serverConfigs *Configs - pointer to the chargers arrays
- *            MQueue *SimpleMessageQueue - pointer to the Message Queue
+ *    Input : centralSystem *CentralSystem - pointer to the central system to send Call
  *            log *logging.Log - pointer to the log
  *            ps httprouter.Params - router parameters
  *            w http.ResponseWriter - http response
  *
  *   Return : Nothing
  */
-func TriggerActionAPI(serverConfigs *Configs, MQueue *SimpleMessageQueue, log *logging.Log, ps httprouter.Params, w http.ResponseWriter) {
+func TriggerActionAPI(centralSystem *CentralSystem, log *logging.Log, ps httprouter.Params, w http.ResponseWriter) {
 	log.Info_Log("TriggerActionAPI")
 
 	chargerName := ps.ByName("chargerName")
@@ -141,8 +341,139 @@ func TriggerActionAPI(serverConfigs *Configs, MQueue *SimpleMessageQueue, log *l
 
 	log.Info_Log("Charger name '%v' and action '%v'", chargerName, action)
 
+	// Sanitize the TriggerMessage type from the request
+	if !core.SanitizeTriggerMessageType(action) {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		log.Error_Log("[%s] TriggerMessage type '%v' is not supported", chargerName, action)
+		return
+	}
+
+	// Generate Call request payload for the TriggerMessage
+	triggerMessagePayload := core.CreateTriggerMessageRequestPayload(core.TriggerMessageType(action), 0)
+
+	sendCommand(centralSystem, chargerName, core.ACTION_TRIGGERMESSAGE, triggerMessagePayload.GetPayload(), log, w)
+}
+
+/****************************************************************************************
+ *
+ * Function : CommandAPI
+ *
+ *  Purpose : Handles API request to send Central System initiated Call to the charger.
+ *			  Body of the request is the payload of the Call in JSON format
+ *
+ *    Input : action string - action of the Call
+ *            centralSystem *CentralSystem - pointer to the central system to send Call
+ *            log *logging.Log - pointer to the log
+ *            ps httprouter.Params - router parameters
+ *            r *http.Request - http request object
+ *            w http.ResponseWriter - http response
+ *
+ *   Return : Nothing
+ */
+func CommandAPI(action string, centralSystem *CentralSystem, log *logging.Log, ps httprouter.Params, r *http.Request, w http.ResponseWriter) {
+	log.Info_Log("CommandAPI")
+
+	chargerName := ps.ByName("chargerName")
+	if chargerName == "" {
+		log.Error_Log("Charger name is empty for action '%v'", action)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	log.Info_Log("Charger name '%v' and action '%v'", chargerName, action)
+
+	requestPayload, isSupported := newCommandPayload(action)
+	if !isSupported {
+		log.Error_Log("[%s] Action '%v' is not supported", chargerName, action)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	// Decode body of the request to the payload struct, empty body is allowed for requests without required fields
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(requestPayload); err != nil && err != io.EOF {
+		log.Error_Log("[%s] Cannot decode '%v' payload with error '%v'", chargerName, action, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(CreateFailResponse(err.Error())))
+		return
+	}
+
+	// Validate payload against OCPP requirements
+	if err := requestPayload.Validate(); err != nil {
+		log.Error_Log("[%s] Payload of the '%v' is not valid with error '%v'", chargerName, action, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(CreateFailResponse(err.Error())))
+		return
+	}
+
+	payload, err := core.MarshalPayload(requestPayload)
+	if err != nil {
+		log.Error_Log("[%s] Cannot convert '%v' payload with error '%v'", chargerName, action, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	sendCommand(centralSystem, chargerName, action, payload, log, w)
+}
+
+/****************************************************************************************
+ *
+ * Function : newCommandPayload
+ *
+ *  Purpose : Create payload struct of the Call supported by CommandAPI
+ *
+ *    Input : action string - action of the Call
+ *
+ *   Return : core.RequestPayload - pointer to the empty payload struct
+ *			  bool - true when action is supported, false otherwise
+ */
+func newCommandPayload(action string) (core.RequestPayload, bool) {
+	switch action {
+	case core.ACTION_CHANGEAVAILABILITY:
+		return &core.ChangeAvailabilityRequestPayload{}, true
+	case core.ACTION_CHANGECONFIGURATION:
+		return &core.ChangeConfigurationRequestPayload{}, true
+	case core.ACTION_CLEARCACHE:
+		return &core.ClearCacheRequestPayload{}, true
+	case core.ACTION_DATATRANSFER:
+		return &core.DataTransferRequestPayload{}, true
+	case core.ACTION_GETCONFIGURATION:
+		return &core.GetConfigurationRequestPayload{}, true
+	case core.ACTION_REMOTESTARTTRANSACTION:
+		return &core.RemoteStartTransactionRequestPayload{}, true
+	case core.ACTION_REMOTESTOPTRANSACTION:
+		return &core.RemoteStopTransactionRequestPayload{}, true
+	case core.ACTION_RESET:
+		return &core.ResetRequestPayload{}, true
+	case core.ACTION_UNLOCKCONNECTOR:
+		return &core.UnlockConnectorRequestPayload{}, true
+	}
+
+	return nil, false
+}
+
+/****************************************************************************************
+ *
+ * Function : sendCommand
+ *
+ *  Purpose : Store Call in the queue, send it to the charger in background
+ *			  and reply to the client with reference of the message
+ *
+ *    Input : centralSystem *CentralSystem - pointer to the central system to send Call
+ *            chargerName string - name of the charger
+ *            action string - action of the Call
+ *            payload map[string]interface{} - payload of the Call
+ *            log *logging.Log - pointer to the log
+ *            w http.ResponseWriter - http response
+ *
+ *   Return : Nothing
+ */
+func sendCommand(centralSystem *CentralSystem, chargerName string, action string, payload map[string]interface{}, log *logging.Log, w http.ResponseWriter) {
 	// Get Charger from the Configs
-	chargerObj, err := serverConfigs.GetChargerObj(chargerName)
+	chargerObj, err := centralSystem.Configs.GetChargerObj(chargerName)
 	if err != nil || chargerObj == nil {
 		// There is no charger with specified name in the configs
 		log.Error_Log("[%s] GetChargerObj returns error '%v'", chargerName, err)
@@ -150,48 +481,385 @@ func TriggerActionAPI(serverConfigs *Configs, MQueue *SimpleMessageQueue, log *l
 		return
 	}
 
-	// Sanitize the TriggerMessage type from the request
-	if !core.SanitizeTriggerMessageType(action) {
+	// Call to the offline charger is buffered when OfflineCallTimeout is set
+	if !chargerObj.IsConnected() && centralSystem.Configs.OfflineCallTimeout <= 0 {
+		log.Error_Log("[%s] Charger is not connected", chargerName)
 		http.Error(w, "Bad Request", http.StatusBadRequest)
-		log.Error_Log("[%s] TriggerMessage type '%v' is not supported", chargerName, action)
+		return
+	}
+	callTimeout := centralSystem.CallTimeout(chargerObj)
+
+	// Store Call request to the charger in the queue
+	callMessageRequest, queueErr := centralSystem.QueueCall(chargerName, action, payload)
+	if schemaErr, isSchemaError := queueErr.(*messages.SchemaError); isSchemaError {
+		// Payload is not valid by OCPP 1.6 schema of the action
+		log.Error_Log("[%s] Payload of the '%v' is not valid by schema with error '%v'", chargerName, action, schemaErr)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(CreateFailResponse(schemaErr.Error())))
+		return
+	}
+	if queueErr == ErrOutboundQueueFull {
+		// Client should retry when charger answers previous Calls
+		log.Error_Log("[%s] Call '%v' is rejected with error '%v'", chargerName, action, queueErr)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(CreateFailResponse(queueErr.Error())))
+		return
+	}
+	if queueErr != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		log.Error_Log("[%s] Error to add message to the queue, error: '%v'", chargerName, queueErr)
 		return
 	}
 
-	id := uuid.New()
-	// Generate Call request payload for the TriggerMessage
-	triggerMessagePayload := core.CreateTriggerMessageRequestPayload(core.TriggerMessageType(action), 0)
-	// Generate Call request to the charger
-	callMessageRequest := messages.CreateCallMessage(
-		id.String(),
-		core.ACTION_TRIGGERMESSAGE,
-		triggerMessagePayload.GetPayload(),
-	)
-
-	// Convert Call message to string
-	callMessageString, messageErr := callMessageRequest.ToString()
-	if messageErr != nil {
+	// Send Call in background, client is polling status of the message
+	centralSystem.WaitCallInBackground(chargerName, callMessageRequest, callTimeout, func(err error) {
+		log.Error_Log("[%s] Call [%v] is failed with error '%v'", chargerName, callMessageRequest.UniqueID, err)
+	})
+
+	// Send response in json format
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(CreateSuccessResponse(callMessageRequest.UniqueID))
+}
+
+/****************************************************************************************
+ *
+ * Function : ListChargersAPI
+ *
+ *  Purpose : Send status of all chargers of the registry to the client by http
+ *
+ *    Input : registry ChargerRegistry - registry of the chargers
+ *            log *logging.Log - pointer to the log
+ *            w http.ResponseWriter - http response
+ *
+ *   Return : Nothing
+ */
+func ListChargersAPI(registry ChargerRegistry, log *logging.Log, w http.ResponseWriter) {
+	log.Info_Log("ListChargersAPI")
+
+	jsonResult, err := json.Marshal(registry.List())
+	if err != nil {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		log.Error_Log("[%s] Error to generate callMessage: '%v'", chargerName, messageErr)
+		log.Error_Log("Cannot marshal chargers with error '%v'", err)
+		return
+	}
+
+	// Send response in json format
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonResult)
+}
+
+/****************************************************************************************
+ *
+ * Function : CreateChargerAPI
+ *
+ *  Purpose : Add charger to the registry, charger can connect right after it.
+ *			  Body of the request is the charger in the format of configs.json
+ *
+ *    Input : registry ChargerRegistry - registry of the chargers
+ *            log *logging.Log - pointer to the log
+ *            r *http.Request - http request object
+ *            w http.ResponseWriter - http response
+ *
+ *   Return : Nothing
+ */
+func CreateChargerAPI(registry ChargerRegistry, log *logging.Log, r *http.Request, w http.ResponseWriter) {
+	log.Info_Log("CreateChargerAPI")
+
+	settings, ok := decodeChargerSettings(r, ChargerFromFile{}, log, w)
+	if !ok {
 		return
 	}
 
-	// Create message for the queue
-	queueMessage := Message{Action: action, Sent: callMessageString, Status: MESSAGE_TYPE_NEW, Received: ""}
-	// Add message to the queue
-	addingErr := MQueue.Add(callMessageRequest.UniqueID, queueMessage)
-	if addingErr != nil {
+	chargerObj, err := registry.Create(settings)
+	if err == ErrChargerExists {
+		log.Error_Log("[%s] Charger exists already", settings.Name)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(CreateFailResponse(err.Error())))
+		return
+	}
+	if err != nil {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		log.Error_Log("[%s] Error to add message [%v] to the queue, error: '%v'", chargerName, callMessageRequest.UniqueID, addingErr)
+		log.Error_Log("[%s] Cannot create charger with error '%v'", settings.Name, err)
 		return
 	}
 
-	// Send to write goroutine message's uniqueid
-	chargerObj.WriteChannel <- callMessageRequest.UniqueID
+	log.Info_Log("[%s] Charger is added to the registry", settings.Name)
+	writeCharger(chargerObj, http.StatusCreated, log, w)
+}
 
-	// Send response in json format
+/****************************************************************************************
+ *
+ * Function : UpdateChargerAPI
+ *
+ *  Purpose : Change settings of the charger in the registry. Current connection is kept,
+ *			  new AuthToken and ping interval are used from the next connection.
+ *			  Fields omitted in the body keep current settings of the charger
+ *
+ *    Input : chargerName string - charger name
+ *            registry ChargerRegistry - registry of the chargers
+ *            log *logging.Log - pointer to the log
+ *            r *http.Request - http request object
+ *            w http.ResponseWriter - http response
+ *
+ *   Return : Nothing
+ */
+func UpdateChargerAPI(chargerName string, registry ChargerRegistry, log *logging.Log, r *http.Request, w http.ResponseWriter) {
+	log.Info_Log("UpdateChargerAPI")
+
+	chargerObj, err := registry.Get(chargerName)
+	if err != nil {
+		log.Error_Log("[%s] Charger is not found", chargerName)
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	// Body is merged to the current settings, AuthToken is not cleared by mistake
+	settings, ok := decodeChargerSettings(r, chargerObj.Settings(), log, w)
+	if !ok {
+		return
+	}
+
+	// Charger cannot be renamed, name of the body is optional
+	if settings.Name != chargerName {
+		log.Error_Log("[%s] Name in the body '%v' does not match the charger", chargerName, settings.Name)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	chargerObj, err = registry.Update(settings)
+	if err == ErrChargerNotFound {
+		log.Error_Log("[%s] Charger is not found", chargerName)
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		log.Error_Log("[%s] Cannot update charger with error '%v'", chargerName, err)
+		return
+	}
+
+	log.Info_Log("[%s] Charger is updated", chargerName)
+	writeCharger(chargerObj, http.StatusOK, log, w)
+}
+
+/****************************************************************************************
+ *
+ * Function : DeleteChargerAPI
+ *
+ *  Purpose : Remove charger from the registry and close its connection
+ *
+ *    Input : chargerName string - charger name
+ *            registry ChargerRegistry - registry of the chargers
+ *            log *logging.Log - pointer to the log
+ *            w http.ResponseWriter - http response
+ *
+ *   Return : Nothing
+ */
+func DeleteChargerAPI(chargerName string, registry ChargerRegistry, log *logging.Log, w http.ResponseWriter) {
+	log.Info_Log("DeleteChargerAPI")
+
+	chargerObj, err := registry.Delete(chargerName)
+	if err == ErrChargerNotFound {
+		log.Error_Log("[%s] Charger is not found", chargerName)
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		log.Error_Log("[%s] Cannot delete charger with error '%v'", chargerName, err)
+		return
+	}
+
+	// Removed charger cannot connect again, current connection is closed
+	if chargerObj.CloseConnection("Charger is removed") {
+		log.Info_Log("[%s] Connection of the removed charger is closed", chargerName)
+	}
+
+	log.Info_Log("[%s] Charger is removed from the registry", chargerName)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	w.Write(CreateSuccessResponse(callMessageRequest.UniqueID))
+	w.Write(CreateSuccessResponse(chargerName))
+}
+
+/****************************************************************************************
+ *	Struct 	: AuthTokenRequest
+ *
+ * 	Purpose : Object handles body of the AuthToken rotation request
+ *
+*****************************************************************************************/
+type AuthTokenRequest struct {
+	Password string `json:"password"` // New password of the charger, generated when empty
+}
+
+/****************************************************************************************
+ *	Struct 	: AuthTokenResponse
+ *
+ * 	Purpose : Object handles response of the AuthToken rotation. Password is not stored
+ *			  by the server and is shown only once
+ *
+*****************************************************************************************/
+type AuthTokenResponse struct {
+	Status   string `json:"status"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+/****************************************************************************************
+ *
+ * Function : RotateAuthTokenAPI
+ *
+ *  Purpose : Set new password of the charger, only hash is kept in the registry.
+ *			  Current connection is kept, charger uses new password on the next connection
+ *
+ *    Input : chargerName string - charger name
+ *            registry ChargerRegistry - registry of the chargers
+ *            log *logging.Log - pointer to the log
+ *            r *http.Request - http request object
+ *            w http.ResponseWriter - http response
+ *
+ *   Return : Nothing
+ */
+func RotateAuthTokenAPI(chargerName string, registry ChargerRegistry, log *logging.Log, r *http.Request, w http.ResponseWriter) {
+	log.Info_Log("RotateAuthTokenAPI")
+
+	if _, err := registry.Get(chargerName); err != nil {
+		log.Error_Log("[%s] Charger is not found", chargerName)
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+
+	// Empty body is allowed, password is generated by the server
+	request := AuthTokenRequest{}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil && err != io.EOF {
+		log.Error_Log("[%s] Cannot decode request with error '%v'", chargerName, err)
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	if request.Password == "" {
+		password, err := generateAuthorizationKey()
+		if err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			log.Error_Log("[%s] Cannot generate password with error '%v'", chargerName, err)
+			return
+		}
+		request.Password = password
+	}
+
+	authToken, err := core.HashAuthorizationKey(request.Password)
+	if err != nil {
+		log.Error_Log("[%s] Password is not valid with error '%v'", chargerName, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(CreateFailResponse(err.Error())))
+		return
+	}
+
+	// Other settings are not overwritten by the concurrent update of the charger
+	if _, err := registry.SetAuthorization(chargerName, authToken); err == ErrChargerNotFound {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		log.Error_Log("[%s] Charger is removed before AuthToken is rotated", chargerName)
+		return
+	} else if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		log.Error_Log("[%s] Cannot update AuthToken with error '%v'", chargerName, err)
+		return
+	}
+
+	log.Info_Log("[%s] AuthToken is rotated", chargerName)
+	jsonResult, _ := json.Marshal(AuthTokenResponse{Status: "success", Name: chargerName, Password: request.Password})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonResult)
+}
+
+/****************************************************************************************
+ *
+ * Function : decodeChargerSettings
+ *
+ *  Purpose : Decode and validate charger settings from the body of the request,
+ *			  client gets error response when settings are not valid
+ *
+ *    Input : r *http.Request - http request object
+ *            settings ChargerFromFile - values of the fields omitted in the body
+ *            log *logging.Log - pointer to the log
+ *            w http.ResponseWriter - http response
+ *
+ *   Return : ChargerFromFile - settings of the charger
+ *			  bool - false when response with error is sent
+ */
+func decodeChargerSettings(r *http.Request, settings ChargerFromFile, log *logging.Log, w http.ResponseWriter) (ChargerFromFile, bool) {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&settings); err != nil {
+		log.Error_Log("Cannot decode charger with error '%v'", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(CreateFailResponse(err.Error())))
+		return settings, false
+	}
+
+	if err := settings.Validate(); err != nil {
+		log.Error_Log("[%s] Charger is not valid with error '%v'", settings.Name, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(CreateFailResponse(err.Error())))
+		return settings, false
+	}
+
+	return settings, true
+}
+
+/****************************************************************************************
+ *
+ * Function : writeCharger
+ *
+ *  Purpose : Send status of the charger to the client by http
+ *
+ *    Input : chargerObj *Charger - charger to send
+ *            statusCode int - http status code of the response
+ *            log *logging.Log - pointer to the log
+ *            w http.ResponseWriter - http response
+ *
+ *   Return : Nothing
+ */
+func writeCharger(chargerObj *Charger, statusCode int, log *logging.Log, w http.ResponseWriter) {
+	jsonResult, err := json.Marshal(chargerObj)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		log.Error_Log("[%s] Cannot marshal charger obj", chargerObj.Name)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(jsonResult)
+}
+
+/****************************************************************************************
+ *
+ * Function : generateAuthorizationKey
+ *
+ *  Purpose : Generate random password of the charger in hex format
+ *
+ *    Input : Nothing
+ *
+ *   Return : string - password of the AUTHORIZATION_KEY_MAX_LENGTH length
+ *			  error - if random bytes cannot be read, nil otherwise
+ */
+func generateAuthorizationKey() (string, error) {
+	keyBytes := make([]byte, core.AUTHORIZATION_KEY_MAX_LENGTH/2)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(keyBytes), nil
 }
 
 /****************************************************************************************
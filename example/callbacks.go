@@ -29,11 +29,21 @@ const (
  *
 *****************************************************************************************/
 type OCPPHandlers struct {
-	Charger *Charger            // Charger struct which connected to the server
-	Log     logging.Log         // Pointer to the log
-	MQueue  *SimpleMessageQueue // For example queue will be here
+	core.CentralSystemHandlersBase // NotImplemented defaults for the actions are not handled below
+
+	Charger      *Charger              // Charger struct which connected to the server
+	Log          logging.Log           // Pointer to the log
+	MQueue       MessageQueue          // For example queue will be here
+	Transactions core.TransactionStore // Store of the charging transactions
+	MeterValues  core.MeterValueStore  // Store of the meter readings
+	Authorizer   core.IdTagAuthorizer  // Backend to authorize identifiers
+	// Charger without AuthToken is connected without credentials, otherwise rejected
+	AllowUnauthenticated bool
 }
 
+// Make sure that OCPPHandlers implements all handlers
+var _ core.CentralSystemHandlers = (*OCPPHandlers)(nil)
+
 /****************************************************************************************
  *
  * Function : OCPPHandlersConstructor (Constructor)
@@ -73,10 +83,14 @@ func (cs *OCPPHandlers) finaliseReqHandler(callMessage messages.CallMessage, res
 	}
 
 	// Update message's action and sending content in the queue
-	qMessage, _ := cs.MQueue.GetMessage(callMessage.UniqueID)
+	qMessage, exists := cs.MQueue.Get(cs.Charger.Name, callMessage.UniqueID)
+	if !exists {
+		cs.Log.Error_Log("[%v] Message is not in the queue, response is not stored", callMessage.UniqueID)
+		return messageStr, nil, socketStatus
+	}
 	qMessage.Action = callMessage.Action
 	qMessage.Sent = messageStr
-	cs.MQueue.UpdateByUniqueID(callMessage.UniqueID, qMessage)
+	cs.MQueue.Update(cs.Charger.Name, callMessage.UniqueID, qMessage)
 
 	return messageStr, nil, socketStatus
 }
@@ -97,11 +111,14 @@ func (cs *OCPPHandlers) finaliseReqHandler(callMessage messages.CallMessage, res
  */
 func (cs *OCPPHandlers) finaliseRespHandler(uniqueID string, socketStatus bool) (error, bool) {
 	// Get message from the queue
-	qMessage, _ := cs.MQueue.GetMessage(uniqueID)
+	qMessage, exists := cs.MQueue.Get(cs.Charger.Name, uniqueID)
+	if !exists {
+		return nil, socketStatus
+	}
 	// update status of the current message
 	qMessage.Status = MESSAGE_TYPE_COMPLETED
 	// Update message in the queue
-	return cs.MQueue.UpdateByUniqueID(uniqueID, qMessage), socketStatus
+	return cs.MQueue.Update(cs.Charger.Name, uniqueID, qMessage), socketStatus
 }
 
 /****************************************************************************************
@@ -118,7 +135,7 @@ func (cs *OCPPHandlers) finaliseRespHandler(uniqueID string, socketStatus bool)
 func (cs *OCPPHandlers) GetActionHandler(uniqueID string) string {
 
 	// Get action from tx queue by UniqueID
-	if message, success := cs.MQueue.GetMessage(uniqueID); success {
+	if message, success := cs.MQueue.Get(cs.Charger.Name, uniqueID); success {
 		// Message exists - return action
 		return message.Action
 	}
@@ -131,7 +148,13 @@ func (cs *OCPPHandlers) GetActionHandler(uniqueID string) string {
  *
  * Function : OCPPHandlers::Authorisation
  *
- * Purpose : Using to Authorise charger before allow websocket connection
+ * Purpose : Using to Authorise charger before allow websocket connection.
+ *			 Charger with verified client certificate is authorised when common name
+ *			 of the certificate is the charger name (Security Profile 3).
+ *			 Otherwise charger sends "Authorization: Basic" header with chargerName:password,
+ *			 password is checked against the hash in AuthToken (Security Profiles 1 and 2).
+ *			 Charger without AuthToken is not authenticated when AllowUnauthenticated
+ *			 is set (Security Profile 0), otherwise it is rejected
  *
  *   Input : chargerName string - charger name to be validated
  *           request *http.Request - http request object
@@ -142,12 +165,46 @@ func (cs *OCPPHandlers) GetActionHandler(uniqueID string) string {
 func (cs *OCPPHandlers) Authorisation(chargerName string, request *http.Request) bool {
 
 	cs.Log.Info_Log("[%v] Auth request from URL '%s'", chargerName, request.RequestURI)
-	cs.Log.Info_Log("[%v] Auth header in request is '%s'", chargerName, request.Header["Authorisation"])
 
-	// Here you can add your own authorisation process ....
+	if certificateName, isVerified := core.ClientCertificateName(request); isVerified {
+		if certificateName != chargerName {
+			cs.Log.Error_Log("[%v] Client certificate is issued for '%v'", chargerName, certificateName)
+			return false
+		}
+
+		cs.Log.Info_Log("[%v] Charger is authorised by client certificate", chargerName)
+		return true
+	}
+
+	// AuthToken can be rotated at runtime by the registry
+	authToken := cs.Charger.Settings().Authorization
+	if authToken == "" {
+		if !cs.AllowUnauthenticated {
+			cs.Log.Error_Log("[%v] Charger has no AuthToken, unauthenticated connections are not allowed", chargerName)
+			return false
+		}
+		cs.Log.Info_Log("[%v] Charger has no AuthToken, connection is not authenticated", chargerName)
+		return true
+	}
+
+	username, password, isBasicAuth := request.BasicAuth()
+	if !isBasicAuth {
+		cs.Log.Error_Log("[%v] Request has no Basic authorization header", chargerName)
+		return false
+	}
 
-	cs.Log.Info_Log("[%v] Authorisation is '%v'", chargerName, cs.Charger.AuthConnection)
-	// Right now we will authorise request
+	// Username must be the identity of the charger from the URL
+	if username != chargerName {
+		cs.Log.Error_Log("[%v] Username '%v' does not match the charger name", chargerName, username)
+		return false
+	}
+
+	if !core.VerifyAuthorizationKey(authToken, password) {
+		cs.Log.Error_Log("[%v] Password does not match AuthToken", chargerName)
+		return false
+	}
+
+	cs.Log.Info_Log("[%v] Charger is authorised", chargerName)
 	return true
 }
 
@@ -173,11 +230,15 @@ func (cs *OCPPHandlers) BootNotificationRequestHandler(callMessage messages.Call
 
 	// Define registration status for the response
 	status := core.RegistrationStatusPending
-	if cs.Charger.AuthConnection {
+	if cs.Charger.IsAuthorised() {
 		status = core.RegistrationStatusAccepted
 	}
 	// Create default payload with pointed status
 	bootNotificationRespPayload := core.CreateBootNotificationResponsePayload(status)
+	// Charger is considered offline when heartbeats stop arriving in this interval
+	if heartBeatInterval := cs.Charger.Settings().HeartBeatInterval; heartBeatInterval > 0 {
+		bootNotificationRespPayload.HeartbeatInterval = heartBeatInterval
+	}
 	// Create CallResult message
 	bootNotificationResp := messages.CreateCallResultMessage(
 		callMessage.UniqueID,
@@ -203,15 +264,27 @@ func (cs *OCPPHandlers) BootNotificationRequestHandler(callMessage messages.Call
 func (cs *OCPPHandlers) AuthorizeRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
 	cs.Log.Info_Log("[%v] AuthorizeRequest Action", callMessage.UniqueID)
 
-	// Check Auth flag
-	if cs.Charger.AuthConnection == false {
-		// Charger is not authorised
-		callErrMess := messages.CallErrorMessageConstructor()
-		messageStr, err := callErrMess.ToString()
-		return messageStr, err, WEBSOCKET_KEEP_OPEN
+	// Decode payload of the request
+	authorizeRequest := core.AuthorizeRequestPayload{}
+	if err := core.UnmarshalPayload(callMessage.Payload, &authorizeRequest); err != nil {
+		return "", err, WEBSOCKET_KEEP_OPEN
+	}
+
+	// Identifiers are not accepted from the charger which is not authorised
+	idTagInfo := core.IdTagInfo{Status: core.AuthorizationStatusInvalid}
+	if cs.Charger.IsAuthorised() {
+		idTagInfo = cs.Authorizer.Authorize(authorizeRequest.IdTag)
 	}
-	// Create ErrorResult message
-	callMessageResponse := messages.CallErrorMessage{}
+	cs.Log.Info_Log("[%v] IdTag '%v' authorization status is '%v'", callMessage.UniqueID, authorizeRequest.IdTag, idTagInfo.Status)
+
+	// Create payload of the response
+	payload, err := core.MarshalPayload(core.AuthorizeResponsePayload{IdTagInfo: idTagInfo})
+	if err != nil {
+		return "", err, WEBSOCKET_KEEP_OPEN
+	}
+
+	// Create CallResultMessage
+	callMessageResponse := messages.CreateCallResultMessage(callMessage.UniqueID, payload)
 
 	return cs.finaliseReqHandler(callMessage, &callMessageResponse, WEBSOCKET_KEEP_OPEN)
 }
@@ -245,15 +318,102 @@ func (cs *OCPPHandlers) HeartbeatRequestHandler(callMessage messages.CallMessage
 	return cs.finaliseReqHandler(callMessage, &callMessageResponse, WEBSOCKET_KEEP_OPEN)
 }
 
-/* Define Response Handlers ===================================================================================
-===============================================================================================================
-*/
+/****************************************************************************************
+ *
+ * Function : OCPPHandlers::MeterValuesRequestHandler
+ *
+ *  Purpose : Handle MeterValuesRequest
+ *
+ *    Input : callMessage messages.CallMessage - original Call message
+ *
+ *   Return : string - response message in string format
+ *			  error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ *
+ */
+func (cs *OCPPHandlers) MeterValuesRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	cs.Log.Info_Log("[%v] MeterValuesRequest Action", callMessage.UniqueID)
+
+	// Decode payload of the request
+	meterValuesRequest := core.MeterValuesRequestPayload{}
+	if err := core.UnmarshalPayload(callMessage.Payload, &meterValuesRequest); err != nil {
+		return "", err, WEBSOCKET_KEEP_OPEN
+	}
+
+	// Store readings, values which cannot be parsed are skipped only
+	err := cs.MeterValues.AddMeterValues(cs.Charger.Name, meterValuesRequest.ConnectorId, meterValuesRequest.TransactionId, meterValuesRequest.MeterValue)
+	if err != nil {
+		cs.Log.Error_Log("[%v] Some of the meter values are skipped with error '%v'", callMessage.UniqueID, err)
+	}
+
+	// Create CallResultMessage with empty payload
+	callMessageResponse := messages.CreateCallResultMessage(callMessage.UniqueID, make(map[string]interface{}))
+
+	return cs.finaliseReqHandler(callMessage, &callMessageResponse, WEBSOCKET_KEEP_OPEN)
+}
+
+/****************************************************************************************
+ *
+ * Function : OCPPHandlers::StartTransactionRequestHandler
+ *
+ *  Purpose : Handle StartTransactionRequest
+ *
+ *    Input : callMessage messages.CallMessage - original Call message
+ *
+ *   Return : string - response message in string format
+ *			  error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ *
+ */
+func (cs *OCPPHandlers) StartTransactionRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	cs.Log.Info_Log("[%v] StartTransactionRequest Action", callMessage.UniqueID)
+
+	// Decode payload of the request
+	startTransactionRequest := core.StartTransactionRequestPayload{}
+	if err := core.UnmarshalPayload(callMessage.Payload, &startTransactionRequest); err != nil {
+		return "", err, WEBSOCKET_KEEP_OPEN
+	}
+
+	// Authorize identifier, only one transaction is allowed per identifier.
+	// Identifiers are not accepted from the charger which is not authorised
+	idTagInfo := core.IdTagInfo{Status: core.AuthorizationStatusInvalid}
+	if cs.Charger.IsAuthorised() {
+		idTagInfo = cs.Authorizer.Authorize(startTransactionRequest.IdTag)
+	}
+	if idTagInfo.Status == core.AuthorizationStatusAccepted {
+		if active, exists := cs.Transactions.GetActiveTransactionByIdTag(startTransactionRequest.IdTag); exists {
+			cs.Log.Info_Log("[%v] IdTag '%v' is involved in transaction '%v' already", callMessage.UniqueID, startTransactionRequest.IdTag, active.TransactionId)
+			idTagInfo.Status = core.AuthorizationStatusConcurrentTx
+		}
+	}
+
+	// Transaction is stored even if identifier is not accepted, charger will stop it
+	transaction, err := cs.Transactions.StartTransaction(cs.Charger.Name, startTransactionRequest)
+	if err != nil {
+		return "", err, WEBSOCKET_KEEP_OPEN
+	}
+	cs.Log.Info_Log("[%v] Transaction '%v' is started on connector '%v' by idTag '%v' with status '%v'", callMessage.UniqueID, transaction.TransactionId, transaction.ConnectorId, transaction.IdTag, idTagInfo.Status)
+
+	// Create payload of the response
+	payload, err := core.MarshalPayload(core.StartTransactionResponsePayload{
+		IdTagInfo:     idTagInfo,
+		TransactionId: transaction.TransactionId,
+	})
+	if err != nil {
+		return "", err, WEBSOCKET_KEEP_OPEN
+	}
+
+	// Create CallResultMessage
+	callMessageResponse := messages.CreateCallResultMessage(callMessage.UniqueID, payload)
+
+	return cs.finaliseReqHandler(callMessage, &callMessageResponse, WEBSOCKET_KEEP_OPEN)
+}
 
 /****************************************************************************************
  *
- * Function : OCPPHandlers::AuthorizeResponseHandler
+ * Function : OCPPHandlers::StatusNotificationRequestHandler
  *
- *  Purpose : Handle AuthorizeResponse
+ *  Purpose : Handle StatusNotificationRequest
  *
  *    Input : callMessage messages.CallMessage - original Call message
  *
@@ -262,10 +422,332 @@ func (cs *OCPPHandlers) HeartbeatRequestHandler(callMessage messages.CallMessage
  *			  bool - false - when connection to charger needs to be closed, otherwise true
  *
  */
-func (cs *OCPPHandlers) AuthorizeResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
-	cs.Log.Info_Log("[%v] AuthorizeResponse Action", callResultMessage.UniqueID)
+func (cs *OCPPHandlers) StatusNotificationRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	cs.Log.Info_Log("[%v] StatusNotificationRequest Action", callMessage.UniqueID)
+
+	// Decode payload of the request
+	statusNotificationRequest := core.StatusNotificationRequestPayload{}
+	if err := core.UnmarshalPayload(callMessage.Payload, &statusNotificationRequest); err != nil {
+		return "", err, WEBSOCKET_KEEP_OPEN
+	}
 
-	// Perform Auth Routine
+	cs.Log.Info_Log("[%v] Connector '%v' reported status '%v' with error code '%v'", callMessage.UniqueID, statusNotificationRequest.ConnectorId, statusNotificationRequest.Status, statusNotificationRequest.ErrorCode)
+
+	// Update connector state, reported status is stored even if transition is not permitted
+	if err := cs.Charger.Connectors.Update(statusNotificationRequest); err != nil {
+		cs.Log.Error_Log("[%v] Connector state warning: '%v'", callMessage.UniqueID, err)
+	}
+
+	// Create CallResultMessage with empty payload
+	callMessageResponse := messages.CreateCallResultMessage(callMessage.UniqueID, make(map[string]interface{}))
+
+	return cs.finaliseReqHandler(callMessage, &callMessageResponse, WEBSOCKET_KEEP_OPEN)
+}
+
+/****************************************************************************************
+ *
+ * Function : OCPPHandlers::StopTransactionRequestHandler
+ *
+ *  Purpose : Handle StopTransactionRequest
+ *
+ *    Input : callMessage messages.CallMessage - original Call message
+ *
+ *   Return : string - response message in string format
+ *			  error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ *
+ */
+func (cs *OCPPHandlers) StopTransactionRequestHandler(callMessage messages.CallMessage) (string, error, bool) {
+	cs.Log.Info_Log("[%v] StopTransactionRequest Action", callMessage.UniqueID)
+
+	// Decode payload of the request
+	stopTransactionRequest := core.StopTransactionRequestPayload{}
+	if err := core.UnmarshalPayload(callMessage.Payload, &stopTransactionRequest); err != nil {
+		return "", err, WEBSOCKET_KEEP_OPEN
+	}
+
+	// Charger expects response even if transaction is unknown, so only log the error
+	transaction, err := cs.Transactions.StopTransaction(cs.Charger.Name, stopTransactionRequest)
+	if err != nil {
+		cs.Log.Error_Log("[%v] Cannot stop transaction '%v' with error '%v'", callMessage.UniqueID, stopTransactionRequest.TransactionId, err)
+	} else {
+		cs.Log.Info_Log("[%v] Transaction '%v' is stopped with reason '%v', consumed %v Wh", callMessage.UniqueID, transaction.TransactionId, transaction.StopReason, transaction.MeterStop-transaction.MeterStart)
+	}
+
+	// Store meter values of the transaction, connector is not known without the transaction
+	if len(stopTransactionRequest.TransactionData) > 0 && err != nil {
+		cs.Log.Error_Log("[%v] Transaction data of the unknown transaction '%v' are orphaned: '%+v'", callMessage.UniqueID, stopTransactionRequest.TransactionId, stopTransactionRequest.TransactionData)
+	} else if len(stopTransactionRequest.TransactionData) > 0 {
+		if err := cs.MeterValues.AddMeterValues(cs.Charger.Name, transaction.ConnectorId, stopTransactionRequest.TransactionId, stopTransactionRequest.TransactionData); err != nil {
+			cs.Log.Error_Log("[%v] Some of the transaction data are skipped with error '%v'", callMessage.UniqueID, err)
+		}
+	}
+
+	// IdTagInfo is required only when idTag is present in the request
+	stopTransactionResponse := core.StopTransactionResponsePayload{}
+	if stopTransactionRequest.IdTag != "" {
+		idTagInfo := core.IdTagInfo{Status: core.AuthorizationStatusInvalid}
+		if cs.Charger.IsAuthorised() {
+			idTagInfo = cs.Authorizer.Authorize(stopTransactionRequest.IdTag)
+		}
+		stopTransactionResponse.IdTagInfo = &idTagInfo
+	}
+
+	// Create payload of the response
+	payload, err := core.MarshalPayload(stopTransactionResponse)
+	if err != nil {
+		return "", err, WEBSOCKET_KEEP_OPEN
+	}
+
+	// Create CallResultMessage
+	callMessageResponse := messages.CreateCallResultMessage(callMessage.UniqueID, payload)
+
+	return cs.finaliseReqHandler(callMessage, &callMessageResponse, WEBSOCKET_KEEP_OPEN)
+}
+
+/* Define Response Handlers ===================================================================================
+===============================================================================================================
+*/
+
+/****************************************************************************************
+ *
+ * Function : OCPPHandlers::ChangeAvailabilityResponseHandler
+ *
+ *  Purpose : Handle ChangeAvailabilityResponse
+ *
+ *    Input : callResultMessage messages.CallResultMessage - income CallResult message
+ *
+ *   Return : error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ *
+ */
+func (cs *OCPPHandlers) ChangeAvailabilityResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	cs.Log.Info_Log("[%v] ChangeAvailabilityResponse Action", callResultMessage.UniqueID)
+
+	// Decode payload of the response
+	changeAvailabilityResponse := core.ChangeAvailabilityResponsePayload{}
+	if err := core.UnmarshalPayload(callResultMessage.Payload, &changeAvailabilityResponse); err != nil {
+		return err, WEBSOCKET_KEEP_OPEN
+	}
+	cs.Log.Info_Log("[%v] ChangeAvailability status is '%v'", callResultMessage.UniqueID, changeAvailabilityResponse.Status)
+
+	return cs.finaliseRespHandler(callResultMessage.UniqueID, WEBSOCKET_KEEP_OPEN)
+}
+
+/****************************************************************************************
+ *
+ * Function : OCPPHandlers::ChangeConfigurationResponseHandler
+ *
+ *  Purpose : Handle ChangeConfigurationResponse
+ *
+ *    Input : callResultMessage messages.CallResultMessage - income CallResult message
+ *
+ *   Return : error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ *
+ */
+func (cs *OCPPHandlers) ChangeConfigurationResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	cs.Log.Info_Log("[%v] ChangeConfigurationResponse Action", callResultMessage.UniqueID)
+
+	// Decode payload of the response
+	changeConfigurationResponse := core.ChangeConfigurationResponsePayload{}
+	if err := core.UnmarshalPayload(callResultMessage.Payload, &changeConfigurationResponse); err != nil {
+		return err, WEBSOCKET_KEEP_OPEN
+	}
+	cs.Log.Info_Log("[%v] ChangeConfiguration status is '%v'", callResultMessage.UniqueID, changeConfigurationResponse.Status)
+
+	return cs.finaliseRespHandler(callResultMessage.UniqueID, WEBSOCKET_KEEP_OPEN)
+}
+
+/****************************************************************************************
+ *
+ * Function : OCPPHandlers::ClearCacheResponseHandler
+ *
+ *  Purpose : Handle ClearCacheResponse
+ *
+ *    Input : callResultMessage messages.CallResultMessage - income CallResult message
+ *
+ *   Return : error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ *
+ */
+func (cs *OCPPHandlers) ClearCacheResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	cs.Log.Info_Log("[%v] ClearCacheResponse Action", callResultMessage.UniqueID)
+
+	// Decode payload of the response
+	clearCacheResponse := core.ClearCacheResponsePayload{}
+	if err := core.UnmarshalPayload(callResultMessage.Payload, &clearCacheResponse); err != nil {
+		return err, WEBSOCKET_KEEP_OPEN
+	}
+	cs.Log.Info_Log("[%v] ClearCache status is '%v'", callResultMessage.UniqueID, clearCacheResponse.Status)
+
+	return cs.finaliseRespHandler(callResultMessage.UniqueID, WEBSOCKET_KEEP_OPEN)
+}
+
+/****************************************************************************************
+ *
+ * Function : OCPPHandlers::DataTransferResponseHandler
+ *
+ *  Purpose : Handle DataTransferResponse
+ *
+ *    Input : callResultMessage messages.CallResultMessage - income CallResult message
+ *
+ *   Return : error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ *
+ */
+func (cs *OCPPHandlers) DataTransferResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	cs.Log.Info_Log("[%v] DataTransferResponse Action", callResultMessage.UniqueID)
+
+	// Decode payload of the response
+	dataTransferResponse := core.DataTransferResponsePayload{}
+	if err := core.UnmarshalPayload(callResultMessage.Payload, &dataTransferResponse); err != nil {
+		return err, WEBSOCKET_KEEP_OPEN
+	}
+	cs.Log.Info_Log("[%v] DataTransfer status is '%v' with data '%v'", callResultMessage.UniqueID, dataTransferResponse.Status, dataTransferResponse.Data)
+
+	return cs.finaliseRespHandler(callResultMessage.UniqueID, WEBSOCKET_KEEP_OPEN)
+}
+
+/****************************************************************************************
+ *
+ * Function : OCPPHandlers::GetConfigurationResponseHandler
+ *
+ *  Purpose : Handle GetConfigurationResponse
+ *
+ *    Input : callResultMessage messages.CallResultMessage - income CallResult message
+ *
+ *   Return : error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ *
+ */
+func (cs *OCPPHandlers) GetConfigurationResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	cs.Log.Info_Log("[%v] GetConfigurationResponse Action", callResultMessage.UniqueID)
+
+	// Decode payload of the response
+	getConfigurationResponse := core.GetConfigurationResponsePayload{}
+	if err := core.UnmarshalPayload(callResultMessage.Payload, &getConfigurationResponse); err != nil {
+		return err, WEBSOCKET_KEEP_OPEN
+	}
+	cs.Log.Info_Log("[%v] GetConfiguration returned '%v' keys and '%v' unknown keys", callResultMessage.UniqueID, len(getConfigurationResponse.ConfigurationKey), len(getConfigurationResponse.UnknownKey))
+
+	return cs.finaliseRespHandler(callResultMessage.UniqueID, WEBSOCKET_KEEP_OPEN)
+}
+
+/****************************************************************************************
+ *
+ * Function : OCPPHandlers::RemoteStartTransactionResponseHandler
+ *
+ *  Purpose : Handle RemoteStartTransactionResponse
+ *
+ *    Input : callResultMessage messages.CallResultMessage - income CallResult message
+ *
+ *   Return : error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ *
+ */
+func (cs *OCPPHandlers) RemoteStartTransactionResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	cs.Log.Info_Log("[%v] RemoteStartTransactionResponse Action", callResultMessage.UniqueID)
+
+	// Decode payload of the response
+	remoteStartTransactionResponse := core.RemoteStartTransactionResponsePayload{}
+	if err := core.UnmarshalPayload(callResultMessage.Payload, &remoteStartTransactionResponse); err != nil {
+		return err, WEBSOCKET_KEEP_OPEN
+	}
+	cs.Log.Info_Log("[%v] RemoteStartTransaction status is '%v'", callResultMessage.UniqueID, remoteStartTransactionResponse.Status)
+
+	return cs.finaliseRespHandler(callResultMessage.UniqueID, WEBSOCKET_KEEP_OPEN)
+}
+
+/****************************************************************************************
+ *
+ * Function : OCPPHandlers::RemoteStopTransactionResponseHandler
+ *
+ *  Purpose : Handle RemoteStopTransactionResponse
+ *
+ *    Input : callResultMessage messages.CallResultMessage - income CallResult message
+ *
+ *   Return : error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ *
+ */
+func (cs *OCPPHandlers) RemoteStopTransactionResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	cs.Log.Info_Log("[%v] RemoteStopTransactionResponse Action", callResultMessage.UniqueID)
+
+	// Decode payload of the response
+	remoteStopTransactionResponse := core.RemoteStopTransactionResponsePayload{}
+	if err := core.UnmarshalPayload(callResultMessage.Payload, &remoteStopTransactionResponse); err != nil {
+		return err, WEBSOCKET_KEEP_OPEN
+	}
+	cs.Log.Info_Log("[%v] RemoteStopTransaction status is '%v'", callResultMessage.UniqueID, remoteStopTransactionResponse.Status)
+
+	return cs.finaliseRespHandler(callResultMessage.UniqueID, WEBSOCKET_KEEP_OPEN)
+}
+
+/****************************************************************************************
+ *
+ * Function : OCPPHandlers::ResetResponseHandler
+ *
+ *  Purpose : Handle ResetResponse
+ *
+ *    Input : callResultMessage messages.CallResultMessage - income CallResult message
+ *
+ *   Return : error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ *
+ */
+func (cs *OCPPHandlers) ResetResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	cs.Log.Info_Log("[%v] ResetResponse Action", callResultMessage.UniqueID)
+
+	// Decode payload of the response
+	resetResponse := core.ResetResponsePayload{}
+	if err := core.UnmarshalPayload(callResultMessage.Payload, &resetResponse); err != nil {
+		return err, WEBSOCKET_KEEP_OPEN
+	}
+	cs.Log.Info_Log("[%v] Reset status is '%v'", callResultMessage.UniqueID, resetResponse.Status)
+
+	return cs.finaliseRespHandler(callResultMessage.UniqueID, WEBSOCKET_KEEP_OPEN)
+}
+
+/****************************************************************************************
+ *
+ * Function : OCPPHandlers::UnlockConnectorResponseHandler
+ *
+ *  Purpose : Handle UnlockConnectorResponse
+ *
+ *    Input : callResultMessage messages.CallResultMessage - income CallResult message
+ *
+ *   Return : error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ *
+ */
+func (cs *OCPPHandlers) UnlockConnectorResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	cs.Log.Info_Log("[%v] UnlockConnectorResponse Action", callResultMessage.UniqueID)
+
+	// Decode payload of the response
+	unlockConnectorResponse := core.UnlockConnectorResponsePayload{}
+	if err := core.UnmarshalPayload(callResultMessage.Payload, &unlockConnectorResponse); err != nil {
+		return err, WEBSOCKET_KEEP_OPEN
+	}
+	cs.Log.Info_Log("[%v] UnlockConnector status is '%v'", callResultMessage.UniqueID, unlockConnectorResponse.Status)
+
+	return cs.finaliseRespHandler(callResultMessage.UniqueID, WEBSOCKET_KEEP_OPEN)
+}
+
+/****************************************************************************************
+ *
+ * Function : OCPPHandlers::TriggerMessageResponseHandler
+ *
+ *  Purpose : Handle TriggerMessageResponse
+ *
+ *    Input : callResultMessage messages.CallResultMessage - income CallResult message
+ *
+ *   Return : error - if happened, nil otherwise
+ *			  bool - false - when connection to charger needs to be closed, otherwise true
+ *
+ */
+func (cs *OCPPHandlers) TriggerMessageResponseHandler(callResultMessage messages.CallResultMessage) (error, bool) {
+	cs.Log.Info_Log("[%v] TriggerMessageResponse Action", callResultMessage.UniqueID)
 
 	return cs.finaliseRespHandler(callResultMessage.UniqueID, WEBSOCKET_KEEP_OPEN)
 }
@@ -290,7 +772,12 @@ func (cs *OCPPHandlers) AuthorizeResponseHandler(callResultMessage messages.Call
 func (cs *OCPPHandlers) OCPPErrorHandler(callErrortMessage messages.CallErrorMessage) (error, bool) {
 	cs.Log.Info_Log("[%v] OCPPErrorHandler", callErrortMessage.UniqueID)
 
-	// Handle OCPP error
+	// Call is answered with error
+	qMessage, exists := cs.MQueue.Get(cs.Charger.Name, callErrortMessage.UniqueID)
+	if !exists {
+		return nil, WEBSOCKET_KEEP_OPEN
+	}
+	qMessage.MarkError(callErrortMessage.Error())
 
-	return cs.finaliseRespHandler(callErrortMessage.UniqueID, WEBSOCKET_KEEP_OPEN)
+	return cs.MQueue.Update(cs.Charger.Name, callErrortMessage.UniqueID, qMessage), WEBSOCKET_KEEP_OPEN
 }
@@ -0,0 +1,164 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: callbacks_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: File with test cases for the handlers of the charger messages
+	=============================================================================
+*/
+
+package example
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/CoderSergiy/golib/logging"
+	"github.com/CoderSergiy/ocpp16-go/core"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+/****************************************************************************************
+ *
+ * Function : testOCPPHandlers
+ *
+ *  Purpose : Create handlers of the charger with in-memory stores and identifiers
+ *			  from idtags.json
+ *
+ *    Input : t *testing.T - test object
+ *
+ *   Return : *OCPPHandlers
+ */
+func testOCPPHandlers(t *testing.T) *OCPPHandlers {
+	authorizer, err := SetIdTagAuthorizerFromFile("idtags.json")
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot load identifiers with error '%v'", err))
+	}
+
+	charger := ChargerConstructor()
+	charger.Name = "CP001"
+
+	ocppHandlers := OCPPHandlersConstructor()
+	ocppHandlers.Log = logging.LogConstructor(filepath.Join(t.TempDir(), "handlers"), false)
+	ocppHandlers.MQueue = SimpleMessageQueueConstructor(QueueLimits{})
+	ocppHandlers.Charger = charger
+	ocppHandlers.Transactions = core.MemoryTransactionStoreConstructor()
+	ocppHandlers.MeterValues = core.MemoryMeterValueStoreConstructor()
+	ocppHandlers.Authorizer = authorizer
+	return &ocppHandlers
+}
+
+/****************************************************************************************
+ *
+ * Function : testCallMessage
+ *
+ *  Purpose : Create Call of the charger from the payload in JSON format
+ *
+ *   Return : messages.CallMessage
+ */
+func testCallMessage(t *testing.T, uniqueID string, action string, rawPayload string) messages.CallMessage {
+	payload := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(rawPayload), &payload); err != nil {
+		t.Fatal(fmt.Sprintf("Cannot parse payload with error '%v'", err))
+	}
+	return messages.CreateCallMessage(uniqueID, action, payload)
+}
+
+/****************************************************************************************
+ *
+ * Function : TestStopUnknownTransaction
+ *
+ *  Purpose : Test that transaction data of the unknown transaction are not stored
+ *			  for the whole charge point
+ *
+ *   Return : Nothing
+ */
+func TestStopUnknownTransaction(t *testing.T) {
+
+	ocppHandlers := testOCPPHandlers(t)
+
+	callMessage := testCallMessage(t, "1", core.ACTION_STOPTRANSACTION, `{"meterStop":100,"timestamp":"2024-01-01T00:00:00Z","transactionId":99,
+		"transactionData":[{"timestamp":"2024-01-01T00:00:00Z","sampledValue":[{"value":"100"}]}]}`)
+	if response, err, _ := ocppHandlers.StopTransactionRequestHandler(callMessage); err != nil || response == "" {
+		t.Error(fmt.Sprintf("Charger gets no response, error '%v'", err))
+	}
+
+	if readings := ocppHandlers.MeterValues.GetMeterReadings(core.MeterReadingFilter{}); len(readings) != 0 {
+		t.Error(fmt.Sprintf("Transaction data of the unknown transaction are stored '%+v'", readings))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestTransactionOfNotAuthorisedCharger
+ *
+ *  Purpose : Test that identifiers are not accepted in the transactions of the charger
+ *			  which is not authorised, as in Authorize request
+ *
+ *   Return : Nothing
+ */
+func TestTransactionOfNotAuthorisedCharger(t *testing.T) {
+
+	for _, authorised := range []bool{false, true} {
+		ocppHandlers := testOCPPHandlers(t)
+		ocppHandlers.Charger.authorised = authorised
+
+		expectedStatus := core.AuthorizationStatusInvalid
+		if authorised {
+			expectedStatus = core.AuthorizationStatusAccepted
+		}
+
+		// Start transaction with the accepted identifier
+		callMessage := testCallMessage(t, "1", core.ACTION_STARTTRANSACTION, `{"connectorId":1,"idTag":"TAG0001","meterStart":0,"timestamp":"2024-01-01T00:00:00Z"}`)
+		response, err, _ := ocppHandlers.StartTransactionRequestHandler(callMessage)
+		if err != nil {
+			t.Fatal(fmt.Sprintf("StartTransaction failed with error '%v'", err))
+		}
+		startTransactionResponse := core.StartTransactionResponsePayload{}
+		if err := core.UnmarshalPayload(messages.CallResultMessageCreator(response).Payload, &startTransactionResponse); err != nil {
+			t.Fatal(fmt.Sprintf("Cannot parse StartTransaction response '%v' with error '%v'", response, err))
+		}
+		if startTransactionResponse.IdTagInfo.Status != expectedStatus {
+			t.Error(fmt.Sprintf("Authorised '%v': StartTransaction status is '%v', expected '%v'", authorised, startTransactionResponse.IdTagInfo.Status, expectedStatus))
+		}
+
+		// Stop transaction with the same identifier
+		callMessage = testCallMessage(t, "2", core.ACTION_STOPTRANSACTION, fmt.Sprintf(`{"idTag":"TAG0001","meterStop":100,"timestamp":"2024-01-01T01:00:00Z","transactionId":%v}`, startTransactionResponse.TransactionId))
+		response, err, _ = ocppHandlers.StopTransactionRequestHandler(callMessage)
+		if err != nil {
+			t.Fatal(fmt.Sprintf("StopTransaction failed with error '%v'", err))
+		}
+		stopTransactionResponse := core.StopTransactionResponsePayload{}
+		if err := core.UnmarshalPayload(messages.CallResultMessageCreator(response).Payload, &stopTransactionResponse); err != nil {
+			t.Fatal(fmt.Sprintf("Cannot parse StopTransaction response '%v' with error '%v'", response, err))
+		}
+		if stopTransactionResponse.IdTagInfo == nil || stopTransactionResponse.IdTagInfo.Status != expectedStatus {
+			t.Error(fmt.Sprintf("Authorised '%v': StopTransaction IdTagInfo is '%+v', expected status '%v'", authorised, stopTransactionResponse.IdTagInfo, expectedStatus))
+		}
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestAuthorisationWithoutAuthToken
+ *
+ *  Purpose : Test that charger without AuthToken is connected without credentials
+ *			  only when it is allowed (Security Profile 0)
+ *
+ *   Return : Nothing
+ */
+func TestAuthorisationWithoutAuthToken(t *testing.T) {
+
+	for _, allowUnauthenticated := range []bool{false, true} {
+		ocppHandlers := testOCPPHandlers(t)
+		ocppHandlers.AllowUnauthenticated = allowUnauthenticated
+
+		request := httptest.NewRequest(http.MethodGet, "/ocpp16/CP001", nil)
+		if isAuthorised := ocppHandlers.Authorisation("CP001", request); isAuthorised != allowUnauthenticated {
+			t.Error(fmt.Sprintf("AllowUnauthenticated '%v': charger without AuthToken is authorised '%v'", allowUnauthenticated, isAuthorised))
+		}
+	}
+}
@@ -0,0 +1,240 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: central_system.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: Central System initiated Calls to the connected chargers.
+			 Call is stored in the queue and waits for the answer of the charger
+	=============================================================================
+*/
+
+package example
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+/****************************************************************************************
+ *	Struct 	: CentralSystem
+ *
+ * 	Purpose : Struct handles chargers and queue to send Calls to the chargers
+ *
+*****************************************************************************************/
+type CentralSystem struct {
+	Configs    *Configs        // Chargers of the server
+	MQueue     MessageQueue    // Queue of the messages
+	Context    context.Context // Parent of the Calls waiting in background, cancelled on server stop
+	background *sync.WaitGroup // Calls waiting in background
+}
+
+/****************************************************************************************
+ *
+ * Function : CentralSystemConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the CentralSystem
+ *
+ *	  Input : configs *Configs - pointer to the chargers configs
+ *			  mQueue MessageQueue - queue of the messages
+ *
+ *	Return : CentralSystem object
+ */
+func CentralSystemConstructor(configs *Configs, mQueue MessageQueue) CentralSystem {
+	centralSystem := CentralSystem{}
+	centralSystem.Configs = configs
+	centralSystem.MQueue = mQueue
+	centralSystem.Context = context.Background()
+	centralSystem.background = &sync.WaitGroup{}
+	return centralSystem
+}
+
+/****************************************************************************************
+ *
+ * Function : CentralSystem::SendCall
+ *
+ *  Purpose : Send Call to the charger and wait for the answer
+ *
+ *    Input : ctx context.Context - context to limit time of the Call
+ *			  chargerName string - name of the charger
+ *			  action string - action of the Call
+ *			  payload map[string]interface{} - payload of the Call
+ *
+ *   Return : messages.CallResultMessage - answer of the charger
+ *			  error - messages.CallErrorMessage when charger answered with CallError,
+ *					  core.ErrCallTimeout, core.ErrCallDisconnected or other error
+ */
+func (centralSystem *CentralSystem) SendCall(ctx context.Context, chargerName string, action string, payload map[string]interface{}) (messages.CallResultMessage, error) {
+	callMessage, err := centralSystem.QueueCall(chargerName, action, payload)
+	if err != nil {
+		return messages.CallResultMessage{}, err
+	}
+
+	return centralSystem.WaitCall(ctx, chargerName, callMessage)
+}
+
+/****************************************************************************************
+ *
+ * Function : CentralSystem::QueueCall
+ *
+ *  Purpose : Create Call with new uniqueID and store it in the queue.
+ *			  Call takes place in the outbound queue of the charger,
+ *			  use WaitCall to send it to the charger and release the place
+ *
+ *    Input : chargerName string - name of the charger
+ *			  action string - action of the Call
+ *			  payload map[string]interface{} - payload of the Call
+ *
+ *   Return : messages.CallMessage - created Call
+ *			  error - ErrOutboundQueueFull when charger has too many Calls, other error if happened
+ */
+func (centralSystem *CentralSystem) QueueCall(chargerName string, action string, payload map[string]interface{}) (messages.CallMessage, error) {
+	chargerObj, err := centralSystem.Configs.GetChargerObj(chargerName)
+	if err != nil {
+		return messages.CallMessage{}, err
+	}
+
+	// Call to the offline charger waits for the connection if it is allowed
+	if !chargerObj.IsConnected() && centralSystem.Configs.OfflineCallTimeout <= 0 {
+		return messages.CallMessage{}, errors.New(fmt.Sprintf("Charger '%v' is not connected", chargerName))
+	}
+
+	// Generate Call request to the charger
+	callMessage := messages.CreateCallMessage(uuid.New().String(), action, payload)
+
+	// Convert Call message to string
+	callMessageString, err := callMessage.ToString()
+	if err != nil {
+		return messages.CallMessage{}, err
+	}
+
+	// Back-pressure of the charger which does not answer fast enough
+	if err := chargerObj.Outbound.AcceptCall(callMessage.UniqueID); err != nil {
+		return messages.CallMessage{}, err
+	}
+
+	// Add message to the queue
+	queueMessage := Message{ChargerName: chargerName, Direction: MESSAGE_DIRECTION_OUTGOING, Action: action, Sent: callMessageString, Status: MESSAGE_TYPE_NEW, Received: ""}
+	if err := centralSystem.MQueue.Add(callMessage.UniqueID, queueMessage); err != nil {
+		chargerObj.Outbound.ReleaseCall(callMessage.UniqueID)
+		return messages.CallMessage{}, err
+	}
+
+	return callMessage, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : CentralSystem::WaitCall
+ *
+ *  Purpose : Send queued Call to the charger and wait for the answer.
+ *			  Call waits till previous Call to the charger is answered,
+ *			  Call to the offline charger is written when charger connects.
+ *			  Message in the queue is marked as error when Call is failed
+ *
+ *    Input : ctx context.Context - context to limit time of the Call
+ *			  chargerName string - name of the charger
+ *			  callMessage messages.CallMessage - Call created by QueueCall
+ *
+ *   Return : messages.CallResultMessage - answer of the charger
+ *			  error - if happened, nil otherwise
+ */
+func (centralSystem *CentralSystem) WaitCall(ctx context.Context, chargerName string, callMessage messages.CallMessage) (messages.CallResultMessage, error) {
+	chargerObj, err := centralSystem.Configs.GetChargerObj(chargerName)
+	if err != nil {
+		return messages.CallResultMessage{}, err
+	}
+
+	callResult, err := chargerObj.Calls.Call(ctx, callMessage, func(callMessageString string) error {
+		// Pass message to the write goroutine
+		chargerObj.Outbound.Push(OutboundMessage{UniqueID: callMessage.UniqueID, Message: callMessageString, Priority: OUTBOUND_PRIORITY_CALL})
+		return nil
+	})
+
+	// Call is not written anymore if it is still in the outbound queue
+	chargerObj.Outbound.ReleaseCall(callMessage.UniqueID)
+
+	// Charger answered with CallError, message is handled by OCPPErrorHandler
+	if _, isCallError := err.(messages.CallErrorMessage); err != nil && !isCallError {
+		if qMessage, exists := centralSystem.MQueue.Get(chargerName, callMessage.UniqueID); exists {
+			qMessage.MarkError(err.Error())
+			centralSystem.MQueue.Update(chargerName, callMessage.UniqueID, qMessage)
+		}
+	}
+
+	// Answer can be handled by the default handler which does not use the queue
+	if err == nil {
+		if qMessage, exists := centralSystem.MQueue.Get(chargerName, callMessage.UniqueID); exists && !qMessage.Status.IsFinished() {
+			qMessage.Status = MESSAGE_TYPE_COMPLETED
+			centralSystem.MQueue.Update(chargerName, callMessage.UniqueID, qMessage)
+		}
+	}
+
+	return callResult, err
+}
+
+/****************************************************************************************
+ *
+ * Function : CentralSystem::WaitCallInBackground
+ *
+ *  Purpose : Wait for the answer of the queued Call in the background goroutine.
+ *			  Call is cancelled with Context of the CentralSystem
+ *
+ *    Input : chargerName string - name of the charger
+ *			  callMessage messages.CallMessage - Call created by QueueCall
+ *			  timeout time.Duration - time to wait for the answer
+ *			  onError func(error) - called when Call is failed
+ *
+ *   Return : Nothing
+ */
+func (centralSystem *CentralSystem) WaitCallInBackground(chargerName string, callMessage messages.CallMessage, timeout time.Duration, onError func(error)) {
+	centralSystem.background.Add(1)
+	go func() {
+		defer centralSystem.background.Done()
+
+		ctx, cancel := context.WithTimeout(centralSystem.Context, timeout)
+		defer cancel()
+
+		if _, err := centralSystem.WaitCall(ctx, chargerName, callMessage); err != nil {
+			onError(err)
+		}
+	}()
+}
+
+/****************************************************************************************
+ *
+ * Function : CentralSystem::Wait
+ *
+ *  Purpose : Wait till Calls waiting in background are finished, as example
+ *			  after Context is cancelled and before the queue is closed
+ *
+ *    Input : Nothing
+ *
+ *   Return : Nothing
+ */
+func (centralSystem *CentralSystem) Wait() {
+	centralSystem.background.Wait()
+}
+
+/****************************************************************************************
+ *
+ * Function : CentralSystem::CallTimeout
+ *
+ *  Purpose : Get time to wait for the answer on Call to the charger.
+ *			  Call to the offline charger waits for the connection in addition
+ *
+ *    Input : chargerObj *Charger - charger of the Call
+ *
+ *   Return : time.Duration
+ */
+func (centralSystem *CentralSystem) CallTimeout(chargerObj *Charger) time.Duration {
+	timeout := time.Duration(centralSystem.Configs.CallTimeout) * time.Second
+	if !chargerObj.IsConnected() {
+		timeout += time.Duration(centralSystem.Configs.OfflineCallTimeout) * time.Second
+	}
+	return timeout
+}
@@ -0,0 +1,535 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: charger.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: Charger object shared by the HTTP handlers and connection goroutines.
+			 State of the connection is changed only by the methods under the lock
+	=============================================================================
+*/
+
+package example
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/core"
+	"net"
+	"sync"
+	"time"
+)
+
+var (
+	ErrReconnectRejected = errors.New("Charger is connected already, connection is rejected by reconnect policy")
+)
+
+/****************************************************************************************
+ *	Struct 	: Charger
+ *
+ * 	Purpose : Struct handles charger parameters in the gorutines
+ *
+*****************************************************************************************/
+type Charger struct {
+	Name              string
+	AuthToken         string `json:"-"` // Hash of the password, see core.HashAuthorizationKey
+	HeartBeatInterval int
+	Connectors        *ConnectorStates  `json:"Connectors"`
+	Outbound          *OutboundQueue    `json:"-"` // Messages waiting for the writing goroutine
+	Calls             *core.CallTracker `json:"-"` // Outstanding Call sent to the charger
+	// Seconds between pings of the server, 0 - disabled (as WebSocketPingInterval configuration key)
+	WebSocketPingInterval int
+	PongTimeout           int         `json:"-"` // Seconds to wait for the pong after ping
+	defaultPingInterval   int         // WebSocketPingInterval of the configs
+	isPingIntervalSet     bool        // WebSocketPingInterval is set for the charger
+	connection            *Connection // Current websocket connection of the charger
+	authorised            bool
+	remoteIP              string
+	subprotocol           string    // Subprotocol agreed on the handshake
+	lastSeen              time.Time // Time of the last message or pong from the charger
+	offlineReason         string    // Why the last connection was closed
+	offlineHandler        func(chargerName string, reason string)
+	connMux               sync.Mutex
+	adoptMux              sync.Mutex    // Serialises handshakes, held while previous connection is pinged
+	pong                  chan struct{} // Signals pong answered on PingConnection
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargerConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the Charger
+ *
+ *	  Input : Nothing
+ *
+ *	Return : *Charger object
+ */
+func ChargerConstructor() *Charger {
+	charger := Charger{}
+	charger.init()
+	return &charger
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::init
+ *
+ *  Purpose : Initiate variables of the Charger structure
+ *
+ *	  Input : Nothing
+ *
+ *	 Return : Nothing
+ */
+func (charger *Charger) init() {
+	charger.Name = ""
+	charger.AuthToken = ""
+	charger.HeartBeatInterval = 300
+	charger.Connectors = ConnectorStatesConstructor()
+	charger.Outbound = OutboundQueueConstructor(10)
+	charger.Calls = core.CallTrackerConstructor()
+	charger.WebSocketPingInterval = 0
+	charger.PongTimeout = 10
+	charger.defaultPingInterval = 0
+	charger.isPingIntervalSet = false
+	charger.connection = nil
+	charger.authorised = false
+	charger.remoteIP = ""
+	charger.subprotocol = ""
+	charger.offlineReason = ""
+	charger.offlineHandler = nil
+	charger.pong = make(chan struct{}, 1)
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::MarshalJSON
+ *
+ *  Purpose : Convert charger state to JSON under the lock
+ *
+ *    Input : Nothing
+ *
+ *   Return : []byte - charger in JSON format
+ *			  error - if happened, nil otherwise
+ */
+func (charger *Charger) MarshalJSON() ([]byte, error) {
+	charger.connMux.Lock()
+	defer charger.connMux.Unlock()
+
+	return json.Marshal(struct {
+		Name                  string
+		HeartBeatInterval     int
+		AuthConnection        bool
+		Connected             bool
+		Subprotocol           string
+		RemoteIP              string
+		Connectors            *ConnectorStates
+		WebSocketPingInterval int
+		LastSeen              time.Time
+		OfflineReason         string
+		OutboundMessages      int
+	}{
+		Name:                  charger.Name,
+		HeartBeatInterval:     charger.HeartBeatInterval,
+		AuthConnection:        charger.authorised,
+		Connected:             charger.connection != nil,
+		Subprotocol:           charger.subprotocol,
+		RemoteIP:              charger.remoteIP,
+		Connectors:            charger.Connectors,
+		WebSocketPingInterval: charger.WebSocketPingInterval,
+		LastSeen:              charger.lastSeen,
+		OfflineReason:         charger.offlineReason,
+		OutboundMessages:      charger.Outbound.Len(),
+	})
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::Connected
+ *
+ *  Purpose : Set new websocket connection of the authorised charger
+ *
+ *    Input : connection *Connection - upgraded connection
+ *
+ *   Return : *Connection - previous connection to be closed by caller, nil if none
+ */
+func (charger *Charger) Connected(connection *Connection) *Connection {
+	charger.connMux.Lock()
+	defer charger.connMux.Unlock()
+
+	// Pong is read by the reading goroutine of the connection
+	connection.SetPongHandler(func() {
+		select {
+		case charger.pong <- struct{}{}:
+		default:
+		}
+		charger.KeepAlive(connection)
+	})
+
+	previousConnection := charger.connection
+	charger.connection = connection
+	charger.authorised = true
+	charger.remoteIP = connection.RemoteAddr().String()
+	charger.subprotocol = connection.Subprotocol()
+	charger.lastSeen = time.Now()
+	charger.offlineReason = ""
+
+	// Call written to the previous connection will not be answered
+	if previousConnection != nil {
+		charger.Calls.CancelWritten()
+	}
+
+	return previousConnection
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::Adopt
+ *
+ *  Purpose : Check reconnect policy and set new connection of the charger in one step.
+ *			  Handshakes of the charger wait for each other, so connection adopted by
+ *			  concurrent handshake is checked by the policy as well
+ *
+ *    Input : acceptReconnect func(*Charger) bool - policy for the connected charger,
+ *				false - new connection is rejected
+ *			  upgrade func() (*Connection, error) - upgrade of the handshake to websocket
+ *
+ *   Return : *Connection - new connection
+ *			  *Connection - previous connection to be closed by caller, nil if none
+ *			  error - ErrReconnectRejected or error of the upgrade, nil otherwise
+ */
+func (charger *Charger) Adopt(acceptReconnect func(*Charger) bool, upgrade func() (*Connection, error)) (*Connection, *Connection, error) {
+	// Lock of the state is not held, ping of the policy is answered by the reading goroutine
+	charger.adoptMux.Lock()
+	defer charger.adoptMux.Unlock()
+
+	if charger.IsConnected() && !acceptReconnect(charger) {
+		return nil, nil, ErrReconnectRejected
+	}
+
+	connection, err := upgrade()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return connection, charger.Connected(connection), nil
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::IsConnected
+ *
+ *  Purpose : Check if charger has websocket connection
+ *
+ *    Input : Nothing
+ *
+ *   Return : bool
+ */
+func (charger *Charger) IsConnected() bool {
+	charger.connMux.Lock()
+	defer charger.connMux.Unlock()
+	return charger.connection != nil
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::IsAuthorised
+ *
+ *  Purpose : Check if charger is authorised on the handshake of current connection
+ *
+ *    Input : Nothing
+ *
+ *   Return : bool
+ */
+func (charger *Charger) IsAuthorised() bool {
+	charger.connMux.Lock()
+	defer charger.connMux.Unlock()
+	return charger.authorised
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::IsConnection
+ *
+ *  Purpose : Check if connection is the current connection of the charger
+ *
+ *    Input : connection *Connection - connection to check
+ *
+ *   Return : bool - false when connection is closed or replaced
+ */
+func (charger *Charger) IsConnection(connection *Connection) bool {
+	charger.connMux.Lock()
+	defer charger.connMux.Unlock()
+	return charger.connection == connection
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::ReadTimeout
+ *
+ *  Purpose : Get time the charger can be silent before it is considered offline.
+ *			  With pings enabled pong must arrive in the interval, otherwise
+ *			  any message as Heartbeat must arrive in the doubled heartbeat interval
+ *
+ *    Input : Nothing
+ *
+ *   Return : time.Duration - 0 when charger is never considered offline
+ */
+func (charger *Charger) ReadTimeout() time.Duration {
+	charger.connMux.Lock()
+	defer charger.connMux.Unlock()
+	return charger.readTimeout()
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::readTimeout
+ *
+ *  Purpose : Get time the charger can be silent, must be called under the lock
+ *
+ *    Input : Nothing
+ *
+ *   Return : time.Duration - 0 when charger is never considered offline
+ */
+func (charger *Charger) readTimeout() time.Duration {
+	if charger.WebSocketPingInterval > 0 {
+		return time.Duration(charger.WebSocketPingInterval+charger.PongTimeout) * time.Second
+	}
+
+	if charger.HeartBeatInterval > 0 {
+		return time.Duration(2*charger.HeartBeatInterval) * time.Second
+	}
+
+	return 0
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::ReadErrorReason
+ *
+ *  Purpose : Get reason of the closed connection from the error of the reading goroutine.
+ *			  Read deadline is exceeded when charger sent no messages and no pongs
+ *
+ *    Input : readingErr error - error of the read
+ *
+ *   Return : string - reason for Connection::Close
+ */
+func (charger *Charger) ReadErrorReason(readingErr error) string {
+	if netErr, isNetError := readingErr.(net.Error); isNetError && netErr.Timeout() {
+		return fmt.Sprintf("No messages or pongs in %v", charger.ReadTimeout())
+	}
+
+	return readingErr.Error()
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::KeepAlive
+ *
+ *  Purpose : Mark charger as seen and extend read deadline of the connection.
+ *			  Called by the reading goroutine on every message and pong
+ *
+ *    Input : connection *Connection - connection which received data
+ *
+ *   Return : Nothing
+ */
+func (charger *Charger) KeepAlive(connection *Connection) {
+	charger.connMux.Lock()
+	defer charger.connMux.Unlock()
+
+	if charger.connection != connection {
+		return
+	}
+
+	charger.lastSeen = time.Now()
+
+	if readTimeout := charger.readTimeout(); readTimeout > 0 {
+		connection.SetReadDeadline(charger.lastSeen.Add(readTimeout))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::PingConnection
+ *
+ *  Purpose : Check that current connection is alive by ping control message
+ *
+ *    Input : timeout time.Duration - time to wait for the pong
+ *
+ *   Return : bool - true when charger answered pong in time
+ */
+func (charger *Charger) PingConnection(timeout time.Duration) bool {
+	charger.connMux.Lock()
+	connection := charger.connection
+	charger.connMux.Unlock()
+
+	if connection == nil {
+		return false
+	}
+
+	// Drop pong of the previous ping
+	select {
+	case <-charger.pong:
+	default:
+	}
+
+	if err := connection.Ping(timeout); err != nil {
+		return false
+	}
+
+	select {
+	case <-charger.pong:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::Disconnected
+ *
+ *  Purpose : Clear Charger parameters when disconnected from socket and call the
+ *			  offline handler. Parameters are kept when connection was replaced by the new one
+ *
+ *    Input : connection *Connection - closed connection
+ *			  reason string - why connection is closed
+ *
+ *   Return : bool - true when parameters are cleared
+ */
+func (charger *Charger) Disconnected(connection *Connection, reason string) bool {
+	charger.connMux.Lock()
+
+	if charger.connection != connection {
+		charger.connMux.Unlock()
+		return false
+	}
+
+	charger.connection = nil
+	charger.authorised = false
+	charger.remoteIP = ""
+	charger.subprotocol = ""
+	charger.offlineReason = reason
+	charger.Calls.CancelWritten() // Written Call will not be answered, queued one waits for the next connection
+	offlineHandler := charger.offlineHandler
+	charger.connMux.Unlock()
+
+	// Handler is called without the lock, it can read state of the charger
+	if offlineHandler != nil {
+		offlineHandler(charger.Name, reason)
+	}
+	return true
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::SetOfflineHandler
+ *
+ *  Purpose : Set handler called by the reading goroutine when charger goes offline,
+ *			  as example when nothing is received before read deadline
+ *
+ *    Input : offlineHandler func(chargerName string, reason string) - handler,
+ *				nil - not called
+ *
+ *   Return : Nothing
+ */
+func (charger *Charger) SetOfflineHandler(offlineHandler func(chargerName string, reason string)) {
+	charger.connMux.Lock()
+	defer charger.connMux.Unlock()
+
+	charger.offlineHandler = offlineHandler
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::Settings
+ *
+ *  Purpose : Get settings of the charger in the format of the registry.
+ *			  WebSocketPingInterval is nil when charger uses the value of the configs
+ *
+ *    Input : Nothing
+ *
+ *   Return : ChargerFromFile
+ */
+func (charger *Charger) Settings() ChargerFromFile {
+	charger.connMux.Lock()
+	defer charger.connMux.Unlock()
+
+	settings := ChargerFromFile{
+		Name:              charger.Name,
+		Authorization:     charger.AuthToken,
+		HeartBeatInterval: charger.HeartBeatInterval,
+	}
+	if charger.isPingIntervalSet {
+		webSocketPingInterval := charger.WebSocketPingInterval
+		settings.WebSocketPingInterval = &webSocketPingInterval
+	}
+
+	return settings
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::PingInterval
+ *
+ *  Purpose : Get seconds between pings of the server, set for the charger or of the configs
+ *
+ *    Input : Nothing
+ *
+ *   Return : int - 0 when ping is disabled
+ */
+func (charger *Charger) PingInterval() int {
+	charger.connMux.Lock()
+	defer charger.connMux.Unlock()
+
+	return charger.WebSocketPingInterval
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::ApplySettings
+ *
+ *  Purpose : Change settings of the charger at runtime. Current connection is kept,
+ *			  new AuthToken and ping interval are used from the next connection.
+ *			  Charger without WebSocketPingInterval uses the value of the configs
+ *
+ *    Input : settings ChargerFromFile - validated settings of the charger
+ *
+ *   Return : Nothing
+ */
+func (charger *Charger) ApplySettings(settings ChargerFromFile) {
+	charger.connMux.Lock()
+	defer charger.connMux.Unlock()
+
+	charger.AuthToken = settings.Authorization
+	charger.HeartBeatInterval = settings.HeartBeatInterval
+	charger.isPingIntervalSet = settings.WebSocketPingInterval != nil
+	if charger.isPingIntervalSet {
+		charger.WebSocketPingInterval = *settings.WebSocketPingInterval
+	} else {
+		charger.WebSocketPingInterval = charger.defaultPingInterval
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : Charger::CloseConnection
+ *
+ *  Purpose : Close current connection of the charger, as example when charger is removed.
+ *			  Reading goroutine clears parameters of the connection
+ *
+ *    Input : reason string - why connection is closed
+ *
+ *   Return : bool - false when charger is not connected
+ */
+func (charger *Charger) CloseConnection(reason string) bool {
+	charger.connMux.Lock()
+	connection := charger.connection
+	charger.connMux.Unlock()
+
+	if connection == nil {
+		return false
+	}
+
+	connection.Close(reason)
+	return true
+}
@@ -0,0 +1,117 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: chargerregistry.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: ChargerRegistry interface of the chargers allowed to connect to the server.
+			 Chargers are added, changed and removed at runtime by the API
+	=============================================================================
+*/
+
+package example
+
+import (
+	"errors"
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/core"
+	"strings"
+)
+
+var (
+	ErrChargerNotFound = errors.New("Charger is not exists in configs")
+	ErrChargerExists   = errors.New("Charger exists already")
+)
+
+/****************************************************************************************
+ *	Interface : ChargerRegistry
+ *
+ * 	Purpose : Interface of the storage of the chargers. Charger object is shared
+ *			  by the API handlers and connection goroutines, registry keeps the same
+ *			  object while charger is updated
+ *
+*****************************************************************************************/
+type ChargerRegistry interface {
+	// Get charger by name, ErrChargerNotFound when charger is not registered
+	Get(chargerName string) (*Charger, error)
+	// List chargers sorted by name
+	List() []*Charger
+	// Create charger from the settings, ErrChargerExists when name is used already
+	Create(settings ChargerFromFile) (*Charger, error)
+	// Update settings of the charger with the same name
+	Update(settings ChargerFromFile) (*Charger, error)
+	// Set AuthToken of the charger, other settings are kept
+	SetAuthorization(chargerName string, authToken string) (*Charger, error)
+	// Delete charger, connection of the removed charger is closed by caller
+	Delete(chargerName string) (*Charger, error)
+}
+
+/****************************************************************************************
+ *	Struct 	: ChargerDefaults
+ *
+ * 	Purpose : Struct handles parameters of the configs applied to the created chargers
+ *
+*****************************************************************************************/
+type ChargerDefaults struct {
+	WebSocketPingInterval int // Used when settings of the charger have no WebSocketPingInterval
+	PongTimeout           int
+	MaxOutboundCalls      int
+}
+
+/****************************************************************************************
+ *
+ * Function : ChargerFromFile::Validate
+ *
+ *  Purpose : Check settings of the charger before it is added to the registry
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if settings are not valid, nil otherwise
+ */
+func (settings *ChargerFromFile) Validate() error {
+	// Name is the last part of the charger's endpoint URL
+	if settings.Name == "" || strings.ContainsAny(settings.Name, "/?#") {
+		return errors.New(fmt.Sprintf("Wrong charger name '%v'", settings.Name))
+	}
+
+	// Only hashed passwords are allowed in configs
+	if settings.Authorization != "" && !core.IsHashedAuthorizationKey(settings.Authorization) {
+		return errors.New(fmt.Sprintf("Authorization of the charger '%v' is not a hash, use core.HashAuthorizationKey", settings.Name))
+	}
+
+	if settings.HeartBeatInterval < 0 {
+		return errors.New(fmt.Sprintf("Wrong HeartBeatInterval '%v'", settings.HeartBeatInterval))
+	}
+
+	if settings.WebSocketPingInterval != nil && *settings.WebSocketPingInterval < 0 {
+		return errors.New(fmt.Sprintf("Wrong WebSocketPingInterval '%v'", *settings.WebSocketPingInterval))
+	}
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : newRegistryCharger
+ *
+ *  Purpose : Create charger from the settings and defaults of the configs
+ *
+ *    Input : settings ChargerFromFile - settings of the charger
+ *			  defaults ChargerDefaults - parameters of the configs
+ *
+ *   Return : *Charger object
+ *			  error - if settings are not valid, nil otherwise
+ */
+func newRegistryCharger(settings ChargerFromFile, defaults ChargerDefaults) (*Charger, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	charger := ChargerConstructor()
+	charger.Name = settings.Name
+	charger.defaultPingInterval = defaults.WebSocketPingInterval
+	charger.PongTimeout = defaults.PongTimeout
+	charger.Outbound = OutboundQueueConstructor(defaults.MaxOutboundCalls)
+	charger.ApplySettings(settings)
+
+	return charger, nil
+}
@@ -0,0 +1,219 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: chargerregistry_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: File with test cases for the implementations of the ChargerRegistry
+	=============================================================================
+*/
+
+package example
+
+import (
+	"fmt"
+	"github.com/CoderSergiy/golib/logging"
+	"github.com/CoderSergiy/ocpp16-go/core"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/****************************************************************************************
+ *
+ * Function : testChargerRegistry
+ *
+ *  Purpose : Test create, update and delete of the chargers by the registry
+ *
+ *   Return : Nothing
+ */
+func testChargerRegistry(t *testing.T, registry ChargerRegistry) {
+
+	authToken, _ := core.HashAuthorizationKey("0123456789ABCDEF")
+	charger, err := registry.Create(ChargerFromFile{Name: "CP002", Authorization: authToken, HeartBeatInterval: 60})
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot create charger with error '%v'", err))
+	}
+	if charger.PingInterval() != 30 || charger.PongTimeout != 5 || charger.Outbound.MaxCalls != 3 {
+		t.Error(fmt.Sprintf("Defaults are not applied to the charger '%+v'", charger.Settings()))
+	}
+	// Value of the configs is not written to the settings of the charger
+	if settings := charger.Settings(); settings.WebSocketPingInterval != nil {
+		t.Error(fmt.Sprintf("Default ping interval is returned as setting %v", *settings.WebSocketPingInterval))
+	}
+
+	// Name is unique, settings are validated
+	if _, err := registry.Create(ChargerFromFile{Name: "CP002"}); err != ErrChargerExists {
+		t.Error(fmt.Sprintf("Expected ErrChargerExists, got '%v'", err))
+	}
+	if _, err := registry.Create(ChargerFromFile{Name: "CP003", Authorization: "password"}); err == nil {
+		t.Error("Charger with not hashed password is created")
+	}
+	if _, err := registry.Create(ChargerFromFile{Name: "CP/003"}); err == nil {
+		t.Error("Charger with wrong name is created")
+	}
+
+	if _, err := registry.Create(ChargerFromFile{Name: "CP001"}); err != nil {
+		t.Error(fmt.Sprintf("Cannot create charger with error '%v'", err))
+	}
+	if chargers := registry.List(); len(chargers) != 2 || chargers[0].Name != "CP001" || chargers[1].Name != "CP002" {
+		t.Error(fmt.Sprintf("Wrong list of the chargers %v", len(chargers)))
+	}
+
+	// Object of the charger is kept, AuthToken is rotated
+	newAuthToken, _ := core.HashAuthorizationKey("FEDCBA9876543210")
+	pingInterval := 0
+	updated, err := registry.Update(ChargerFromFile{Name: "CP002", Authorization: newAuthToken, HeartBeatInterval: 120, WebSocketPingInterval: &pingInterval})
+	if err != nil || updated != charger {
+		t.Error(fmt.Sprintf("Cannot update charger with error '%v'", err))
+	}
+	if settings := charger.Settings(); !core.VerifyAuthorizationKey(settings.Authorization, "FEDCBA9876543210") || settings.HeartBeatInterval != 120 || *settings.WebSocketPingInterval != 0 {
+		t.Error(fmt.Sprintf("Settings are not updated '%+v'", settings))
+	}
+
+	// Only AuthToken is changed, other settings are kept
+	rotatedAuthToken, _ := core.HashAuthorizationKey("0011223344556677")
+	if updated, err := registry.SetAuthorization("CP002", rotatedAuthToken); err != nil || updated != charger {
+		t.Error(fmt.Sprintf("Cannot set AuthToken with error '%v'", err))
+	}
+	if settings := charger.Settings(); !core.VerifyAuthorizationKey(settings.Authorization, "0011223344556677") || settings.HeartBeatInterval != 120 || *settings.WebSocketPingInterval != 0 {
+		t.Error(fmt.Sprintf("Settings are not kept with AuthToken '%+v'", settings))
+	}
+	if _, err := registry.SetAuthorization("CP002", "password"); err == nil {
+		t.Error("Not hashed password is set as AuthToken")
+	}
+	if _, err := registry.SetAuthorization("CP004", rotatedAuthToken); err != ErrChargerNotFound {
+		t.Error(fmt.Sprintf("Expected ErrChargerNotFound, got '%v'", err))
+	}
+
+	// Charger without the setting uses value of the configs again
+	if _, err := registry.Update(ChargerFromFile{Name: "CP001", WebSocketPingInterval: &pingInterval}); err != nil {
+		t.Error(fmt.Sprintf("Cannot update charger with error '%v'", err))
+	}
+	if updated, err := registry.Update(ChargerFromFile{Name: "CP001"}); err != nil || updated.PingInterval() != 30 || updated.Settings().WebSocketPingInterval != nil {
+		t.Error(fmt.Sprintf("Ping interval of the configs is not restored with error '%v'", err))
+	}
+
+	if _, err := registry.Update(ChargerFromFile{Name: "CP004"}); err != ErrChargerNotFound {
+		t.Error(fmt.Sprintf("Expected ErrChargerNotFound, got '%v'", err))
+	}
+
+	if deleted, err := registry.Delete("CP001"); err != nil || deleted.Name != "CP001" {
+		t.Error(fmt.Sprintf("Cannot delete charger with error '%v'", err))
+	}
+	if _, err := registry.Get("CP001"); err != ErrChargerNotFound {
+		t.Error(fmt.Sprintf("Expected ErrChargerNotFound, got '%v'", err))
+	}
+	if _, err := registry.Delete("CP001"); err != ErrChargerNotFound {
+		t.Error(fmt.Sprintf("Expected ErrChargerNotFound, got '%v'", err))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestMemoryChargerRegistry
+ *
+ *  Purpose : Test in-memory implementation of the ChargerRegistry
+ *
+ *   Return : Nothing
+ */
+func TestMemoryChargerRegistry(t *testing.T) {
+	testChargerRegistry(t, MemoryChargerRegistryConstructor(ChargerDefaults{WebSocketPingInterval: 30, PongTimeout: 5, MaxOutboundCalls: 3}))
+}
+
+/****************************************************************************************
+ *
+ * Function : TestFileChargerRegistry
+ *
+ *  Purpose : Test file implementation of the ChargerRegistry and reload of the file
+ *
+ *   Return : Nothing
+ */
+func TestFileChargerRegistry(t *testing.T) {
+
+	fileName := filepath.Join(t.TempDir(), "chargers.json")
+	defaults := ChargerDefaults{WebSocketPingInterval: 30, PongTimeout: 5, MaxOutboundCalls: 3}
+
+	// New file is created with the chargers of the configs
+	registry, err := FileChargerRegistryConstructor(fileName, defaults, []ChargerFromFile{{Name: "CP005"}})
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot create registry with error '%v'", err))
+	}
+	if _, err := os.Stat(fileName); err != nil {
+		t.Error(fmt.Sprintf("File of the registry is not created with error '%v'", err))
+	}
+	if _, err := registry.Delete("CP005"); err != nil {
+		t.Error(fmt.Sprintf("Cannot delete charger with error '%v'", err))
+	}
+
+	testChargerRegistry(t, registry)
+
+	// Chargers of the configs are not used when file exists
+	reloaded, err := FileChargerRegistryConstructor(fileName, defaults, []ChargerFromFile{{Name: "CP005"}})
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot reload registry with error '%v'", err))
+	}
+	chargers := reloaded.List()
+	if len(chargers) != 1 || chargers[0].Name != "CP002" {
+		t.Fatal(fmt.Sprintf("Wrong chargers after reload %v", len(chargers)))
+	}
+	if settings := chargers[0].Settings(); !core.VerifyAuthorizationKey(settings.Authorization, "0011223344556677") || settings.HeartBeatInterval != 120 || *settings.WebSocketPingInterval != 0 {
+		t.Error(fmt.Sprintf("Settings are not reloaded '%+v'", settings))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestUpdateChargerAPI
+ *
+ *  Purpose : Test update of the charger by API, omitted fields keep current settings
+ *
+ *   Return : Nothing
+ */
+func TestUpdateChargerAPI(t *testing.T) {
+
+	log := logging.LogConstructor(filepath.Join(t.TempDir(), "api"), false)
+	registry := MemoryChargerRegistryConstructor(ChargerDefaults{WebSocketPingInterval: 30, PongTimeout: 5, MaxOutboundCalls: 3})
+	authToken, _ := core.HashAuthorizationKey("0123456789ABCDEF")
+	charger, err := registry.Create(ChargerFromFile{Name: "CP001", Authorization: authToken, HeartBeatInterval: 60})
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot create charger with error '%v'", err))
+	}
+
+	updateCharger := func(chargerName string, body string) int {
+		recorder := httptest.NewRecorder()
+		UpdateChargerAPI(chargerName, registry, &log, httptest.NewRequest(http.MethodPut, "/charger/"+chargerName, strings.NewReader(body)), recorder)
+		return recorder.Code
+	}
+
+	if code := updateCharger("CP001", `{"WebSocketPingInterval":10}`); code != http.StatusOK {
+		t.Error(fmt.Sprintf("Expected status 200, got %v", code))
+	}
+	if settings := charger.Settings(); settings.Authorization != authToken || settings.HeartBeatInterval != 60 || settings.WebSocketPingInterval == nil || *settings.WebSocketPingInterval != 10 {
+		t.Error(fmt.Sprintf("Omitted fields are changed '%+v'", settings))
+	}
+
+	if code := updateCharger("CP001", `{"HeartBeatInterval":120}`); code != http.StatusOK {
+		t.Error(fmt.Sprintf("Expected status 200, got %v", code))
+	}
+	if settings := charger.Settings(); settings.Authorization != authToken || settings.HeartBeatInterval != 120 || charger.PingInterval() != 10 {
+		t.Error(fmt.Sprintf("Omitted fields are changed '%+v'", settings))
+	}
+
+	// Null returns the charger to the value of the configs
+	if code := updateCharger("CP001", `{"WebSocketPingInterval":null}`); code != http.StatusOK || charger.Settings().WebSocketPingInterval != nil || charger.PingInterval() != 30 {
+		t.Error(fmt.Sprintf("Ping interval of the configs is not restored, status %v", code))
+	}
+
+	if code := updateCharger("CP001", `{"Name":"CP002"}`); code != http.StatusBadRequest {
+		t.Error(fmt.Sprintf("Expected status 400 for the rename, got %v", code))
+	}
+	if code := updateCharger("CP001", `{"HeartBeatInterval":-1}`); code != http.StatusBadRequest || charger.Settings().HeartBeatInterval != 120 {
+		t.Error(fmt.Sprintf("Expected status 400 for the wrong settings, got %v", code))
+	}
+	if code := updateCharger("CP002", `{}`); code != http.StatusNotFound {
+		t.Error(fmt.Sprintf("Expected status 404, got %v", code))
+	}
+}
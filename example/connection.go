@@ -0,0 +1,275 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: connection.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: Websocket connection of the charger. Connection owns the socket,
+			 serialises writes and is closed once by any of the goroutines,
+			 context of the connection is cancelled on close
+	=============================================================================
+*/
+
+package example
+
+import (
+	"context"
+	"errors"
+	"github.com/gorilla/websocket"
+	"net"
+	"sync"
+	"time"
+)
+
+var (
+	ErrConnectionClosed = errors.New("Connection is closed")
+)
+
+const (
+	// Time to write message to the charger before connection is considered broken
+	CONNECTION_WRITE_TIMEOUT time.Duration = 10 * time.Second
+)
+
+/****************************************************************************************
+ *	Struct 	: Connection
+ *
+ * 	Purpose : Struct handles websocket connection of the charger
+ *
+*****************************************************************************************/
+type Connection struct {
+	conn      *websocket.Conn
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{} // Closed when connection is closed and reason is set
+	writeMux  sync.Mutex
+	closeOnce sync.Once
+	reasonMux sync.Mutex
+	reason    string // Why connection is closed
+}
+
+/****************************************************************************************
+ *
+ * Function : ConnectionConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the Connection
+ *
+ *	  Input : parent context.Context - connection is closed when parent is done
+ *			  conn *websocket.Conn - upgraded websocket
+ *
+ *	Return : *Connection object
+ */
+func ConnectionConstructor(parent context.Context, conn *websocket.Conn) *Connection {
+	connection := Connection{}
+	connection.conn = conn
+	connection.ctx, connection.cancel = context.WithCancel(parent)
+	connection.done = make(chan struct{})
+
+	// Socket is closed with parent context as example on server shutdown
+	go func() {
+		<-connection.ctx.Done()
+		connection.CloseWithCode(websocket.CloseGoingAway, "Server is stopped")
+	}()
+
+	return &connection
+}
+
+/****************************************************************************************
+ *
+ * Function : Connection::Done
+ *
+ *  Purpose : Get channel which is closed when connection is closed
+ *
+ *    Input : Nothing
+ *
+ *   Return : <-chan struct{}
+ */
+func (connection *Connection) Done() <-chan struct{} {
+	return connection.done
+}
+
+/****************************************************************************************
+ *
+ * Function : Connection::Context
+ *
+ *  Purpose : Get context which is cancelled when connection is closed
+ *
+ *    Input : Nothing
+ *
+ *   Return : context.Context
+ */
+func (connection *Connection) Context() context.Context {
+	return connection.ctx
+}
+
+/****************************************************************************************
+ *
+ * Function : Connection::RemoteAddr
+ *
+ *  Purpose : Get address of the charger
+ *
+ *    Input : Nothing
+ *
+ *   Return : net.Addr
+ */
+func (connection *Connection) RemoteAddr() net.Addr {
+	return connection.conn.RemoteAddr()
+}
+
+/****************************************************************************************
+ *
+ * Function : Connection::Subprotocol
+ *
+ *  Purpose : Get subprotocol agreed on the handshake
+ *
+ *    Input : Nothing
+ *
+ *   Return : string
+ */
+func (connection *Connection) Subprotocol() string {
+	return connection.conn.Subprotocol()
+}
+
+/****************************************************************************************
+ *
+ * Function : Connection::ReadMessage
+ *
+ *  Purpose : Read next message. Must be called by the single reading goroutine
+ *
+ *    Input : Nothing
+ *
+ *   Return : []byte - message
+ *			  error - if connection is broken or closed
+ */
+func (connection *Connection) ReadMessage() ([]byte, error) {
+	_, rawMessage, err := connection.conn.ReadMessage()
+	return rawMessage, err
+}
+
+/****************************************************************************************
+ *
+ * Function : Connection::SetReadDeadline
+ *
+ *  Purpose : Set time when ReadMessage fails if nothing is received
+ *
+ *    Input : deadline time.Time
+ *
+ *   Return : Nothing
+ */
+func (connection *Connection) SetReadDeadline(deadline time.Time) {
+	connection.conn.SetReadDeadline(deadline)
+}
+
+/****************************************************************************************
+ *
+ * Function : Connection::SetPongHandler
+ *
+ *  Purpose : Set handler of the pong, called by the reading goroutine
+ *
+ *    Input : handler func() - handler of the pong
+ *
+ *   Return : Nothing
+ */
+func (connection *Connection) SetPongHandler(handler func()) {
+	connection.conn.SetPongHandler(func(string) error {
+		handler()
+		return nil
+	})
+}
+
+/****************************************************************************************
+ *
+ * Function : Connection::Send
+ *
+ *  Purpose : Write text message to the charger. Safe for concurrent use
+ *
+ *    Input : message string - message to send
+ *
+ *   Return : error - ErrConnectionClosed or error of the socket, nil otherwise
+ */
+func (connection *Connection) Send(message string) error {
+	connection.writeMux.Lock()
+	defer connection.writeMux.Unlock()
+
+	if connection.ctx.Err() != nil {
+		return ErrConnectionClosed
+	}
+
+	connection.conn.SetWriteDeadline(time.Now().Add(CONNECTION_WRITE_TIMEOUT))
+	return connection.conn.WriteMessage(websocket.TextMessage, []byte(message))
+}
+
+/****************************************************************************************
+ *
+ * Function : Connection::Ping
+ *
+ *  Purpose : Write ping control message, pong is passed to the pong handler
+ *
+ *    Input : timeout time.Duration - time to write the ping
+ *
+ *   Return : error - ErrConnectionClosed or error of the socket, nil otherwise
+ */
+func (connection *Connection) Ping(timeout time.Duration) error {
+	if connection.ctx.Err() != nil {
+		return ErrConnectionClosed
+	}
+
+	return connection.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(timeout))
+}
+
+/****************************************************************************************
+ *
+ * Function : Connection::Close
+ *
+ *  Purpose : Close connection with reason and normal closure status code
+ *
+ *    Input : reason string - why connection is closed
+ *
+ *   Return : Nothing
+ */
+func (connection *Connection) Close(reason string) {
+	connection.CloseWithCode(websocket.CloseNormalClosure, reason)
+}
+
+/****************************************************************************************
+ *
+ * Function : Connection::CloseWithCode
+ *
+ *  Purpose : Close connection with reason. Only first call has effect,
+ *			  reading goroutine gets error and writing goroutine is stopped by Done
+ *
+ *    Input : code int - status code of the close frame, as example websocket.CloseGoingAway
+ *			  reason string - why connection is closed
+ *
+ *   Return : Nothing
+ */
+func (connection *Connection) CloseWithCode(code int, reason string) {
+	connection.closeOnce.Do(func() {
+		connection.reasonMux.Lock()
+		connection.reason = reason
+		connection.reasonMux.Unlock()
+
+		connection.cancel()
+
+		// Close frame is not waited, charger may be unreachable already
+		closeMessage := websocket.FormatCloseMessage(code, "")
+		connection.conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(time.Second))
+		connection.conn.Close()
+
+		close(connection.done)
+	})
+}
+
+/****************************************************************************************
+ *
+ * Function : Connection::Reason
+ *
+ *  Purpose : Get reason of the close
+ *
+ *    Input : Nothing
+ *
+ *   Return : string - empty while connection is open
+ */
+func (connection *Connection) Reason() string {
+	connection.reasonMux.Lock()
+	defer connection.reasonMux.Unlock()
+	return connection.reason
+}
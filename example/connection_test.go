@@ -0,0 +1,472 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: connection_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: File with test cases for lifecycle of the charger connection
+	=============================================================================
+*/
+
+package example
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/core"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/****************************************************************************************
+ *
+ * Function : startTestServer
+ *
+ *  Purpose : Start server which passes upgraded connections of the charger to the test.
+ *			  Reading goroutine is running for every connection to handle pongs
+ *
+ *    Input : t *testing.T - test object
+ *			  ctx context.Context - parent context of the connections
+ *			  charger *Charger - charger of the connections
+ *
+ *   Return : *httptest.Server, chan *Connection
+ */
+func startTestServer(t *testing.T, ctx context.Context, charger *Charger) (*httptest.Server, chan *Connection) {
+	configs := ServerConfigsConstructor()
+	return startReconnectTestServer(t, ctx, &configs, charger)
+}
+
+/****************************************************************************************
+ *
+ * Function : startReconnectTestServer
+ *
+ *  Purpose : Start test server which checks ReconnectPolicy of the configs before upgrade,
+ *			  rejected connection gets 400 as in the handler of the server
+ *
+ *    Input : t *testing.T - test object
+ *			  ctx context.Context - parent context of the connections
+ *			  configs *Configs - configs with ReconnectPolicy
+ *			  charger *Charger - charger of the connections
+ *
+ *   Return : *httptest.Server, chan *Connection
+ */
+func startReconnectTestServer(t *testing.T, ctx context.Context, configs *Configs, charger *Charger) (*httptest.Server, chan *Connection) {
+	connections := make(chan *Connection, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connection, previousConnection, err := charger.Adopt(configs.AcceptReconnect, func() (*Connection, error) {
+			conn, err := websocket.Upgrade(w, r, nil, 1024, 1024)
+			if err != nil {
+				return nil, err
+			}
+			return ConnectionConstructor(ctx, conn), nil
+		})
+		if err == ErrReconnectRejected {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			t.Error(fmt.Sprintf("Cannot upgrade connection with error '%v'", err))
+			return
+		}
+		if previousConnection != nil {
+			previousConnection.Close("Replaced by new connection")
+		}
+
+		go func() {
+			// Connection is closed when nothing is received before read deadline
+			charger.KeepAlive(connection)
+			for {
+				if _, err := connection.ReadMessage(); err != nil {
+					connection.Close(charger.ReadErrorReason(err))
+					charger.Disconnected(connection, connection.Reason())
+					return
+				}
+				charger.KeepAlive(connection)
+			}
+		}()
+
+		connections <- connection
+	}))
+
+	return server, connections
+}
+
+/****************************************************************************************
+ *
+ * Function : dialTestServer
+ *
+ *  Purpose : Connect client to the test server, client answers pings while reading
+ *
+ *   Return : *websocket.Conn
+ */
+func dialTestServer(t *testing.T, server *httptest.Server) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot connect with error '%v'", err))
+	}
+	return conn
+}
+
+/****************************************************************************************
+ *
+ * Function : TestConnectionLifecycle
+ *
+ *  Purpose : Test connect, concurrent sends, replace and disconnect of the charger
+ *
+ *   Return : Nothing
+ */
+func TestConnectionLifecycle(t *testing.T) {
+
+	charger := ChargerConstructor()
+	charger.Name = "CP001"
+	server, connections := startTestServer(t, context.Background(), charger)
+	defer server.Close()
+
+	client := dialTestServer(t, server)
+	defer client.Close()
+	connection := <-connections
+
+	if !charger.IsConnected() || !charger.IsAuthorised() || !charger.IsConnection(connection) {
+		t.Error("Charger is not connected")
+	}
+
+	// Messages are written by many goroutines at the same time
+	const sendersCount = 50
+	var waitGroup sync.WaitGroup
+	for index := 0; index < sendersCount; index++ {
+		waitGroup.Add(1)
+		go func(index int) {
+			defer waitGroup.Done()
+			if err := connection.Send(fmt.Sprintf("message %v", index)); err != nil {
+				t.Error(fmt.Sprintf("Cannot send message with error '%v'", err))
+			}
+			// Status is read by API while connection is used
+			json.Marshal(charger)
+		}(index)
+	}
+	waitGroup.Wait()
+
+	received := make(map[string]bool)
+	for len(received) < sendersCount {
+		_, message, err := client.ReadMessage()
+		if err != nil {
+			t.Fatal(fmt.Sprintf("Cannot read message with error '%v'", err))
+		}
+		received[string(message)] = true
+	}
+
+	// Read pongs by client while server pings
+	go func() {
+		for {
+			if _, _, err := client.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	if !charger.PingConnection(time.Second) {
+		t.Error("Charger did not answer ping")
+	}
+
+	// New connection of the same charger replaces previous one
+	newClient := dialTestServer(t, server)
+	defer newClient.Close()
+	newConnection := <-connections
+
+	<-connection.Done()
+	if connection.Reason() != "Replaced by new connection" || charger.Disconnected(connection, connection.Reason()) {
+		t.Error(fmt.Sprintf("Previous connection is not replaced, reason '%v'", connection.Reason()))
+	}
+	if err := connection.Send("late message"); err != ErrConnectionClosed {
+		t.Error(fmt.Sprintf("Expected ErrConnectionClosed, got '%v'", err))
+	}
+	if !charger.IsConnected() || !charger.IsConnection(newConnection) {
+		t.Error("Charger lost new connection")
+	}
+
+	// Charger closes connection
+	newClient.Close()
+	<-newConnection.Done()
+
+	// Disconnected is called by the reading goroutine after close
+	deadline := time.Now().Add(time.Second)
+	for charger.IsConnected() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	status, _ := json.Marshal(charger)
+	if charger.IsConnected() || charger.IsAuthorised() || !strings.Contains(string(status), `"Connected":false`) {
+		t.Error(fmt.Sprintf("Charger is not disconnected, status '%v'", string(status)))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestConnectionParentContext
+ *
+ *  Purpose : Test that connection is closed with parent context
+ *
+ *   Return : Nothing
+ */
+func TestConnectionParentContext(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	charger := ChargerConstructor()
+	server, connections := startTestServer(t, ctx, charger)
+	defer server.Close()
+
+	client := dialTestServer(t, server)
+	defer client.Close()
+	connection := <-connections
+
+	cancel()
+
+	select {
+	case <-connection.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Connection is not closed with parent context")
+	}
+
+	if connection.Reason() != "Server is stopped" {
+		t.Error(fmt.Sprintf("Wrong reason '%v'", connection.Reason()))
+	}
+
+	// Charger gets close frame of the stopped server
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	if _, _, err := client.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Error(fmt.Sprintf("Expected going away close frame, got '%v'", err))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestReconnectPolicies
+ *
+ *  Purpose : Test second connection of the connected charger by every ReconnectPolicy
+ *
+ *   Return : Nothing
+ */
+func TestReconnectPolicies(t *testing.T) {
+
+	testCases := []struct {
+		policy      string
+		isAnswering bool // Previous connection answers pings
+		isReplaced  bool
+	}{
+		{policy: RECONNECT_POLICY_REPLACE, isAnswering: true, isReplaced: true},
+		{policy: RECONNECT_POLICY_REJECT, isAnswering: false, isReplaced: false},
+		{policy: RECONNECT_POLICY_PING, isAnswering: true, isReplaced: false},
+		{policy: RECONNECT_POLICY_PING, isAnswering: false, isReplaced: true},
+	}
+
+	for _, testCase := range testCases {
+		configs := ServerConfigsConstructor()
+		configs.ReconnectPolicy = testCase.policy
+		configs.ReconnectPingTimeout = 1
+		charger := ChargerConstructor()
+		server, connections := startReconnectTestServer(t, context.Background(), &configs, charger)
+
+		client := dialTestServer(t, server)
+		previousConnection := <-connections
+		if testCase.isAnswering {
+			// Client answers pings while reading
+			go func() {
+				for {
+					if _, _, err := client.ReadMessage(); err != nil {
+						return
+					}
+				}
+			}()
+		}
+
+		newClient, response, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+		if testCase.isReplaced {
+			if err != nil {
+				t.Fatal(fmt.Sprintf("Policy '%v': new connection is rejected with error '%v'", testCase.policy, err))
+			}
+			connection := <-connections
+			select {
+			case <-previousConnection.Done():
+			case <-time.After(time.Second):
+				t.Error(fmt.Sprintf("Policy '%v': previous connection is not closed", testCase.policy))
+			}
+			if previousConnection.Reason() != "Replaced by new connection" || !charger.IsConnection(connection) {
+				t.Error(fmt.Sprintf("Policy '%v': connection is not replaced, reason '%v'", testCase.policy, previousConnection.Reason()))
+			}
+			newClient.Close()
+		} else {
+			if err == nil || response == nil || response.StatusCode != http.StatusBadRequest {
+				t.Error(fmt.Sprintf("Policy '%v': expected rejection with status 400, got error '%v'", testCase.policy, err))
+			}
+			if !charger.IsConnection(previousConnection) || previousConnection.Reason() != "" {
+				t.Error(fmt.Sprintf("Policy '%v': previous connection is not kept, reason '%v'", testCase.policy, previousConnection.Reason()))
+			}
+		}
+
+		client.Close()
+		server.Close()
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestConcurrentReconnect
+ *
+ *  Purpose : Test concurrent handshakes of the charger with reject policy,
+ *			  only one connection is accepted
+ *
+ *   Return : Nothing
+ */
+func TestConcurrentReconnect(t *testing.T) {
+
+	configs := ServerConfigsConstructor()
+	configs.ReconnectPolicy = RECONNECT_POLICY_REJECT
+	charger := ChargerConstructor()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, err := charger.Adopt(configs.AcceptReconnect, func() (*Connection, error) {
+			// Slow upgrade lets other handshakes check the policy meanwhile
+			time.Sleep(50 * time.Millisecond)
+			conn, err := websocket.Upgrade(w, r, nil, 1024, 1024)
+			if err != nil {
+				return nil, err
+			}
+			return ConnectionConstructor(context.Background(), conn), nil
+		})
+		if err == ErrReconnectRejected {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	var waitGroup sync.WaitGroup
+	var resultMux sync.Mutex
+	clients := make([]*websocket.Conn, 0)
+	rejected := 0
+	for index := 0; index < 10; index++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			client, response, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+
+			resultMux.Lock()
+			defer resultMux.Unlock()
+			if err == nil {
+				clients = append(clients, client)
+			} else if response != nil && response.StatusCode == http.StatusBadRequest {
+				rejected++
+			} else {
+				t.Error(fmt.Sprintf("Handshake is failed with error '%v'", err))
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	for _, client := range clients {
+		defer client.Close()
+	}
+	if len(clients) != 1 || rejected != 9 {
+		t.Fatal(fmt.Sprintf("Expected 1 accepted and 9 rejected connections, got %v and %v", len(clients), rejected))
+	}
+	if !charger.IsConnected() {
+		t.Error("Accepted connection is not kept")
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestOfflineDetection
+ *
+ *  Purpose : Test that charger which stops answering pongs is disconnected
+ *			  after WebSocketPingInterval + PongTimeout, its written Call is cancelled
+ *			  and offline handler is called with the reason
+ *
+ *   Return : Nothing
+ */
+func TestOfflineDetection(t *testing.T) {
+
+	charger := ChargerConstructor()
+	charger.WebSocketPingInterval = 1
+	charger.PongTimeout = 1
+	offlineReasons := make(chan string, 1)
+	charger.SetOfflineHandler(func(chargerName string, reason string) {
+		offlineReasons <- reason
+	})
+	server, connections := startTestServer(t, context.Background(), charger)
+	defer server.Close()
+
+	client := dialTestServer(t, server)
+	defer client.Close()
+	connection := <-connections
+
+	// Writing goroutine pings the charger
+	go func() {
+		pingTicker := time.NewTicker(time.Second)
+		defer pingTicker.Stop()
+		for {
+			select {
+			case <-connection.Done():
+				return
+			case <-pingTicker.C:
+				connection.Ping(time.Second)
+			}
+		}
+	}()
+
+	callResult := make(chan error, 1)
+	go func() {
+		_, err := charger.Calls.Call(context.Background(), messages.CreateCallMessage("1", core.ACTION_CLEARCACHE, map[string]interface{}{}), func(string) error {
+			// Call is written by the writing goroutine
+			charger.Calls.Written("1", true)
+			return nil
+		})
+		callResult <- err
+	}()
+
+	// Client answers pings while reading, pongs extend read deadline of 2 seconds
+	client.SetReadDeadline(time.Now().Add(3 * time.Second))
+	for {
+		if _, _, err := client.ReadMessage(); err != nil {
+			break
+		}
+	}
+	if !charger.IsConnection(connection) {
+		t.Fatal(fmt.Sprintf("Charger answering pongs is disconnected with reason '%v'", connection.Reason()))
+	}
+
+	// Client stops reading and answering pongs
+	select {
+	case <-connection.Done():
+	case <-time.After(3 * time.Second):
+		t.Fatal("Connection is not closed without pongs")
+	}
+
+	select {
+	case err := <-callResult:
+		if err != core.ErrCallDisconnected {
+			t.Error(fmt.Sprintf("Expected ErrCallDisconnected, got '%v'", err))
+		}
+	case <-time.After(time.Second):
+		t.Error("Call is not cancelled")
+	}
+
+	select {
+	case reason := <-offlineReasons:
+		if reason != "No messages or pongs in 2s" {
+			t.Error(fmt.Sprintf("Wrong reason of the offline event '%v'", reason))
+		}
+	case <-time.After(time.Second):
+		t.Error("Offline event is not fired")
+	}
+
+	status, _ := json.Marshal(charger)
+	if charger.IsConnected() || !strings.Contains(string(status), `"OfflineReason":"No messages or pongs in 2s"`) {
+		t.Error(fmt.Sprintf("Reason is not recorded, status '%v'", string(status)))
+	}
+}
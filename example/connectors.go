@@ -0,0 +1,170 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: connectors.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: State of the charger's connectors reported by StatusNotification
+	=============================================================================
+*/
+
+package example
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/core"
+	"sort"
+	"sync"
+	"time"
+)
+
+/****************************************************************************************
+ *	Struct 	: Connector
+ *
+ * 	Purpose : Struct handles last reported state of the connector
+ *
+*****************************************************************************************/
+type Connector struct {
+	ConnectorId     int                       `json:"connectorId"`
+	Status          core.ChargePointStatus    `json:"status"`
+	ErrorCode       core.ChargePointErrorCode `json:"errorCode"`
+	Info            string                    `json:"info,omitempty"`
+	VendorId        string                    `json:"vendorId,omitempty"`
+	VendorErrorCode string                    `json:"vendorErrorCode,omitempty"`
+	Timestamp       string                    `json:"timestamp,omitempty"` // Time of the status reported by charger
+	Updated         string                    `json:"updated"`             // Time when status is received by server
+}
+
+/****************************************************************************************
+ *	Struct 	: ConnectorStates
+ *
+ * 	Purpose : Struct handles states of all connectors of the charger.
+ *			  Connector 0 is the Charge Point main controller
+ *
+*****************************************************************************************/
+type ConnectorStates struct {
+	connectors map[int]Connector
+	statesMux  sync.Mutex
+}
+
+/****************************************************************************************
+ *
+ * Function : ConnectorStatesConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the ConnectorStates
+ *
+ *	  Input : Nothing
+ *
+ *	Return : *ConnectorStates object
+ */
+func ConnectorStatesConstructor() *ConnectorStates {
+	connectorStates := ConnectorStates{}
+	connectorStates.connectors = make(map[int]Connector)
+	return &connectorStates
+}
+
+/****************************************************************************************
+ *
+ * Function : ConnectorStates::Update
+ *
+ *  Purpose : Update state of the connector by StatusNotification request.
+ *			  Not permitted transitions are stored too, as Central System has to accept
+ *			  reported status, but error is returned to log it
+ *
+ *    Input : statusNotification core.StatusNotificationRequestPayload - payload of the request
+ *
+ *   Return : error - when status or transition is not valid, nil otherwise
+ */
+func (connectorStates *ConnectorStates) Update(statusNotification core.StatusNotificationRequestPayload) error {
+	if statusNotification.ConnectorId < 0 {
+		return errors.New(fmt.Sprintf("Wrong connectorId '%v'", statusNotification.ConnectorId))
+	}
+
+	if !core.IsValidChargePointStatus(statusNotification.Status, statusNotification.ConnectorId) {
+		return errors.New(fmt.Sprintf("Status '%v' is not valid for connector '%v'", statusNotification.Status, statusNotification.ConnectorId))
+	}
+
+	// Lock the states before any changes
+	connectorStates.statesMux.Lock()
+	defer connectorStates.statesMux.Unlock()
+
+	previousStatus := connectorStates.connectors[statusNotification.ConnectorId].Status
+
+	connectorStates.connectors[statusNotification.ConnectorId] = Connector{
+		ConnectorId:     statusNotification.ConnectorId,
+		Status:          statusNotification.Status,
+		ErrorCode:       statusNotification.ErrorCode,
+		Info:            statusNotification.Info,
+		VendorId:        statusNotification.VendorId,
+		VendorErrorCode: statusNotification.VendorErrorCode,
+		Timestamp:       statusNotification.Timestamp,
+		Updated:         time.Now().Format("2006-01-02 15:04:05.000"),
+	}
+
+	if !core.IsValidStatusTransition(previousStatus, statusNotification.Status) {
+		return errors.New(fmt.Sprintf("Transition from '%v' to '%v' is not permitted for connector '%v'", previousStatus, statusNotification.Status, statusNotification.ConnectorId))
+	}
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : ConnectorStates::Get
+ *
+ *  Purpose : Get state of the connector
+ *
+ *    Input : connectorId int - id of the connector
+ *
+ *   Return : Connector
+ *			  bool - true when connector has reported status, false otherwise
+ */
+func (connectorStates *ConnectorStates) Get(connectorId int) (Connector, bool) {
+	connectorStates.statesMux.Lock()
+	defer connectorStates.statesMux.Unlock()
+
+	connector, isKeyPresent := connectorStates.connectors[connectorId]
+	return connector, isKeyPresent
+}
+
+/****************************************************************************************
+ *
+ * Function : ConnectorStates::List
+ *
+ *  Purpose : Get states of all connectors sorted by connectorId
+ *
+ *    Input : Nothing
+ *
+ *   Return : []Connector - list of the connectors
+ */
+func (connectorStates *ConnectorStates) List() []Connector {
+	connectorStates.statesMux.Lock()
+	defer connectorStates.statesMux.Unlock()
+
+	connectors := make([]Connector, 0, len(connectorStates.connectors))
+	for _, connector := range connectorStates.connectors {
+		connectors = append(connectors, connector)
+	}
+
+	sort.Slice(connectors, func(i, j int) bool {
+		return connectors[i].ConnectorId < connectors[j].ConnectorId
+	})
+
+	return connectors
+}
+
+/****************************************************************************************
+ *
+ * Function : ConnectorStates::MarshalJSON
+ *
+ *  Purpose : Marshal connectors as a list to be used in the API responses
+ *
+ *    Input : Nothing
+ *
+ *   Return : []byte - json format of the connectors list
+ *			  error - if happened, nil otherwise
+ */
+func (connectorStates *ConnectorStates) MarshalJSON() ([]byte, error) {
+	return json.Marshal(connectorStates.List())
+}
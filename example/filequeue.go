@@ -0,0 +1,502 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: filequeue.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: Persistent implementation of the MessageQueue. Every change is appended
+			 to the log file as json line, messages are restored from the log on start.
+			 Log is compacted to the current messages when it grows
+	=============================================================================
+*/
+
+package example
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// Operations of the log records
+	FILE_QUEUE_OPERATION_PUT    string = "put"
+	FILE_QUEUE_OPERATION_DELETE string = "delete"
+	// Log is not compacted while number of records is less
+	FILE_QUEUE_COMPACT_MIN_RECORDS int = 1000
+)
+
+/****************************************************************************************
+ *	Struct 	: fileQueueRecord
+ *
+ * 	Purpose : Struct handles one change of the queue in the log file
+ *
+*****************************************************************************************/
+type fileQueueRecord struct {
+	Operation   string   `json:"Operation"`
+	ChargerName string   `json:"ChargerName"`
+	UniqueID    string   `json:"UniqueID"`
+	Message     *Message `json:"Message,omitempty"` // Empty for the delete operation
+}
+
+/****************************************************************************************
+ *	Struct 	: FileMessageQueue
+ *
+ * 	Purpose : File-backed implementation of the MessageQueue
+ *
+*****************************************************************************************/
+type FileMessageQueue struct {
+	FileName string
+	messages map[MessageKey]Message
+	policy   queuePolicy
+	file     *os.File // Log opened for appending, nil after Close
+	records  int      // Number of records in the log
+	queueMux sync.Mutex
+}
+
+/****************************************************************************************
+ *
+ * Function : FileMessageQueueConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the FileMessageQueue, messages are restored
+ *			  from the file when it exists. Unfinished messages are marked as error
+ *
+ *	  Input : fileName string - log file of the queue
+ *			  limits QueueLimits - limits of the queue, restored messages are not limited
+ *
+ *	Return : *FileMessageQueue object
+ *			 error - if file cannot be read or created
+ */
+func FileMessageQueueConstructor(fileName string, limits QueueLimits) (*FileMessageQueue, error) {
+	if fileName == "" {
+		return nil, errors.New("Filename is empty")
+	}
+
+	queue := FileMessageQueue{}
+	queue.policy.limits = limits
+	queue.FileName = fileName
+	queue.messages = make(map[MessageKey]Message)
+
+	if err := queue.load(); err != nil {
+		return nil, err
+	}
+
+	// Exchange of the unfinished messages is lost with connections, they are
+	// finished to expire and to release the quota of the charger
+	for key, queuedMessage := range queue.messages {
+		if !queuedMessage.Status.IsFinished() {
+			message := queuedMessage
+			message.MarkError("interrupted by restart")
+			queue.messages[key] = updatedQueueMessage(queuedMessage, message)
+		}
+	}
+
+	// Start with the log of the current messages only
+	if err := queue.compact(); err != nil {
+		return nil, err
+	}
+
+	return &queue, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : FileMessageQueue::load
+ *
+ *  Purpose : Replay records of the log file. Incomplete last record, written
+ *			  when server is stopped in the middle of the write, is skipped
+ *
+ *	  Input : Nothing
+ *
+ *	 Return : error - if file cannot be read or has broken record, nil otherwise
+ */
+func (queue *FileMessageQueue) load() error {
+	file, err := os.Open(queue.FileName)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.New(fmt.Sprintf("Cannot open queue file '%v' with error '%v'", queue.FileName, err))
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		rawRecord, readErr := reader.ReadBytes('\n')
+		if readErr == io.EOF {
+			// Record without new line is not completed
+			return nil
+		}
+		if readErr != nil {
+			return errors.New(fmt.Sprintf("Cannot read queue file '%v' with error '%v'", queue.FileName, readErr))
+		}
+
+		record := fileQueueRecord{}
+		if err := json.Unmarshal(rawRecord, &record); err != nil {
+			return errors.New(fmt.Sprintf("Broken record on line %v of the queue file '%v': '%v'", line, queue.FileName, err))
+		}
+
+		switch record.Operation {
+		case FILE_QUEUE_OPERATION_PUT:
+			if record.Message == nil {
+				return errors.New(fmt.Sprintf("Record on line %v of the queue file '%v' has no message", line, queue.FileName))
+			}
+			queue.messages[record.Message.Key()] = *record.Message
+		case FILE_QUEUE_OPERATION_DELETE:
+			delete(queue.messages, MessageKey{ChargerName: record.ChargerName, UniqueID: record.UniqueID})
+		default:
+			return errors.New(fmt.Sprintf("Unknown operation '%v' on line %v of the queue file '%v'", record.Operation, line, queue.FileName))
+		}
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : FileMessageQueue::compact
+ *
+ *  Purpose : Rewrite log with current messages only. New log is written to the
+ *			  temporary file and replaces old one, so crash keeps one of them complete.
+ *			  Caller holds the lock
+ *
+ *	  Input : Nothing
+ *
+ *	 Return : error - if happened, nil otherwise
+ */
+func (queue *FileMessageQueue) compact() error {
+	tempFileName := queue.FileName + ".tmp"
+	tempFile, err := os.OpenFile(tempFileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Cannot create queue file '%v' with error '%v'", tempFileName, err))
+	}
+
+	writer := bufio.NewWriter(tempFile)
+	for _, message := range filterMessages(queue.messages, MessageFilter{}) {
+		message := message
+		rawRecord, _ := json.Marshal(fileQueueRecord{Operation: FILE_QUEUE_OPERATION_PUT, ChargerName: message.ChargerName, UniqueID: message.UniqueID, Message: &message})
+		writer.Write(append(rawRecord, '\n'))
+	}
+
+	err = writer.Flush()
+	if err == nil {
+		err = tempFile.Sync()
+	}
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempFileName, queue.FileName)
+	}
+	if err != nil {
+		os.Remove(tempFileName)
+		return errors.New(fmt.Sprintf("Cannot compact queue file '%v' with error '%v'", queue.FileName, err))
+	}
+
+	file, err := os.OpenFile(queue.FileName, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Cannot open queue file '%v' with error '%v'", queue.FileName, err))
+	}
+
+	if queue.file != nil {
+		queue.file.Close()
+	}
+	queue.file = file
+	queue.records = len(queue.messages)
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : FileMessageQueue::write
+ *
+ *  Purpose : Append record to the log. Caller holds the lock
+ *
+ *	  Input : record fileQueueRecord - change of the queue
+ *
+ *	 Return : error - if happened, nil otherwise
+ */
+func (queue *FileMessageQueue) write(record fileQueueRecord) error {
+	if queue.file == nil {
+		return errors.New("Queue file is closed")
+	}
+
+	rawRecord, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	// Record is written by one call to be appended as whole line
+	if _, err := queue.file.Write(append(rawRecord, '\n')); err != nil {
+		return errors.New(fmt.Sprintf("Cannot write queue file '%v' with error '%v'", queue.FileName, err))
+	}
+	queue.records++
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : FileMessageQueue::compactIfNeeded
+ *
+ *  Purpose : Compact log when it has more outdated records than messages.
+ *			  Caller holds the lock
+ *
+ *	  Input : Nothing
+ *
+ *	 Return : error - if happened, nil otherwise
+ */
+func (queue *FileMessageQueue) compactIfNeeded() error {
+	if queue.records < FILE_QUEUE_COMPACT_MIN_RECORDS || queue.records < 2*len(queue.messages) {
+		return nil
+	}
+
+	return queue.compact()
+}
+
+/****************************************************************************************
+ *
+ * Function : FileMessageQueue::put
+ *
+ *  Purpose : Write message to the log and to the queue. Caller holds the lock
+ *
+ *    Input : message Message - message with unique ID
+ *
+ *   Return : error - if happened, nil otherwise
+ *
+ */
+func (queue *FileMessageQueue) put(message Message) error {
+	if err := queue.write(fileQueueRecord{Operation: FILE_QUEUE_OPERATION_PUT, ChargerName: message.ChargerName, UniqueID: message.UniqueID, Message: &message}); err != nil {
+		return err
+	}
+	queue.messages[message.Key()] = message
+
+	return queue.compactIfNeeded()
+}
+
+/****************************************************************************************
+ *
+ * Function : FileMessageQueue::remove
+ *
+ *  Purpose : Write deletion of the message to the log and delete it from the queue.
+ *			  Caller holds the lock
+ *
+ *    Input : key MessageKey - charger name and unique ID of the message
+ *
+ *   Return : error - if happened, nil otherwise
+ *
+ */
+func (queue *FileMessageQueue) remove(key MessageKey) error {
+	if err := queue.write(fileQueueRecord{Operation: FILE_QUEUE_OPERATION_DELETE, ChargerName: key.ChargerName, UniqueID: key.UniqueID}); err != nil {
+		return err
+	}
+	delete(queue.messages, key)
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : FileMessageQueue::Add
+ *
+ *  Purpose : Add message to the queue
+ *
+ *    Input : uniqueID string - id of the message
+ *			  message Message - message to add
+ *
+ *   Return : error - if happened, nil otherwise
+ *
+ */
+func (queue *FileMessageQueue) Add(uniqueID string, message Message) error {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	message = newQueueMessage(uniqueID, message)
+
+	// Check limits of the queue, finished messages can be evicted to fit new one
+	evicted, err := queue.policy.admit(queue.messages, message)
+	if err != nil {
+		return err
+	}
+	for _, evictedKey := range evicted {
+		if err := queue.remove(evictedKey); err != nil {
+			return err
+		}
+	}
+
+	return queue.put(message)
+}
+
+/****************************************************************************************
+ *
+ * Function : FileMessageQueue::Update
+ *
+ *  Purpose : Update message of the charger in the queue by unique id
+ *
+ *    Input : chargerName string - name of the charger
+ *			  uniqueID string - id of the message
+ *            message Message - message obj to update in the queue
+ *
+ *   Return : error - if happened, nil otherwise
+ *
+ */
+func (queue *FileMessageQueue) Update(chargerName string, uniqueID string, message Message) error {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	queuedMessage, isKeyPresent := queue.messages[MessageKey{ChargerName: chargerName, UniqueID: uniqueID}]
+	if !isKeyPresent {
+		return errors.New("Update. Message with pointed uniqueID is not exists")
+	}
+
+	return queue.put(updatedQueueMessage(queuedMessage, message))
+}
+
+/****************************************************************************************
+ *
+ * Function : FileMessageQueue::Delete
+ *
+ *  Purpose : Delete message of the charger from the queue by unique id
+ *
+ *    Input : chargerName string - name of the charger
+ *			  uniqueID string - id of the message
+ *
+ *   Return : error - if happened, nil otherwise
+ *
+ */
+func (queue *FileMessageQueue) Delete(chargerName string, uniqueID string) error {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	key := MessageKey{ChargerName: chargerName, UniqueID: uniqueID}
+	if _, isKeyPresent := queue.messages[key]; !isKeyPresent {
+		return errors.New("Delete. Message with pointed uniqueID is not exists")
+	}
+
+	if err := queue.remove(key); err != nil {
+		return err
+	}
+
+	return queue.compactIfNeeded()
+}
+
+/****************************************************************************************
+ *
+ * Function : FileMessageQueue::Get
+ *
+ *  Purpose : Get message of the charger from the queue by unique id
+ *
+ *    Input : chargerName string - name of the charger
+ *			  uniqueID string - id of the message
+ *
+ *   Return : Message
+ *			  bool - true when message exists, false otherwise
+ *
+ */
+func (queue *FileMessageQueue) Get(chargerName string, uniqueID string) (Message, bool) {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	message, isKeyPresent := queue.messages[MessageKey{ChargerName: chargerName, UniqueID: uniqueID}]
+	return message, isKeyPresent
+}
+
+/****************************************************************************************
+ *
+ * Function : FileMessageQueue::List
+ *
+ *  Purpose : Get snapshot of the messages selected by the filter
+ *
+ *    Input : filter MessageFilter - parameters to select messages
+ *
+ *   Return : []Message - messages ordered by time of creation
+ *
+ */
+func (queue *FileMessageQueue) List(filter MessageFilter) []Message {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	return filterMessages(queue.messages, filter)
+}
+
+/****************************************************************************************
+ *
+ * Function : FileMessageQueue::Expire
+ *
+ *  Purpose : Delete finished messages which are not updated since pointed time
+ *
+ *    Input : updatedBefore time.Time - messages updated before it are deleted
+ *
+ *   Return : int - number of deleted messages
+ *			  error - if happened, nil otherwise
+ *
+ */
+func (queue *FileMessageQueue) Expire(updatedBefore time.Time) (int, error) {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	expired := 0
+	for key, message := range queue.messages {
+		if !queue.policy.isExpired(message, updatedBefore) {
+			continue
+		}
+
+		if err := queue.remove(key); err != nil {
+			queue.policy.expired += expired
+			return expired, err
+		}
+		expired++
+	}
+	queue.policy.expired += expired
+
+	return expired, queue.compactIfNeeded()
+}
+
+/****************************************************************************************
+ *
+ * Function : FileMessageQueue::Stats
+ *
+ *  Purpose : Get statistics of the queue
+ *
+ *    Input : Nothing
+ *
+ *   Return : QueueStats
+ *
+ */
+func (queue *FileMessageQueue) Stats() QueueStats {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	return queue.policy.stats(queue.messages)
+}
+
+/****************************************************************************************
+ *
+ * Function : FileMessageQueue::Close
+ *
+ *  Purpose : Flush and close the log file, queue cannot be changed after it
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if happened, nil otherwise
+ *
+ */
+func (queue *FileMessageQueue) Close() error {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	if queue.file == nil {
+		return nil
+	}
+
+	err := queue.file.Sync()
+	if closeErr := queue.file.Close(); err == nil {
+		err = closeErr
+	}
+	queue.file = nil
+
+	return err
+}
@@ -0,0 +1,295 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: fileregistry.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: ChargerRegistry persisted in the JSON file. File is rewritten on every
+			 change of the registry through the temporary file and rename,
+			 so it is never left half-written
+	=============================================================================
+*/
+
+package example
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+/****************************************************************************************
+ *	Struct 	: FileChargers
+ *
+ * 	Purpose : Struct handles list of the chargers in the file of the registry
+ *
+*****************************************************************************************/
+type FileChargers struct {
+	Chargers []ChargerFromFile `json:"Chargers"`
+}
+
+/****************************************************************************************
+ *	Struct 	: FileChargerRegistry
+ *
+ * 	Purpose : Struct handles chargers in memory and writes them to the file on change
+ *
+*****************************************************************************************/
+type FileChargerRegistry struct {
+	FileName string
+	memory   *MemoryChargerRegistry
+	fileMux  sync.Mutex // Serialises changes of the registry with writes of the file
+}
+
+// Make sure that FileChargerRegistry implements registry interface
+var _ ChargerRegistry = (*FileChargerRegistry)(nil)
+
+/****************************************************************************************
+ *
+ * Function : FileChargerRegistryConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the FileChargerRegistry. Chargers are loaded
+ *			  from the file, file is created with initial chargers when it does not exist
+ *
+ *	  Input : fileName string - file of the registry
+ *			  defaults ChargerDefaults - parameters of the configs for the created chargers
+ *			  initial []ChargerFromFile - chargers of the new file, as example from configs
+ *
+ *	Return : *FileChargerRegistry object
+ *			  error - if happened, nil otherwise
+ */
+func FileChargerRegistryConstructor(fileName string, defaults ChargerDefaults, initial []ChargerFromFile) (*FileChargerRegistry, error) {
+	registry := FileChargerRegistry{}
+	registry.FileName = fileName
+	registry.memory = MemoryChargerRegistryConstructor(defaults)
+
+	if fileName == "" {
+		return nil, errors.New("Filename is empty")
+	}
+
+	fileContentBytes, fileError := ioutil.ReadFile(fileName)
+	if os.IsNotExist(fileError) {
+		for _, settings := range initial {
+			if _, err := registry.memory.Create(settings); err != nil {
+				return nil, errors.New(fmt.Sprintf("Cannot add charger '%v' with error '%v'", settings.Name, err))
+			}
+		}
+		return &registry, registry.save()
+	}
+	if fileError != nil {
+		return nil, fileError
+	}
+
+	chargers := FileChargers{}
+	if err := json.Unmarshal(fileContentBytes, &chargers); err != nil {
+		return nil, errors.New(fmt.Sprintf("Cannot parse chargers file '%v' with error '%v'", fileName, err))
+	}
+
+	for _, settings := range chargers.Chargers {
+		if _, err := registry.memory.Create(settings); err != nil {
+			return nil, errors.New(fmt.Sprintf("Cannot add charger '%v' with error '%v'", settings.Name, err))
+		}
+	}
+
+	return &registry, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : FileChargerRegistry::Get
+ *
+ *  Purpose : Get charger by name
+ *
+ *    Input : chargerName string - name of the charger
+ *
+ *   Return : *Charger - charger object
+ *			  error - ErrChargerNotFound when charger is not registered
+ */
+func (registry *FileChargerRegistry) Get(chargerName string) (*Charger, error) {
+	return registry.memory.Get(chargerName)
+}
+
+/****************************************************************************************
+ *
+ * Function : FileChargerRegistry::List
+ *
+ *  Purpose : Get all chargers of the registry
+ *
+ *    Input : Nothing
+ *
+ *   Return : []*Charger - chargers sorted by name
+ */
+func (registry *FileChargerRegistry) List() []*Charger {
+	return registry.memory.List()
+}
+
+/****************************************************************************************
+ *
+ * Function : FileChargerRegistry::Create
+ *
+ *  Purpose : Add new charger to the registry and the file
+ *
+ *    Input : settings ChargerFromFile - settings of the charger
+ *
+ *   Return : *Charger - created charger
+ *			  error - ErrChargerExists, error of the settings or the file, nil otherwise
+ */
+func (registry *FileChargerRegistry) Create(settings ChargerFromFile) (*Charger, error) {
+	registry.fileMux.Lock()
+	defer registry.fileMux.Unlock()
+
+	charger, err := registry.memory.Create(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Registry is not changed when file cannot be written
+	if err := registry.save(); err != nil {
+		registry.memory.Delete(settings.Name)
+		return nil, err
+	}
+
+	return charger, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : FileChargerRegistry::Update
+ *
+ *  Purpose : Change settings of the registered charger and write them to the file
+ *
+ *    Input : settings ChargerFromFile - new settings of the charger
+ *
+ *   Return : *Charger - updated charger
+ *			  error - ErrChargerNotFound, error of the settings or the file, nil otherwise
+ */
+func (registry *FileChargerRegistry) Update(settings ChargerFromFile) (*Charger, error) {
+	registry.fileMux.Lock()
+	defer registry.fileMux.Unlock()
+
+	charger, err := registry.memory.Get(settings.Name)
+	if err != nil {
+		return nil, err
+	}
+	previousSettings := charger.Settings()
+
+	if _, err := registry.memory.Update(settings); err != nil {
+		return nil, err
+	}
+
+	if err := registry.save(); err != nil {
+		charger.ApplySettings(previousSettings)
+		return nil, err
+	}
+
+	return charger, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : FileChargerRegistry::SetAuthorization
+ *
+ *  Purpose : Change AuthToken of the registered charger and write it to the file
+ *
+ *    Input : chargerName string - name of the charger
+ *			  authToken string - hash of the password, empty - charger is not authenticated
+ *
+ *   Return : *Charger - updated charger
+ *			  error - ErrChargerNotFound, error of the settings or the file, nil otherwise
+ */
+func (registry *FileChargerRegistry) SetAuthorization(chargerName string, authToken string) (*Charger, error) {
+	registry.fileMux.Lock()
+	defer registry.fileMux.Unlock()
+
+	charger, err := registry.memory.Get(chargerName)
+	if err != nil {
+		return nil, err
+	}
+	previousSettings := charger.Settings()
+
+	if _, err := registry.memory.SetAuthorization(chargerName, authToken); err != nil {
+		return nil, err
+	}
+
+	if err := registry.save(); err != nil {
+		charger.ApplySettings(previousSettings)
+		return nil, err
+	}
+
+	return charger, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : FileChargerRegistry::Delete
+ *
+ *  Purpose : Remove charger from the registry and the file
+ *
+ *    Input : chargerName string - name of the charger
+ *
+ *   Return : *Charger - removed charger
+ *			  error - ErrChargerNotFound or error of the file, nil otherwise
+ */
+func (registry *FileChargerRegistry) Delete(chargerName string) (*Charger, error) {
+	registry.fileMux.Lock()
+	defer registry.fileMux.Unlock()
+
+	charger, err := registry.memory.Delete(chargerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := registry.save(); err != nil {
+		registry.memory.restore(charger)
+		return nil, err
+	}
+
+	return charger, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : FileChargerRegistry::save
+ *
+ *  Purpose : Write all chargers to the temporary file and rename it to the file
+ *			  of the registry. Caller holds the lock
+ *
+ *    Input : Nothing
+ *
+ *   Return : error - if happened, nil otherwise
+ */
+func (registry *FileChargerRegistry) save() error {
+	chargers := FileChargers{Chargers: []ChargerFromFile{}}
+	for _, charger := range registry.memory.List() {
+		chargers.Chargers = append(chargers.Chargers, charger.Settings())
+	}
+
+	fileContentBytes, err := json.MarshalIndent(chargers, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	tempFileName := registry.FileName + ".tmp"
+	tempFile, err := os.OpenFile(tempFileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Cannot create chargers file '%v' with error '%v'", tempFileName, err))
+	}
+
+	_, err = tempFile.Write(append(fileContentBytes, '\n'))
+	if err == nil {
+		err = tempFile.Sync()
+	}
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempFileName, registry.FileName)
+	}
+	if err != nil {
+		os.Remove(tempFileName)
+		return errors.New(fmt.Sprintf("Cannot write chargers file '%v' with error '%v'", registry.FileName, err))
+	}
+
+	return nil
+}
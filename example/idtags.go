@@ -0,0 +1,130 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: idtags.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: File-backed implementation of the IdTagAuthorizer
+			 List of identifiers is loaded from the file on start of the server
+	=============================================================================
+*/
+
+package example
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/CoderSergiy/ocpp16-go/core"
+	"io/ioutil"
+	"time"
+)
+
+/****************************************************************************************
+ *	Struct 	: IdTagFromFile
+ *
+ * 	Purpose : Struct handles identifier's parameters from the file
+ *
+*****************************************************************************************/
+type IdTagFromFile struct {
+	IdTag       string `json:"IdTag"`
+	Status      string `json:"Status"`
+	ExpiryDate  string `json:"ExpiryDate"`
+	ParentIdTag string `json:"ParentIdTag"`
+}
+
+/****************************************************************************************
+ *	Struct 	: FileIdTags
+ *
+ * 	Purpose : Struct handles list of identifiers from file
+ *
+*****************************************************************************************/
+type FileIdTags struct {
+	IdTags []IdTagFromFile `json:"IdTags"`
+}
+
+/****************************************************************************************
+ *	Struct 	: FileIdTagAuthorizer
+ *
+ * 	Purpose : Authorizes identifiers by the list loaded from file.
+ *			  Identifiers which are not in the list are Invalid
+ *
+*****************************************************************************************/
+type FileIdTagAuthorizer struct {
+	idTags map[string]core.IdTagInfo
+}
+
+// Make sure that FileIdTagAuthorizer implements authorizer interface
+var _ core.IdTagAuthorizer = (*FileIdTagAuthorizer)(nil)
+
+/****************************************************************************************
+ *
+ * Function : SetIdTagAuthorizerFromFile (Constructor)
+ *
+ *  Purpose : Creates a new instance of the FileIdTagAuthorizer from the file
+ *
+ *	  Input : fileName string - filename with list of identifiers
+ *
+ *	 Return : *FileIdTagAuthorizer object
+ * 			  error - error if happened
+ */
+func SetIdTagAuthorizerFromFile(fileName string) (*FileIdTagAuthorizer, error) {
+	authorizer := FileIdTagAuthorizer{}
+	authorizer.idTags = make(map[string]core.IdTagInfo)
+
+	// Check if filename is empty
+	if fileName == "" {
+		return &authorizer, errors.New("Filename is empty")
+	}
+
+	// Read file context to the buffer
+	fileContentBytes, fileError := ioutil.ReadFile(fileName)
+	if fileError != nil {
+		return &authorizer, fileError
+	}
+
+	// Unmarshal the content of the file to the FileIdTags struct
+	fileIdTags := FileIdTags{}
+	if err := json.Unmarshal(fileContentBytes, &fileIdTags); err != nil {
+		return &authorizer, err
+	}
+
+	for _, idTag := range fileIdTags.IdTags {
+		status := core.AuthorizationStatus(idTag.Status)
+		if status == "" {
+			status = core.AuthorizationStatusAccepted
+		}
+
+		authorizer.idTags[idTag.IdTag] = core.IdTagInfo{
+			ExpiryDate:  idTag.ExpiryDate,
+			ParentIdTag: idTag.ParentIdTag,
+			Status:      status,
+		}
+	}
+
+	return &authorizer, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : FileIdTagAuthorizer::Authorize
+ *
+ *  Purpose : Get status information of the identifier.
+ *			  Accepted identifier with expiry date in the past is Expired
+ *
+ *	  Input : idTag string - identifier to authorize
+ *
+ *	 Return : core.IdTagInfo - status information of the identifier
+ */
+func (authorizer *FileIdTagAuthorizer) Authorize(idTag string) core.IdTagInfo {
+	idTagInfo, isKeyPresent := authorizer.idTags[idTag]
+	if !isKeyPresent {
+		return core.IdTagInfo{Status: core.AuthorizationStatusInvalid}
+	}
+
+	if idTagInfo.Status == core.AuthorizationStatusAccepted && idTagInfo.ExpiryDate != "" {
+		if expiryDate, err := core.ParseDateTime(idTagInfo.ExpiryDate); err == nil && expiryDate.Before(time.Now()) {
+			idTagInfo.Status = core.AuthorizationStatusExpired
+		}
+	}
+
+	return idTagInfo
+}
@@ -0,0 +1,226 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: memoryregistry.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: In-memory implementation of the ChargerRegistry.
+			 Changes of the registry are lost on restart of the server
+	=============================================================================
+*/
+
+package example
+
+import (
+	"sort"
+	"sync"
+)
+
+/****************************************************************************************
+ *	Struct 	: MemoryChargerRegistry
+ *
+ * 	Purpose : Struct handles chargers in the map by name
+ *
+*****************************************************************************************/
+type MemoryChargerRegistry struct {
+	defaults    ChargerDefaults
+	chargers    map[string]*Charger
+	registryMux sync.RWMutex
+}
+
+// Make sure that MemoryChargerRegistry implements registry interface
+var _ ChargerRegistry = (*MemoryChargerRegistry)(nil)
+
+/****************************************************************************************
+ *
+ * Function : MemoryChargerRegistryConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the MemoryChargerRegistry
+ *
+ *	  Input : defaults ChargerDefaults - parameters of the configs for the created chargers
+ *
+ *	Return : *MemoryChargerRegistry object
+ */
+func MemoryChargerRegistryConstructor(defaults ChargerDefaults) *MemoryChargerRegistry {
+	registry := MemoryChargerRegistry{}
+	registry.defaults = defaults
+	registry.chargers = make(map[string]*Charger)
+	return &registry
+}
+
+/****************************************************************************************
+ *
+ * Function : MemoryChargerRegistry::Get
+ *
+ *  Purpose : Get charger by name
+ *
+ *    Input : chargerName string - name of the charger
+ *
+ *   Return : *Charger - charger object
+ *			  error - ErrChargerNotFound when charger is not registered
+ */
+func (registry *MemoryChargerRegistry) Get(chargerName string) (*Charger, error) {
+	registry.registryMux.RLock()
+	defer registry.registryMux.RUnlock()
+
+	if charger, isKeyPresent := registry.chargers[chargerName]; isKeyPresent {
+		return charger, nil
+	}
+
+	return nil, ErrChargerNotFound
+}
+
+/****************************************************************************************
+ *
+ * Function : MemoryChargerRegistry::List
+ *
+ *  Purpose : Get all chargers of the registry
+ *
+ *    Input : Nothing
+ *
+ *   Return : []*Charger - chargers sorted by name
+ */
+func (registry *MemoryChargerRegistry) List() []*Charger {
+	registry.registryMux.RLock()
+	defer registry.registryMux.RUnlock()
+
+	chargers := make([]*Charger, 0, len(registry.chargers))
+	for _, charger := range registry.chargers {
+		chargers = append(chargers, charger)
+	}
+
+	sort.Slice(chargers, func(i, j int) bool {
+		return chargers[i].Name < chargers[j].Name
+	})
+
+	return chargers
+}
+
+/****************************************************************************************
+ *
+ * Function : MemoryChargerRegistry::Create
+ *
+ *  Purpose : Add new charger to the registry
+ *
+ *    Input : settings ChargerFromFile - settings of the charger
+ *
+ *   Return : *Charger - created charger
+ *			  error - ErrChargerExists or error of the settings, nil otherwise
+ */
+func (registry *MemoryChargerRegistry) Create(settings ChargerFromFile) (*Charger, error) {
+	charger, err := newRegistryCharger(settings, registry.defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	registry.registryMux.Lock()
+	defer registry.registryMux.Unlock()
+
+	if _, isKeyPresent := registry.chargers[charger.Name]; isKeyPresent {
+		return nil, ErrChargerExists
+	}
+
+	registry.chargers[charger.Name] = charger
+	return charger, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : MemoryChargerRegistry::Update
+ *
+ *  Purpose : Change settings of the registered charger, object of the charger is kept
+ *
+ *    Input : settings ChargerFromFile - new settings of the charger
+ *
+ *   Return : *Charger - updated charger
+ *			  error - ErrChargerNotFound or error of the settings, nil otherwise
+ */
+func (registry *MemoryChargerRegistry) Update(settings ChargerFromFile) (*Charger, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Changes of the charger are not interleaved
+	registry.registryMux.Lock()
+	defer registry.registryMux.Unlock()
+
+	charger, isKeyPresent := registry.chargers[settings.Name]
+	if !isKeyPresent {
+		return nil, ErrChargerNotFound
+	}
+
+	charger.ApplySettings(settings)
+	return charger, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : MemoryChargerRegistry::SetAuthorization
+ *
+ *  Purpose : Change AuthToken of the registered charger, other settings are read and
+ *			  kept under the lock of the registry
+ *
+ *    Input : chargerName string - name of the charger
+ *			  authToken string - hash of the password, empty - charger is not authenticated
+ *
+ *   Return : *Charger - updated charger
+ *			  error - ErrChargerNotFound or error of the settings, nil otherwise
+ */
+func (registry *MemoryChargerRegistry) SetAuthorization(chargerName string, authToken string) (*Charger, error) {
+	registry.registryMux.Lock()
+	defer registry.registryMux.Unlock()
+
+	charger, isKeyPresent := registry.chargers[chargerName]
+	if !isKeyPresent {
+		return nil, ErrChargerNotFound
+	}
+
+	settings := charger.Settings()
+	settings.Authorization = authToken
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	charger.ApplySettings(settings)
+	return charger, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : MemoryChargerRegistry::Delete
+ *
+ *  Purpose : Remove charger from the registry
+ *
+ *    Input : chargerName string - name of the charger
+ *
+ *   Return : *Charger - removed charger
+ *			  error - ErrChargerNotFound when charger is not registered
+ */
+func (registry *MemoryChargerRegistry) Delete(chargerName string) (*Charger, error) {
+	registry.registryMux.Lock()
+	defer registry.registryMux.Unlock()
+
+	charger, isKeyPresent := registry.chargers[chargerName]
+	if !isKeyPresent {
+		return nil, ErrChargerNotFound
+	}
+
+	delete(registry.chargers, chargerName)
+	return charger, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : MemoryChargerRegistry::restore
+ *
+ *  Purpose : Put removed charger back to the registry, object of the charger is kept
+ *
+ *    Input : charger *Charger - charger returned by Delete
+ *
+ *   Return : Nothing
+ */
+func (registry *MemoryChargerRegistry) restore(charger *Charger) {
+	registry.registryMux.Lock()
+	defer registry.registryMux.Unlock()
+
+	registry.chargers[charger.Name] = charger
+}
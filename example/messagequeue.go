@@ -0,0 +1,556 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: messagequeue.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: Interface of the messages' queue used by handlers, API and server.
+			 SimpleMessageQueue keeps messages in memory,
+			 FileMessageQueue keeps them in the file to survive restarts
+	=============================================================================
+*/
+
+package example
+
+import (
+	"errors"
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+	"sort"
+	"strings"
+	"time"
+)
+
+type QueueMessageType int
+
+const (
+	MESSAGE_TYPE_NEW       QueueMessageType = 1
+	MESSAGE_TYPE_SENT      QueueMessageType = 2
+	MESSAGE_TYPE_RECEIVED  QueueMessageType = 3
+	MESSAGE_TYPE_COMPLETED QueueMessageType = 4
+	MESSAGE_TYPE_ERROR     QueueMessageType = 5
+)
+
+const (
+	// Direction of the exchange, defined by the side sent the Call
+	MESSAGE_DIRECTION_INCOMING string = "Incoming" // Call of the charger
+	MESSAGE_DIRECTION_OUTGOING string = "Outgoing" // Call of the Central System
+)
+
+const (
+	// Policies for the message added to the full queue
+	QUEUE_EVICTION_POLICY_REJECT   string = "reject"         // Reject new message
+	QUEUE_EVICTION_POLICY_FINISHED string = "evict-finished" // Delete the oldest completed or failed messages to fit new one
+)
+
+/****************************************************************************************
+ *
+ * Function : QueueMessageType::String
+ *
+ *  Purpose : Get name of the message status
+ *
+ *    Input : Nothing
+ *
+ *   Return : string
+ */
+func (status QueueMessageType) String() string {
+	switch status {
+	case MESSAGE_TYPE_NEW:
+		return "New"
+	case MESSAGE_TYPE_SENT:
+		return "Sent"
+	case MESSAGE_TYPE_RECEIVED:
+		return "Received"
+	case MESSAGE_TYPE_COMPLETED:
+		return "Completed"
+	case MESSAGE_TYPE_ERROR:
+		return "Error"
+	}
+	return fmt.Sprintf("Unknown(%d)", int(status))
+}
+
+/****************************************************************************************
+ *
+ * Function : ParseQueueMessageType
+ *
+ *  Purpose : Get message status by name, as example "Completed"
+ *
+ *    Input : name string - name of the status, case is ignored
+ *
+ *   Return : QueueMessageType
+ *			  error - if status is not known, nil otherwise
+ */
+func ParseQueueMessageType(name string) (QueueMessageType, error) {
+	for status := MESSAGE_TYPE_NEW; status <= MESSAGE_TYPE_ERROR; status++ {
+		if strings.EqualFold(status.String(), name) {
+			return status, nil
+		}
+	}
+	return 0, errors.New(fmt.Sprintf("Unknown message status '%v'", name))
+}
+
+/****************************************************************************************
+ *
+ * Function : QueueMessageType::IsFinished
+ *
+ *  Purpose : Check if exchange of the message is finished, only such messages are
+ *			  expired and evicted
+ *
+ *    Input : Nothing
+ *
+ *   Return : bool - true for completed and failed messages
+ */
+func (status QueueMessageType) IsFinished() bool {
+	return status == MESSAGE_TYPE_COMPLETED || status == MESSAGE_TYPE_ERROR
+}
+
+/****************************************************************************************
+ *	Struct 	: Message
+ *
+ * 	Purpose : Struct handles message's parameters
+ *
+*****************************************************************************************/
+type Message struct {
+	UniqueID    string // Set by the queue on Add
+	ChargerName string // Charger which the message is exchanged with
+	Direction   string // Who sent the Call, MESSAGE_DIRECTION_INCOMING - charger
+	Action      string // Action of the message
+	Received    string // Message content
+	Status      QueueMessageType
+	Sent        string
+	Error       string         // Reason of the error status
+	Created     time.Time      // Set by the queue on Add when empty
+	Updated     time.Time      // Set by the queue on Update, TTL of the finished message counts from it
+	SentAt      time.Time      // Sent is written to the charger
+	AnsweredAt  time.Time      // Answer of the charger is received or response to the charger is sent
+	RoundTrip   time.Duration  // Time from sending to the answer, from receiving to the response for incoming Call
+	History     []StatusChange // Set by the queue on every change of the status
+}
+
+/****************************************************************************************
+ *	Struct 	: MessageKey
+ *
+ * 	Purpose : Key of the message in the queue. Chargers choose unique IDs
+ *			  independently, messages of different chargers with the same ID are kept apart
+ *
+*****************************************************************************************/
+type MessageKey struct {
+	ChargerName string
+	UniqueID    string
+}
+
+/****************************************************************************************
+ *	Struct 	: StatusChange
+ *
+ * 	Purpose : Struct handles change of the message status
+ *
+*****************************************************************************************/
+type StatusChange struct {
+	Status QueueMessageType
+	Time   time.Time
+}
+
+/****************************************************************************************
+ *
+ * Function : Message::Key
+ *
+ *  Purpose : Get key of the message in the queue
+ *
+ *    Input : Nothing
+ *
+ *   Return : MessageKey
+ */
+func (message Message) Key() MessageKey {
+	return MessageKey{ChargerName: message.ChargerName, UniqueID: message.UniqueID}
+}
+
+/****************************************************************************************
+ *
+ * Function : Message::MarkSent
+ *
+ *  Purpose : Update message after Sent is written to the charger. Outgoing Call waits
+ *			  for the answer, exchange of the incoming Call is finished by the response
+ *
+ *    Input : sentAt time.Time - time of the write
+ *
+ *   Return : Nothing
+ */
+func (message *Message) MarkSent(sentAt time.Time) {
+	message.SentAt = sentAt
+
+	if message.Direction == MESSAGE_DIRECTION_OUTGOING {
+		message.Status = MESSAGE_TYPE_SENT
+		return
+	}
+
+	message.AnsweredAt = sentAt
+	message.RoundTrip = sentAt.Sub(message.Created)
+	message.Status = MESSAGE_TYPE_COMPLETED
+
+	// Call of the charger is answered with CallError
+	if messageType, _, _ := messages.GetMessageTypeFromRaw(message.Sent); messageType == int(messages.MESSAGE_TYPE_CALL_ERROR) {
+		message.MarkError("Answered with CallError")
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : Message::MarkAnswered
+ *
+ *  Purpose : Keep answer of the charger to the outgoing Call. Status is set by
+ *			  the handler of the answer
+ *
+ *    Input : received string - CallResult or CallError of the charger
+ *			  answeredAt time.Time - time of the answer
+ *
+ *   Return : Nothing
+ */
+func (message *Message) MarkAnswered(received string, answeredAt time.Time) {
+	message.Received = received
+	message.AnsweredAt = answeredAt
+	if !message.SentAt.IsZero() {
+		message.RoundTrip = answeredAt.Sub(message.SentAt)
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : Message::MarkError
+ *
+ *  Purpose : Set error status of the message
+ *
+ *    Input : reason string - why exchange of the message is failed
+ *
+ *   Return : Nothing
+ */
+func (message *Message) MarkError(reason string) {
+	message.Status = MESSAGE_TYPE_ERROR
+	message.Error = reason
+}
+
+/****************************************************************************************
+ *	Interface : MessageQueue
+ *
+ * 	  Purpose : Interface to keep messages exchanged with the chargers by charger name
+ *				and unique ID. Add takes charger name from the message
+ *
+*****************************************************************************************/
+type MessageQueue interface {
+	Add(uniqueID string, message Message) error
+	Get(chargerName string, uniqueID string) (Message, bool)
+	Update(chargerName string, uniqueID string, message Message) error
+	Delete(chargerName string, uniqueID string) error
+	List(filter MessageFilter) []Message         // Messages ordered by time of creation
+	Expire(updatedBefore time.Time) (int, error) // Delete old finished messages, returns number of deleted
+	Stats() QueueStats
+}
+
+/****************************************************************************************
+ *	Struct 	: MessageFilter
+ *
+ * 	Purpose : Parameters to select messages from the queue.
+ *			  Empty strings, zero Status, From or To are not filtering
+ *
+*****************************************************************************************/
+type MessageFilter struct {
+	ChargerName string
+	Direction   string
+	Action      string
+	Status      QueueMessageType
+	From        time.Time // Messages created from the time
+	To          time.Time // Messages created till the time
+}
+
+/****************************************************************************************
+ *
+ * Function : MessageFilter::Match
+ *
+ *  Purpose : Check if message is selected by the filter
+ *
+ *    Input : message Message - message of the queue
+ *
+ *   Return : bool
+ */
+func (filter MessageFilter) Match(message Message) bool {
+	if filter.ChargerName != "" && message.ChargerName != filter.ChargerName {
+		return false
+	}
+	if filter.Direction != "" && message.Direction != filter.Direction {
+		return false
+	}
+	if filter.Action != "" && message.Action != filter.Action {
+		return false
+	}
+	if filter.Status != 0 && message.Status != filter.Status {
+		return false
+	}
+	if !filter.From.IsZero() && message.Created.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && message.Created.After(filter.To) {
+		return false
+	}
+	return true
+}
+
+/****************************************************************************************
+ *	Struct 	: QueueLimits
+ *
+ * 	Purpose : Struct handles limits of the queue, zero values - not limited
+ *
+*****************************************************************************************/
+type QueueLimits struct {
+	MaxSize        int    // Messages in the queue
+	MaxChargerSize int    // Messages of one charger in the queue
+	EvictionPolicy string // Handling of the message added to the full queue, empty - reject
+}
+
+/****************************************************************************************
+ *	Struct 	: QueueStats
+ *
+ * 	Purpose : Struct handles statistics of the queue
+ *
+*****************************************************************************************/
+type QueueStats struct {
+	Size           int            `json:"size"`
+	MaxSize        int            `json:"maxSize"`
+	MaxChargerSize int            `json:"maxChargerSize"`
+	EvictionPolicy string         `json:"evictionPolicy"`
+	Statuses       map[string]int `json:"statuses"` // Number of messages by status
+	Chargers       map[string]int `json:"chargers"` // Number of messages by charger
+	Added          int            `json:"added"`    // Counters since start of the server
+	Rejected       int            `json:"rejected"`
+	Evicted        int            `json:"evicted"`
+	Expired        int            `json:"expired"`
+}
+
+/****************************************************************************************
+ *	Struct 	: queuePolicy
+ *
+ * 	Purpose : Limits and counters shared by implementations of the MessageQueue.
+ *			  Methods are called under the lock of the queue
+ *
+*****************************************************************************************/
+type queuePolicy struct {
+	limits   QueueLimits
+	added    int
+	rejected int
+	evicted  int
+	expired  int
+}
+
+/****************************************************************************************
+ *
+ * Function : queuePolicy::admit
+ *
+ *  Purpose : Check if message fits limits of the queue. When queue is full
+ *			  and policy allows eviction, the oldest finished messages are chosen
+ *			  to be deleted, own messages of the charger first
+ *
+ *    Input : messages map[MessageKey]Message - messages of the queue
+ *			  message Message - message to add with unique ID
+ *
+ *   Return : []MessageKey - keys of the messages to delete before adding
+ *			  error - if message is rejected, nil otherwise
+ */
+func (policy *queuePolicy) admit(messages map[MessageKey]Message, message Message) ([]MessageKey, error) {
+	// Message of the charger with the same unique ID is replaced, size is not changed
+	if _, isKeyPresent := messages[message.Key()]; isKeyPresent {
+		policy.added++
+		return nil, nil
+	}
+
+	overSize, overChargerSize := 0, 0
+	if policy.limits.MaxSize > 0 {
+		overSize = len(messages) + 1 - policy.limits.MaxSize
+	}
+	if policy.limits.MaxChargerSize > 0 {
+		chargerSize := 0
+		for _, queuedMessage := range messages {
+			if queuedMessage.ChargerName == message.ChargerName {
+				chargerSize++
+			}
+		}
+		overChargerSize = chargerSize + 1 - policy.limits.MaxChargerSize
+	}
+
+	var evicted []MessageKey
+	if policy.limits.EvictionPolicy == QUEUE_EVICTION_POLICY_FINISHED && (overSize > 0 || overChargerSize > 0) {
+		finished := make([]Message, 0)
+		for _, queuedMessage := range messages {
+			if queuedMessage.Status.IsFinished() {
+				finished = append(finished, queuedMessage)
+			}
+		}
+		sort.Slice(finished, func(i, j int) bool {
+			return finished[i].Updated.Before(finished[j].Updated)
+		})
+
+		isEvicted := make(map[MessageKey]bool)
+		for _, queuedMessage := range finished {
+			if overChargerSize <= 0 {
+				break
+			}
+			if queuedMessage.ChargerName == message.ChargerName {
+				isEvicted[queuedMessage.Key()] = true
+				evicted = append(evicted, queuedMessage.Key())
+				overChargerSize--
+				overSize--
+			}
+		}
+		for _, queuedMessage := range finished {
+			if overSize <= 0 {
+				break
+			}
+			if !isEvicted[queuedMessage.Key()] {
+				evicted = append(evicted, queuedMessage.Key())
+				overSize--
+			}
+		}
+	}
+
+	if overChargerSize > 0 {
+		policy.rejected++
+		return nil, errors.New(fmt.Sprintf("Reached max queue size of the charger '%v'", message.ChargerName))
+	}
+	if overSize > 0 {
+		policy.rejected++
+		return nil, errors.New("Reached max queue size")
+	}
+
+	policy.added++
+	policy.evicted += len(evicted)
+	return evicted, nil
+}
+
+/****************************************************************************************
+ *
+ * Function : queuePolicy::isExpired
+ *
+ *  Purpose : Check if message is finished and not updated since pointed time
+ *
+ *    Input : message Message - message of the queue
+ *			  updatedBefore time.Time - time of expiry
+ *
+ *   Return : bool
+ */
+func (policy *queuePolicy) isExpired(message Message, updatedBefore time.Time) bool {
+	return message.Status.IsFinished() && message.Updated.Before(updatedBefore)
+}
+
+/****************************************************************************************
+ *
+ * Function : queuePolicy::stats
+ *
+ *  Purpose : Collect statistics of the queue
+ *
+ *    Input : messages map[MessageKey]Message - messages of the queue
+ *
+ *   Return : QueueStats
+ */
+func (policy *queuePolicy) stats(messages map[MessageKey]Message) QueueStats {
+	stats := QueueStats{
+		Size:           len(messages),
+		MaxSize:        policy.limits.MaxSize,
+		MaxChargerSize: policy.limits.MaxChargerSize,
+		EvictionPolicy: policy.limits.EvictionPolicy,
+		Statuses:       make(map[string]int),
+		Chargers:       make(map[string]int),
+		Added:          policy.added,
+		Rejected:       policy.rejected,
+		Evicted:        policy.evicted,
+		Expired:        policy.expired,
+	}
+
+	if stats.EvictionPolicy == "" {
+		stats.EvictionPolicy = QUEUE_EVICTION_POLICY_REJECT
+	}
+
+	for _, message := range messages {
+		stats.Statuses[message.Status.String()]++
+		if message.ChargerName != "" {
+			stats.Chargers[message.ChargerName]++
+		}
+	}
+
+	return stats
+}
+
+/****************************************************************************************
+ *
+ * Function : filterMessages
+ *
+ *  Purpose : Get copy of the messages selected by the filter ordered by time of creation
+ *
+ *    Input : messages map[MessageKey]Message - messages of the queue
+ *			  filter MessageFilter - parameters to select messages
+ *
+ *   Return : []Message
+ */
+func filterMessages(messages map[MessageKey]Message, filter MessageFilter) []Message {
+	list := make([]Message, 0)
+	for _, message := range messages {
+		if filter.Match(message) {
+			list = append(list, message)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Created.Equal(list[j].Created) {
+			return list[i].Created.Before(list[j].Created)
+		}
+		if list[i].UniqueID != list[j].UniqueID {
+			return list[i].UniqueID < list[j].UniqueID
+		}
+		return list[i].ChargerName < list[j].ChargerName
+	})
+
+	return list
+}
+
+/****************************************************************************************
+ *
+ * Function : newQueueMessage
+ *
+ *  Purpose : Prepare message to be added to the queue
+ *
+ *    Input : uniqueID string - id of the message
+ *			  message Message - message to add
+ *
+ *   Return : Message - with unique ID and time of creation
+ */
+func newQueueMessage(uniqueID string, message Message) Message {
+	message.UniqueID = uniqueID
+	if message.Created.IsZero() {
+		message.Created = time.Now().UTC()
+	}
+	message.Updated = message.Created
+	message.History = []StatusChange{{Status: message.Status, Time: message.Created}}
+	return message
+}
+
+/****************************************************************************************
+ *
+ * Function : updatedQueueMessage
+ *
+ *  Purpose : Prepare message to replace queued one, unique ID, charger and time
+ *			  of creation are kept from the queued message, change of the status
+ *			  is added to the history
+ *
+ *    Input : queuedMessage Message - message in the queue
+ *			  message Message - new values of the message
+ *
+ *   Return : Message
+ */
+func updatedQueueMessage(queuedMessage Message, message Message) Message {
+	message.UniqueID = queuedMessage.UniqueID
+	message.ChargerName = queuedMessage.ChargerName
+	message.Created = queuedMessage.Created
+	message.Updated = time.Now().UTC()
+
+	// History is copied, previous snapshots of the message keep own one
+	message.History = append([]StatusChange{}, queuedMessage.History...)
+	if message.Status != queuedMessage.Status {
+		message.History = append(message.History, StatusChange{Status: message.Status, Time: message.Updated})
+	}
+	return message
+}
@@ -0,0 +1,414 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: messagequeue_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: File with test cases for implementations of the MessageQueue
+	=============================================================================
+*/
+
+package example
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/****************************************************************************************
+ *
+ * Function : testMessageQueue
+ *
+ *  Purpose : Test methods of the MessageQueue, same for every implementation
+ *
+ *    Input : t *testing.T - test object
+ *			  queue MessageQueue - empty queue
+ *
+ *   Return : Nothing
+ */
+func testMessageQueue(t *testing.T, queue MessageQueue) {
+
+	oldMessage := Message{Action: "Heartbeat", Received: "[2,\"1\",\"Heartbeat\",{}]", Status: MESSAGE_TYPE_COMPLETED, Created: time.Now().Add(-time.Hour)}
+	if err := queue.Add("1", oldMessage); err != nil {
+		t.Fatal(fmt.Sprintf("Cannot add message with error '%v'", err))
+	}
+	if err := queue.Add("2", Message{Action: "Reset", Status: MESSAGE_TYPE_NEW}); err != nil {
+		t.Fatal(fmt.Sprintf("Cannot add message with error '%v'", err))
+	}
+
+	message, exists := queue.Get("", "2")
+	if !exists || message.UniqueID != "2" || message.Action != "Reset" || message.Created.IsZero() {
+		t.Error(fmt.Sprintf("Wrong message '%+v'", message))
+	}
+
+	// Update keeps unique ID and time of creation
+	created := message.Created
+	if err := queue.Update("", "2", Message{Action: "Reset", Status: MESSAGE_TYPE_COMPLETED}); err != nil {
+		t.Error(fmt.Sprintf("Cannot update message with error '%v'", err))
+	}
+	if message, _ := queue.Get("", "2"); message.Status != MESSAGE_TYPE_COMPLETED || !message.Created.Equal(created) {
+		t.Error(fmt.Sprintf("Message is not updated '%+v'", message))
+	}
+
+	if err := queue.Update("", "3", Message{}); err == nil {
+		t.Error("Message which is not in the queue is updated")
+	}
+
+	// Message with the same unique ID of other charger is kept apart
+	if err := queue.Add("2", Message{ChargerName: "CP001", Action: "Heartbeat", Status: MESSAGE_TYPE_RECEIVED}); err != nil {
+		t.Error(fmt.Sprintf("Cannot add message of other charger with error '%v'", err))
+	}
+	if message, exists := queue.Get("CP001", "2"); !exists || message.Action != "Heartbeat" {
+		t.Error(fmt.Sprintf("Wrong message of other charger '%+v'", message))
+	}
+	if message, exists := queue.Get("", "2"); !exists || message.Action != "Reset" {
+		t.Error(fmt.Sprintf("Wrong message of the charger '%+v'", message))
+	}
+	if err := queue.Delete("CP001", "2"); err != nil {
+		t.Error(fmt.Sprintf("Cannot delete message of other charger with error '%v'", err))
+	}
+	if _, exists := queue.Get("", "2"); !exists {
+		t.Error("Message is deleted with message of other charger")
+	}
+
+	if list := queue.List(MessageFilter{}); len(list) != 2 || list[0].UniqueID != "1" || list[1].UniqueID != "2" {
+		t.Error(fmt.Sprintf("Wrong list of messages '%+v'", list))
+	}
+
+	// Messages are selected by the filter
+	if list := queue.List(MessageFilter{Action: "Reset", Status: MESSAGE_TYPE_COMPLETED}); len(list) != 1 || list[0].UniqueID != "2" {
+		t.Error(fmt.Sprintf("Wrong filtered messages '%+v'", list))
+	}
+	if list := queue.List(MessageFilter{From: time.Now().Add(-time.Minute)}); len(list) != 1 || list[0].UniqueID != "2" {
+		t.Error(fmt.Sprintf("Wrong messages from the time '%+v'", list))
+	}
+	if list := queue.List(MessageFilter{ChargerName: "CP001"}); len(list) != 0 {
+		t.Error(fmt.Sprintf("Wrong messages of the charger '%+v'", list))
+	}
+
+	// Only finished messages are expired
+	queue.Add("3", Message{Action: "Reset", Status: MESSAGE_TYPE_NEW, Created: time.Now().Add(-time.Hour)})
+	if expired, err := queue.Expire(time.Now().Add(-time.Minute)); expired != 1 || err != nil {
+		t.Error(fmt.Sprintf("Expected 1 expired message, got %v with error '%v'", expired, err))
+	}
+	if _, exists := queue.Get("", "1"); exists {
+		t.Error("Expired message is in the queue")
+	}
+
+	if stats := queue.Stats(); stats.Size != 2 || stats.Expired != 1 || stats.Statuses["New"] != 1 || stats.Statuses["Completed"] != 1 {
+		t.Error(fmt.Sprintf("Wrong statistics '%+v'", stats))
+	}
+
+	for _, uniqueID := range []string{"2", "3"} {
+		if err := queue.Delete("", uniqueID); err != nil {
+			t.Error(fmt.Sprintf("Cannot delete message with error '%v'", err))
+		}
+	}
+	if err := queue.Delete("", "2"); err == nil {
+		t.Error("Deleted message is deleted again")
+	}
+	if list := queue.List(MessageFilter{}); len(list) != 0 {
+		t.Error(fmt.Sprintf("Queue is not empty '%+v'", list))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : testQueueLimits
+ *
+ *  Purpose : Test limits of the queue and eviction of the finished messages
+ *
+ *    Input : t *testing.T - test object
+ *			  constructor func(QueueLimits) MessageQueue - creates empty queue
+ *
+ *   Return : Nothing
+ */
+func testQueueLimits(t *testing.T, constructor func(QueueLimits) MessageQueue) {
+
+	// Full queue rejects new messages
+	queue := constructor(QueueLimits{MaxSize: 2, EvictionPolicy: QUEUE_EVICTION_POLICY_REJECT})
+	queue.Add("1", Message{ChargerName: "CP001", Status: MESSAGE_TYPE_COMPLETED})
+	queue.Add("2", Message{ChargerName: "CP001", Status: MESSAGE_TYPE_COMPLETED})
+	if err := queue.Add("3", Message{ChargerName: "CP001"}); err == nil {
+		t.Error("Message is added to the full queue")
+	}
+	// Message with the same unique ID replaces queued one
+	if err := queue.Add("2", Message{ChargerName: "CP001"}); err != nil {
+		t.Error(fmt.Sprintf("Cannot replace message with error '%v'", err))
+	}
+
+	// Oldest finished messages are evicted, own messages of the charger first
+	queue = constructor(QueueLimits{MaxSize: 4, MaxChargerSize: 2, EvictionPolicy: QUEUE_EVICTION_POLICY_FINISHED})
+	created := time.Now().Add(-time.Hour)
+	queue.Add("1", Message{ChargerName: "CP001", Status: MESSAGE_TYPE_COMPLETED, Created: created})
+	queue.Add("2", Message{ChargerName: "CP002", Status: MESSAGE_TYPE_COMPLETED, Created: created.Add(time.Second)})
+	queue.Add("3", Message{ChargerName: "CP002", Status: MESSAGE_TYPE_ERROR, Created: created.Add(2 * time.Second)})
+	queue.Add("4", Message{ChargerName: "CP001", Status: MESSAGE_TYPE_NEW, Created: created.Add(3 * time.Second)})
+
+	if err := queue.Add("5", Message{ChargerName: "CP002", Status: MESSAGE_TYPE_NEW}); err != nil {
+		t.Error(fmt.Sprintf("Cannot add message with error '%v'", err))
+	}
+	if _, exists := queue.Get("CP002", "2"); exists {
+		t.Error("Oldest finished message of the charger is not evicted")
+	}
+
+	if err := queue.Add("6", Message{ChargerName: "CP003", Status: MESSAGE_TYPE_NEW}); err != nil {
+		t.Error(fmt.Sprintf("Cannot add message with error '%v'", err))
+	}
+	if _, exists := queue.Get("CP001", "1"); exists {
+		t.Error("Oldest finished message is not evicted")
+	}
+
+	// Finished message of other charger is evicted, then only pending messages are left
+	if err := queue.Add("7", Message{ChargerName: "CP001", Status: MESSAGE_TYPE_NEW}); err != nil {
+		t.Error(fmt.Sprintf("Cannot add message with error '%v'", err))
+	}
+	if err := queue.Add("8", Message{ChargerName: "CP003", Status: MESSAGE_TYPE_NEW}); err == nil {
+		t.Error("Pending message is evicted")
+	}
+
+	stats := queue.Stats()
+	if stats.Size != 4 || stats.Added != 7 || stats.Evicted != 3 || stats.Rejected != 1 || stats.Chargers["CP002"] != 1 {
+		t.Error(fmt.Sprintf("Wrong statistics '%+v'", stats))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : testConcurrentAccess
+ *
+ *  Purpose : Test queue used by many goroutines at the same time, run with -race
+ *
+ *    Input : t *testing.T - test object
+ *			  queue MessageQueue - empty queue
+ *
+ *   Return : Nothing
+ */
+func testConcurrentAccess(t *testing.T, queue MessageQueue) {
+
+	var waitGroup sync.WaitGroup
+	for index := 0; index < 20; index++ {
+		waitGroup.Add(1)
+		go func(index int) {
+			defer waitGroup.Done()
+			uniqueID := fmt.Sprintf("%v", index)
+			chargerName := fmt.Sprintf("CP%03d", index%3)
+			queue.Add(uniqueID, Message{ChargerName: chargerName, Status: MESSAGE_TYPE_RECEIVED})
+			for step := 0; step < 20; step++ {
+				message, _ := queue.Get(chargerName, uniqueID)
+				message.Status = MESSAGE_TYPE_COMPLETED
+				queue.Update(chargerName, uniqueID, message)
+				queue.List(MessageFilter{ChargerName: chargerName})
+				queue.Stats()
+			}
+		}(index)
+	}
+	waitGroup.Wait()
+
+	if list := queue.List(MessageFilter{Status: MESSAGE_TYPE_COMPLETED}); len(list) != 20 {
+		t.Error(fmt.Sprintf("Expected 20 completed messages, got %v", len(list)))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestMessageLifecycle
+ *
+ *  Purpose : Test timestamps and history of the statuses for both directions of the exchange
+ *
+ *   Return : Nothing
+ */
+func TestMessageLifecycle(t *testing.T) {
+
+	queue := SimpleMessageQueueConstructor(QueueLimits{})
+	created := time.Now().UTC().Add(-time.Second)
+
+	// Call of the charger is completed by the response
+	queue.Add("1", Message{Direction: MESSAGE_DIRECTION_INCOMING, Status: MESSAGE_TYPE_RECEIVED, Created: created})
+	message, _ := queue.Get("", "1")
+	message.Sent = "[3,\"1\",{}]"
+	message.MarkSent(created.Add(10 * time.Millisecond))
+	queue.Update("", "1", message)
+
+	message, _ = queue.Get("", "1")
+	if message.Status != MESSAGE_TYPE_COMPLETED || message.RoundTrip != 10*time.Millisecond || len(message.History) != 2 {
+		t.Error(fmt.Sprintf("Wrong incoming message '%+v'", message))
+	}
+
+	// Call of the charger is answered with CallError
+	queue.Add("2", Message{Direction: MESSAGE_DIRECTION_INCOMING, Status: MESSAGE_TYPE_RECEIVED})
+	message, _ = queue.Get("", "2")
+	message.Sent = "[4,\"2\",\"NotImplemented\",\"\",{}]"
+	message.MarkSent(time.Now().UTC())
+	if message.Status != MESSAGE_TYPE_ERROR || message.Error == "" {
+		t.Error(fmt.Sprintf("CallError response is not marked as error '%+v'", message))
+	}
+
+	// Call of the Central System waits for the answer
+	queue.Add("3", Message{Direction: MESSAGE_DIRECTION_OUTGOING, Status: MESSAGE_TYPE_NEW, Created: created})
+	message, _ = queue.Get("", "3")
+	message.MarkSent(created.Add(time.Millisecond))
+	queue.Update("", "3", message)
+	sentMessage, _ := queue.Get("", "3")
+
+	sentMessage.MarkAnswered("[3,\"3\",{}]", created.Add(21*time.Millisecond))
+	sentMessage.Status = MESSAGE_TYPE_COMPLETED
+	queue.Update("", "3", sentMessage)
+
+	message, _ = queue.Get("", "3")
+	if message.Received != "[3,\"3\",{}]" || message.RoundTrip != 20*time.Millisecond || !message.AnsweredAt.Equal(created.Add(21*time.Millisecond)) {
+		t.Error(fmt.Sprintf("Wrong answered message '%+v'", message))
+	}
+
+	statuses := []QueueMessageType{}
+	for _, change := range message.History {
+		statuses = append(statuses, change.Status)
+	}
+	if fmt.Sprint(statuses) != fmt.Sprint([]QueueMessageType{MESSAGE_TYPE_NEW, MESSAGE_TYPE_SENT, MESSAGE_TYPE_COMPLETED}) {
+		t.Error(fmt.Sprintf("Wrong history of the statuses '%v'", statuses))
+	}
+
+	// Snapshot of the message keeps own history
+	if len(sentMessage.History) != 2 {
+		t.Error(fmt.Sprintf("History of the snapshot is changed '%v'", sentMessage.History))
+	}
+
+	if list := queue.List(MessageFilter{Direction: MESSAGE_DIRECTION_OUTGOING}); len(list) != 1 || list[0].UniqueID != "3" {
+		t.Error(fmt.Sprintf("Wrong outgoing messages '%+v'", list))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestParseQueueMessageType
+ *
+ *  Purpose : Test status of the message by name
+ *
+ *   Return : Nothing
+ */
+func TestParseQueueMessageType(t *testing.T) {
+
+	if status, err := ParseQueueMessageType("completed"); status != MESSAGE_TYPE_COMPLETED || err != nil {
+		t.Error(fmt.Sprintf("Expected completed status, got '%v' with error '%v'", status, err))
+	}
+
+	if _, err := ParseQueueMessageType("Done"); err == nil {
+		t.Error("Unknown status is parsed")
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestSimpleMessageQueue
+ *
+ *  Purpose : Test in-memory queue
+ *
+ *   Return : Nothing
+ */
+func TestSimpleMessageQueue(t *testing.T) {
+	testMessageQueue(t, SimpleMessageQueueConstructor(QueueLimits{}))
+	testConcurrentAccess(t, SimpleMessageQueueConstructor(QueueLimits{}))
+	testQueueLimits(t, func(limits QueueLimits) MessageQueue {
+		return SimpleMessageQueueConstructor(limits)
+	})
+}
+
+/****************************************************************************************
+ *
+ * Function : TestFileMessageQueue
+ *
+ *  Purpose : Test file-backed queue and restore of the messages after restart
+ *
+ *   Return : Nothing
+ */
+func TestFileMessageQueue(t *testing.T) {
+
+	fileName := filepath.Join(t.TempDir(), "queue.log")
+
+	testQueueLimits(t, func(limits QueueLimits) MessageQueue {
+		queue, err := FileMessageQueueConstructor(filepath.Join(t.TempDir(), "limits.log"), limits)
+		if err != nil {
+			t.Fatal(fmt.Sprintf("Cannot create queue with error '%v'", err))
+		}
+		return queue
+	})
+
+	concurrentQueue, err := FileMessageQueueConstructor(filepath.Join(t.TempDir(), "concurrent.log"), QueueLimits{})
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot create queue with error '%v'", err))
+	}
+	testConcurrentAccess(t, concurrentQueue)
+	concurrentQueue.Close()
+
+	queue, err := FileMessageQueueConstructor(fileName, QueueLimits{})
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot create queue with error '%v'", err))
+	}
+	testMessageQueue(t, queue)
+
+	queue.Add("4", Message{Action: "BootNotification", Status: MESSAGE_TYPE_RECEIVED})
+	queue.Add("5", Message{Action: "Heartbeat", Status: MESSAGE_TYPE_RECEIVED})
+	queue.Update("", "5", Message{Action: "Heartbeat", Status: MESSAGE_TYPE_COMPLETED, Sent: "[3,\"5\",{}]"})
+	queue.Delete("", "4")
+	if err := queue.Close(); err != nil {
+		t.Error(fmt.Sprintf("Cannot close queue with error '%v'", err))
+	}
+	if err := queue.Add("6", Message{}); err == nil {
+		t.Error("Message is added to the closed queue")
+	}
+
+	// Server is stopped in the middle of the write
+	file, _ := os.OpenFile(fileName, os.O_WRONLY|os.O_APPEND, 0600)
+	file.WriteString("{\"Operation\":\"put\",\"UniqueID\":\"7\"")
+	file.Close()
+
+	restoredQueue, err := FileMessageQueueConstructor(fileName, QueueLimits{})
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot restore queue with error '%v'", err))
+	}
+	defer restoredQueue.Close()
+
+	list := restoredQueue.List(MessageFilter{})
+	if len(list) != 1 || list[0].UniqueID != "5" || list[0].Status != MESSAGE_TYPE_COMPLETED || list[0].Sent != "[3,\"5\",{}]" {
+		t.Error(fmt.Sprintf("Wrong restored messages '%+v'", list))
+	}
+
+	// Log is compacted to the current messages on start
+	content, _ := ioutil.ReadFile(fileName)
+	if strings.Count(string(content), "\n") != 1 {
+		t.Error(fmt.Sprintf("Log is not compacted '%v'", string(content)))
+	}
+
+	// Unfinished message is interrupted by restart and can expire
+	restoredQueue.Add("8", Message{Direction: MESSAGE_DIRECTION_OUTGOING, Status: MESSAGE_TYPE_SENT})
+	// Message of other charger with the same unique ID is restored apart
+	restoredQueue.Add("8", Message{ChargerName: "CP001", Direction: MESSAGE_DIRECTION_INCOMING, Status: MESSAGE_TYPE_COMPLETED})
+	restoredQueue.Close()
+
+	reopenedQueue, err := FileMessageQueueConstructor(fileName, QueueLimits{})
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot reopen queue with error '%v'", err))
+	}
+	defer reopenedQueue.Close()
+
+	if message, _ := reopenedQueue.Get("", "8"); message.Status != MESSAGE_TYPE_ERROR || message.Error != "interrupted by restart" {
+		t.Error(fmt.Sprintf("Unfinished message is not interrupted '%+v'", message))
+	}
+	if message, _ := reopenedQueue.Get("CP001", "8"); message.Status != MESSAGE_TYPE_COMPLETED {
+		t.Error(fmt.Sprintf("Message of other charger is not restored '%+v'", message))
+	}
+	if expired, err := reopenedQueue.Expire(time.Now().UTC().Add(time.Second)); expired != 3 || err != nil {
+		t.Error(fmt.Sprintf("Expected 3 expired messages, got %v with error '%v'", expired, err))
+	}
+
+	// Broken log is not loaded silently
+	ioutil.WriteFile(fileName, []byte("broken\n"), 0600)
+	if _, err := FileMessageQueueConstructor(fileName, QueueLimits{}); err == nil {
+		t.Error("Broken queue file is loaded")
+	}
+}
@@ -0,0 +1,353 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: outboundqueue.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: Outbound queue of the charger. Holds messages waiting for the
+			 writing goroutine of the charger, responses to the charger are
+			 written before Calls of the Central System. Calls are kept while
+			 charger is offline and delivered when it connects again
+	=============================================================================
+*/
+
+package example
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrOutboundQueueFull = errors.New("Outbound queue of the charger is full")
+)
+
+type OutboundPriority int
+
+const (
+	OUTBOUND_PRIORITY_RESPONSE OutboundPriority = iota // Answers to the Calls of the charger
+	OUTBOUND_PRIORITY_CALL                             // Calls initiated by the Central System
+	outboundPrioritiesCount
+)
+
+/****************************************************************************************
+ *	Struct 	: OutboundMessage
+ *
+ * 	Purpose : Struct handles message waiting to be written to the charger.
+ *			  Message is written as it is, unique IDs of the chargers can match
+ *
+*****************************************************************************************/
+type OutboundMessage struct {
+	UniqueID string           // Unique ID of the message in the messages queue
+	Message  string           // Raw message to write
+	Priority OutboundPriority // Priority of the message
+}
+
+/****************************************************************************************
+ *	Struct 	: OutboundQueue
+ *
+ * 	Purpose : Struct handles messages waiting to be written to the charger
+ *
+*****************************************************************************************/
+type OutboundQueue struct {
+	MaxCalls int                                        // Accepted Calls which are not finished, 0 - not limited
+	entries  [outboundPrioritiesCount][]OutboundMessage // FIFO of the messages per priority
+	accepted map[string]bool                            // Calls accepted by AcceptCall
+	ready    chan struct{}                              // Signals writing goroutine about new entry
+	queueMux sync.Mutex
+}
+
+/****************************************************************************************
+ *
+ * Function : OutboundQueueConstructor (Constructor)
+ *
+ *  Purpose : Creates a new instance of the OutboundQueue
+ *
+ *	  Input : maxCalls int - max number of the accepted Calls, 0 - not limited
+ *
+ *	Return : *OutboundQueue object
+ */
+func OutboundQueueConstructor(maxCalls int) *OutboundQueue {
+	queue := OutboundQueue{}
+	queue.MaxCalls = maxCalls
+	queue.accepted = make(map[string]bool)
+	queue.ready = make(chan struct{}, 1)
+	return &queue
+}
+
+/****************************************************************************************
+ *
+ * Function : OutboundQueue::AcceptCall
+ *
+ *  Purpose : Reserve place for the Call of the Central System. Call is released
+ *			  by ReleaseCall when it is answered, failed or timed out
+ *
+ *    Input : uniqueID string - unique ID of the Call
+ *
+ *   Return : error - ErrOutboundQueueFull when MaxCalls are accepted already
+ */
+func (queue *OutboundQueue) AcceptCall(uniqueID string) error {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	if queue.MaxCalls > 0 && len(queue.accepted) >= queue.MaxCalls {
+		return ErrOutboundQueueFull
+	}
+
+	queue.accepted[uniqueID] = true
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : OutboundQueue::ReleaseCall
+ *
+ *  Purpose : Release place of the finished Call, Call is not written
+ *			  to the charger if it is still in the queue
+ *
+ *    Input : uniqueID string - unique ID of the Call
+ *
+ *   Return : Nothing
+ */
+func (queue *OutboundQueue) ReleaseCall(uniqueID string) {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	delete(queue.accepted, uniqueID)
+
+	calls := queue.entries[OUTBOUND_PRIORITY_CALL]
+	for index, call := range calls {
+		if call.UniqueID == uniqueID {
+			queue.entries[OUTBOUND_PRIORITY_CALL] = append(calls[:index:index], calls[index+1:]...)
+			return
+		}
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : OutboundQueue::Push
+ *
+ *  Purpose : Add message to the end of the priority FIFO and wake writing goroutine.
+ *			  Never blocks, limit of the Calls is checked by AcceptCall
+ *
+ *    Input : outboundMessage OutboundMessage - message with the priority
+ *
+ *   Return : Nothing
+ */
+func (queue *OutboundQueue) Push(outboundMessage OutboundMessage) {
+	queue.queueMux.Lock()
+	queue.entries[outboundMessage.Priority] = append(queue.entries[outboundMessage.Priority], outboundMessage)
+	queue.queueMux.Unlock()
+
+	select {
+	case queue.ready <- struct{}{}:
+	default:
+		// Writing goroutine is signalled already
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : OutboundQueue::Pop
+ *
+ *  Purpose : Take next message to write, responses are taken before Calls
+ *
+ *    Input : Nothing
+ *
+ *   Return : OutboundMessage
+ *			  bool - false when queue is empty
+ */
+func (queue *OutboundQueue) Pop() (OutboundMessage, bool) {
+	return queue.pop(OUTBOUND_PRIORITY_CALL)
+}
+
+/****************************************************************************************
+ *
+ * Function : OutboundQueue::PopResponse
+ *
+ *  Purpose : Take next response to write, Calls are kept in the queue
+ *
+ *    Input : Nothing
+ *
+ *   Return : OutboundMessage
+ *			  bool - false when there are no responses
+ */
+func (queue *OutboundQueue) PopResponse() (OutboundMessage, bool) {
+	return queue.pop(OUTBOUND_PRIORITY_RESPONSE)
+}
+
+/****************************************************************************************
+ *
+ * Function : OutboundQueue::DropResponses
+ *
+ *  Purpose : Remove responses which will not be answered on the closed connection.
+ *			  Calls stay in the queue till charger connects again
+ *
+ *    Input : Nothing
+ *
+ *   Return : []OutboundMessage - removed responses
+ */
+func (queue *OutboundQueue) DropResponses() []OutboundMessage {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	responses := queue.entries[OUTBOUND_PRIORITY_RESPONSE]
+	queue.entries[OUTBOUND_PRIORITY_RESPONSE] = nil
+	return responses
+}
+
+/****************************************************************************************
+ *
+ * Function : OutboundQueue::Ready
+ *
+ *  Purpose : Get channel which is signalled when message is pushed
+ *
+ *    Input : Nothing
+ *
+ *   Return : <-chan struct{}
+ */
+func (queue *OutboundQueue) Ready() <-chan struct{} {
+	return queue.ready
+}
+
+/****************************************************************************************
+ *
+ * Function : OutboundQueue::Len
+ *
+ *  Purpose : Get number of the messages waiting for the writing goroutine
+ *
+ *    Input : Nothing
+ *
+ *   Return : int
+ */
+func (queue *OutboundQueue) Len() int {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	size := 0
+	for _, entries := range queue.entries {
+		size += len(entries)
+	}
+	return size
+}
+
+/****************************************************************************************
+ *
+ * Function : OutboundQueue::AcceptedCalls
+ *
+ *  Purpose : Get number of the accepted Calls which are not finished
+ *
+ *    Input : Nothing
+ *
+ *   Return : int
+ */
+func (queue *OutboundQueue) AcceptedCalls() int {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	return len(queue.accepted)
+}
+
+/****************************************************************************************
+ *
+ * Function : OutboundQueue::pop
+ *
+ *  Purpose : Take first message of the highest priority up to the lowest one
+ *
+ *    Input : lowest OutboundPriority - lowest priority to take
+ *
+ *   Return : OutboundMessage
+ *			  bool - false when there are no messages
+ */
+func (queue *OutboundQueue) pop(lowest OutboundPriority) (OutboundMessage, bool) {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	for priority := OUTBOUND_PRIORITY_RESPONSE; priority <= lowest; priority++ {
+		if entries := queue.entries[priority]; len(entries) > 0 {
+			queue.entries[priority] = entries[1:]
+			return entries[0], true
+		}
+	}
+
+	return OutboundMessage{}, false
+}
+
+/****************************************************************************************
+ *
+ * Function : PushResponse
+ *
+ *  Purpose : Pass response to the Call of the charger to the writing goroutine of the
+ *			  charger. Response is stored in the message of the charger when handler
+ *			  does not use the queue
+ *
+ *    Input : queue MessageQueue - queue of the messages
+ *			  chargerObj *Charger - charger which sent the Call
+ *			  uniqueID string - unique ID of the Call
+ *			  response string - raw response
+ *
+ *   Return : Nothing
+ */
+func PushResponse(queue MessageQueue, chargerObj *Charger, uniqueID string, response string) {
+	if qMessage, exists := queue.Get(chargerObj.Name, uniqueID); exists && qMessage.Sent == "" {
+		qMessage.Sent = response
+		queue.Update(chargerObj.Name, uniqueID, qMessage)
+	}
+
+	chargerObj.Outbound.Push(OutboundMessage{UniqueID: uniqueID, Message: response, Priority: OUTBOUND_PRIORITY_RESPONSE})
+}
+
+/****************************************************************************************
+ *
+ * Function : WriteOutboundMessage
+ *
+ *  Purpose : Write message of the outbound queue to the charger. Status of the message
+ *			  of the charger in the messages queue is updated, outgoing Call is marked
+ *			  as sent and response to the Call of the charger completes the exchange.
+ *			  Message is passed back to the outbound queue when connection is replaced
+ *
+ *    Input : connection *Connection - websocket connection of the charger
+ *			  chargerObj *Charger - charger of the connection
+ *			  queue MessageQueue - queue of the messages
+ *			  outboundMessage OutboundMessage - message to write
+ *
+ *   Return : error - send error, connection is closed, nil otherwise
+ */
+func WriteOutboundMessage(connection *Connection, chargerObj *Charger, queue MessageQueue, outboundMessage OutboundMessage) error {
+	// Status is updated before the write, answer of the charger can be handled right after it
+	qMessage, exists := queue.Get(chargerObj.Name, outboundMessage.UniqueID)
+	queuedMessage := qMessage
+	if exists {
+		qMessage.MarkSent(time.Now().UTC())
+		queue.Update(chargerObj.Name, outboundMessage.UniqueID, qMessage)
+	}
+	// Call written to the connection is failed when connection is closed
+	isCall := outboundMessage.Priority == OUTBOUND_PRIORITY_CALL
+	if isCall {
+		chargerObj.Calls.Written(outboundMessage.UniqueID, true)
+	}
+
+	err := connection.Send(outboundMessage.Message)
+	if err == nil {
+		return nil
+	}
+
+	connection.Close(fmt.Sprintf("Send error '%v'", err))
+	if !chargerObj.IsConnection(connection) {
+		// Pass message to the connection which replaced this one
+		if exists {
+			queue.Update(chargerObj.Name, outboundMessage.UniqueID, queuedMessage)
+		}
+		if isCall {
+			chargerObj.Calls.Written(outboundMessage.UniqueID, false)
+		}
+		chargerObj.Outbound.Push(outboundMessage)
+	} else if exists {
+		qMessage.MarkError(fmt.Sprintf("Send error '%v'", err))
+		queue.Update(chargerObj.Name, outboundMessage.UniqueID, qMessage)
+	}
+
+	return err
+}
@@ -0,0 +1,326 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: outboundqueue_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/example
+	Purpose: File with test cases for the outbound queue of the charger
+	=============================================================================
+*/
+
+package example
+
+import (
+	"context"
+	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/core"
+	"github.com/CoderSergiy/ocpp16-go/messages"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/****************************************************************************************
+ *
+ * Function : TestOutboundQueue
+ *
+ *  Purpose : Test priorities, back-pressure and release of the Calls
+ *
+ *   Return : Nothing
+ */
+func TestOutboundQueue(t *testing.T) {
+
+	queue := OutboundQueueConstructor(2)
+
+	if err := queue.AcceptCall("call1"); err != nil {
+		t.Error(fmt.Sprintf("Cannot accept first Call with error '%v'", err))
+	}
+	if err := queue.AcceptCall("call2"); err != nil {
+		t.Error(fmt.Sprintf("Cannot accept second Call with error '%v'", err))
+	}
+	if err := queue.AcceptCall("call3"); err != ErrOutboundQueueFull {
+		t.Error(fmt.Sprintf("Expected ErrOutboundQueueFull, got '%v'", err))
+	}
+
+	// Push never blocks, writing goroutine is signalled once
+	queue.Push(OutboundMessage{UniqueID: "call1", Priority: OUTBOUND_PRIORITY_CALL})
+	queue.Push(OutboundMessage{UniqueID: "call2", Priority: OUTBOUND_PRIORITY_CALL})
+	queue.Push(OutboundMessage{UniqueID: "response1", Message: "[3,\"response1\",{}]", Priority: OUTBOUND_PRIORITY_RESPONSE})
+	queue.Push(OutboundMessage{UniqueID: "response2", Priority: OUTBOUND_PRIORITY_RESPONSE})
+	select {
+	case <-queue.Ready():
+	default:
+		t.Error("Writing goroutine is not signalled")
+	}
+	if queue.Len() != 4 {
+		t.Error(fmt.Sprintf("Expected 4 messages, got %v", queue.Len()))
+	}
+
+	// Released Call is not written
+	queue.ReleaseCall("call1")
+	if queue.AcceptedCalls() != 1 {
+		t.Error(fmt.Sprintf("Expected 1 accepted Call, got %v", queue.AcceptedCalls()))
+	}
+
+	if outboundMessage, _ := queue.Pop(); outboundMessage.UniqueID != "response1" || outboundMessage.Message != "[3,\"response1\",{}]" {
+		t.Error(fmt.Sprintf("Wrong first message '%+v'", outboundMessage))
+	}
+	expected := []string{"response2", "call2"}
+	for _, expectedID := range expected {
+		if outboundMessage, isQueued := queue.Pop(); !isQueued || outboundMessage.UniqueID != expectedID {
+			t.Error(fmt.Sprintf("Expected '%v', got '%v'", expectedID, outboundMessage.UniqueID))
+		}
+	}
+	if outboundMessage, isQueued := queue.Pop(); isQueued {
+		t.Error(fmt.Sprintf("Queue is not empty, got '%v'", outboundMessage.UniqueID))
+	}
+
+	// Calls are kept for the next connection, responses are dropped
+	queue.Push(OutboundMessage{UniqueID: "call2", Priority: OUTBOUND_PRIORITY_CALL})
+	queue.Push(OutboundMessage{UniqueID: "response3", Priority: OUTBOUND_PRIORITY_RESPONSE})
+	if dropped := queue.DropResponses(); len(dropped) != 1 || dropped[0].UniqueID != "response3" {
+		t.Error(fmt.Sprintf("Wrong dropped responses %v", dropped))
+	}
+	if _, isQueued := queue.PopResponse(); isQueued {
+		t.Error("Response is not dropped")
+	}
+	if outboundMessage, isQueued := queue.Pop(); !isQueued || outboundMessage.UniqueID != "call2" {
+		t.Error(fmt.Sprintf("Call is not kept, got '%v'", outboundMessage.UniqueID))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestOfflineCall
+ *
+ *  Purpose : Test Call to the offline charger and back-pressure of the Calls
+ *
+ *   Return : Nothing
+ */
+func TestOfflineCall(t *testing.T) {
+
+	configs := ServerConfigsConstructor()
+	configs.MaxOutboundCalls = 1
+	configs.Chargers = MemoryChargerRegistryConstructor(configs.ChargerDefaults())
+	charger, err := configs.Chargers.Create(ChargerFromFile{Name: "CP001"})
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot create charger with error '%v'", err))
+	}
+
+	queue := SimpleMessageQueueConstructor(QueueLimits{})
+	centralSystem := CentralSystemConstructor(&configs, queue)
+
+	if timeout := centralSystem.CallTimeout(charger); timeout != time.Duration(configs.CallTimeout+configs.OfflineCallTimeout)*time.Second {
+		t.Error(fmt.Sprintf("Wrong timeout of the offline Call %v", timeout))
+	}
+
+	callMessage, err := centralSystem.QueueCall(charger.Name, core.ACTION_CLEARCACHE, map[string]interface{}{})
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot queue Call to the offline charger with error '%v'", err))
+	}
+	if _, err := centralSystem.QueueCall(charger.Name, core.ACTION_CLEARCACHE, map[string]interface{}{}); err != ErrOutboundQueueFull {
+		t.Error(fmt.Sprintf("Expected ErrOutboundQueueFull, got '%v'", err))
+	}
+
+	waitResult := make(chan error, 1)
+	go func() {
+		_, err := centralSystem.WaitCall(context.Background(), charger.Name, callMessage)
+		waitResult <- err
+	}()
+
+	// Call waits in the outbound queue for the writing goroutine of the next connection
+	select {
+	case <-charger.Outbound.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("Call is not pushed to the outbound queue")
+	}
+	callMessageString, _ := callMessage.ToString()
+	if outboundMessage, isQueued := charger.Outbound.Pop(); !isQueued || outboundMessage.UniqueID != callMessage.UniqueID || outboundMessage.Message != callMessageString {
+		t.Fatal(fmt.Sprintf("Wrong Call in the outbound queue '%+v'", outboundMessage))
+	}
+
+	charger.Calls.Resolve(messages.CreateCallResultMessage(callMessage.UniqueID, map[string]interface{}{"status": "Accepted"}))
+	if err := <-waitResult; err != nil {
+		t.Error(fmt.Sprintf("Call is failed with error '%v'", err))
+	}
+
+	// Place of the answered Call is released
+	if charger.Outbound.AcceptedCalls() != 0 {
+		t.Error(fmt.Sprintf("Call is not released, accepted %v", charger.Outbound.AcceptedCalls()))
+	}
+	if qMessage, _ := queue.Get(charger.Name, callMessage.UniqueID); qMessage.Status != MESSAGE_TYPE_COMPLETED {
+		t.Error(fmt.Sprintf("Wrong status of the Call '%v'", qMessage.Status))
+	}
+
+	// Call waiting in background is cancelled with the context of the Central System
+	ctx, cancel := context.WithCancel(context.Background())
+	centralSystem.Context = ctx
+	callMessage, err = centralSystem.QueueCall(charger.Name, core.ACTION_CLEARCACHE, map[string]interface{}{})
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot queue Call with error '%v'", err))
+	}
+	failed := make(chan error, 1)
+	centralSystem.WaitCallInBackground(charger.Name, callMessage, time.Minute, func(err error) {
+		failed <- err
+	})
+	cancel()
+	centralSystem.Wait()
+
+	if err := <-failed; err != context.Canceled {
+		t.Error(fmt.Sprintf("Expected cancelled Call, got '%v'", err))
+	}
+	if qMessage, _ := queue.Get(charger.Name, callMessage.UniqueID); qMessage.Status != MESSAGE_TYPE_ERROR {
+		t.Error(fmt.Sprintf("Wrong status of the cancelled Call '%v'", qMessage.Status))
+	}
+
+	// Call is rejected when offline buffering is disabled
+	configs.OfflineCallTimeout = 0
+	if _, err := centralSystem.QueueCall(charger.Name, core.ACTION_CLEARCACHE, map[string]interface{}{}); err == nil {
+		t.Error("Call to the offline charger is queued")
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestCallQueuedOnDisconnect
+ *
+ *  Purpose : Test that Call which is not written when charger is disconnected
+ *			  is delivered to the next connection
+ *
+ *   Return : Nothing
+ */
+func TestCallQueuedOnDisconnect(t *testing.T) {
+
+	configs := ServerConfigsConstructor()
+	charger, err := configs.Chargers.Create(ChargerFromFile{Name: "CP001"})
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot create charger with error '%v'", err))
+	}
+	queue := SimpleMessageQueueConstructor(QueueLimits{})
+	centralSystem := CentralSystemConstructor(&configs, queue)
+
+	server, connections := startTestServer(t, context.Background(), charger)
+	defer server.Close()
+	client := dialTestServer(t, server)
+	connection := <-connections
+
+	callMessage, err := centralSystem.QueueCall(charger.Name, core.ACTION_CLEARCACHE, map[string]interface{}{})
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot queue Call with error '%v'", err))
+	}
+	waitResult := make(chan error, 1)
+	go func() {
+		_, err := centralSystem.WaitCall(context.Background(), charger.Name, callMessage)
+		waitResult <- err
+	}()
+
+	select {
+	case <-charger.Outbound.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("Call is not pushed to the outbound queue")
+	}
+
+	// Charger is disconnected before writing goroutine takes the Call
+	client.Close()
+	<-connection.Done()
+	for deadline := time.Now().Add(time.Second); charger.IsConnected() && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if charger.IsConnected() {
+		t.Fatal("Charger is not disconnected")
+	}
+
+	select {
+	case err := <-waitResult:
+		t.Fatal(fmt.Sprintf("Queued Call is finished on disconnect with error '%v'", err))
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Writing goroutine of the next connection delivers the Call
+	client = dialTestServer(t, server)
+	defer client.Close()
+	connection = <-connections
+
+	outboundMessage, isQueued := charger.Outbound.Pop()
+	if !isQueued || outboundMessage.UniqueID != callMessage.UniqueID {
+		t.Fatal(fmt.Sprintf("Call is not kept in the outbound queue '%+v'", outboundMessage))
+	}
+	if err := WriteOutboundMessage(connection, charger, queue, outboundMessage); err != nil {
+		t.Fatal(fmt.Sprintf("Cannot write Call with error '%v'", err))
+	}
+
+	client.SetReadDeadline(time.Now().Add(time.Second))
+	if _, rawMessage, err := client.ReadMessage(); err != nil || !strings.Contains(string(rawMessage), callMessage.UniqueID) {
+		t.Fatal(fmt.Sprintf("Charger got wrong Call '%v' with error '%v'", string(rawMessage), err))
+	}
+
+	charger.Calls.Resolve(messages.CreateCallResultMessage(callMessage.UniqueID, map[string]interface{}{"status": "Accepted"}))
+	if err := <-waitResult; err != nil {
+		t.Error(fmt.Sprintf("Call is failed with error '%v'", err))
+	}
+	if qMessage, _ := queue.Get(charger.Name, callMessage.UniqueID); qMessage.Status != MESSAGE_TYPE_COMPLETED {
+		t.Error(fmt.Sprintf("Wrong status of the delivered Call '%v'", qMessage.Status))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestSameUniqueID
+ *
+ *  Purpose : Test chargers which send Calls with the same unique ID at the same time,
+ *			  every charger gets own response
+ *
+ *   Return : Nothing
+ */
+func TestSameUniqueID(t *testing.T) {
+
+	configs := ServerConfigsConstructor()
+	queue := SimpleMessageQueueConstructor(QueueLimits{})
+
+	var waitGroup sync.WaitGroup
+	for _, chargerName := range []string{"CP001", "CP002"} {
+		charger, err := configs.Chargers.Create(ChargerFromFile{Name: chargerName})
+		if err != nil {
+			t.Fatal(fmt.Sprintf("Cannot create charger with error '%v'", err))
+		}
+
+		server, connections := startTestServer(t, context.Background(), charger)
+		defer server.Close()
+		client := dialTestServer(t, server)
+		defer client.Close()
+		connection := <-connections
+
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+
+			// Reading goroutine of the charger, Call of the other charger is kept in the queue
+			queue.Add("1", Message{ChargerName: charger.Name, Direction: MESSAGE_DIRECTION_INCOMING, Status: MESSAGE_TYPE_RECEIVED})
+			PushResponse(queue, charger, "1", fmt.Sprintf("[3,\"1\",{\"charger\":\"%v\"}]", charger.Name))
+
+			// Writing goroutine of the charger
+			if outboundMessage, isQueued := charger.Outbound.Pop(); !isQueued {
+				t.Error(fmt.Sprintf("No response for the charger '%v'", charger.Name))
+			} else if err := WriteOutboundMessage(connection, charger, queue, outboundMessage); err != nil {
+				t.Error(fmt.Sprintf("Cannot write response with error '%v'", err))
+			}
+
+			client.SetReadDeadline(time.Now().Add(time.Second))
+			if _, rawMessage, err := client.ReadMessage(); err != nil || !strings.Contains(string(rawMessage), charger.Name) {
+				t.Error(fmt.Sprintf("Charger '%v' got wrong response '%v' with error '%v'", charger.Name, string(rawMessage), err))
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	// Message is kept for every charger, with own response
+	for _, chargerName := range []string{"CP001", "CP002"} {
+		message, exists := queue.Get(chargerName, "1")
+		if !exists || message.Status != MESSAGE_TYPE_COMPLETED || !strings.Contains(message.Sent, chargerName) {
+			t.Error(fmt.Sprintf("Wrong message of the charger '%v' in the queue '%+v'", chargerName, message))
+		}
+	}
+	if stats := queue.Stats(); stats.Size != 2 || stats.Rejected != 0 {
+		t.Error(fmt.Sprintf("Wrong statistics '%+v'", stats))
+	}
+}
@@ -14,42 +14,28 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/CoderSergiy/ocpp16-go/core"
 	"io/ioutil"
 	"sync"
+	"time"
 )
 
-type QueueMessageType int
-
 const (
-	MESSAGE_TYPE_NEW       QueueMessageType = 1
-	MESSAGE_TYPE_SENT      QueueMessageType = 2
-	MESSAGE_TYPE_RECEIVED  QueueMessageType = 3
-	MESSAGE_TYPE_COMPLETED QueueMessageType = 4
-	MESSAGE_TYPE_ERROR     QueueMessageType = 5
+	// Policies for the charger connecting while previous connection is open
+	RECONNECT_POLICY_REPLACE string = "replace"                    // Close previous connection
+	RECONNECT_POLICY_REJECT  string = "reject"                     // Reject new connection
+	RECONNECT_POLICY_PING    string = "replace-after-ping-failure" // Close previous connection if it does not answer ping
 )
 
-/****************************************************************************************
- *	Struct 	: Message
- *
- * 	Purpose : Struct handles message's parameters
- *
-*****************************************************************************************/
-type Message struct {
-	Action   string // Action of the message
-	Received string // Message content
-	Status   QueueMessageType
-	Sent     string
-}
-
 /****************************************************************************************
  *	Struct 	: SimpleMessageQueue
  *
- * 	Purpose : Struct handles messages queue routines
+ * 	Purpose : In-memory implementation of the MessageQueue
  *
 *****************************************************************************************/
 type SimpleMessageQueue struct {
-	MaxSize      int
-	MessageQueue map[string]Message
+	MessageQueue map[MessageKey]Message
+	policy       queuePolicy
 	queueMux     sync.Mutex
 }
 
@@ -59,14 +45,15 @@ type SimpleMessageQueue struct {
  *
  *  Purpose : Creates a new instance of the SimpleMessageQueue
  *
- *	  Input : Nothing
+ *	  Input : limits QueueLimits - limits of the queue
  *
- *	Return : SimpleMessageQueue object
+ *	Return : *SimpleMessageQueue object
  */
-func SimpleMessageQueueConstructor() SimpleMessageQueue {
+func SimpleMessageQueueConstructor(limits QueueLimits) *SimpleMessageQueue {
 	messageQueue := SimpleMessageQueue{}
 	messageQueue.init()
-	return messageQueue
+	messageQueue.policy.limits = limits
+	return &messageQueue
 }
 
 /****************************************************************************************
@@ -80,8 +67,7 @@ func SimpleMessageQueueConstructor() SimpleMessageQueue {
  *	 Return : Nothing
  */
 func (queue *SimpleMessageQueue) init() {
-	queue.MaxSize = 10
-	queue.MessageQueue = make(map[string]Message)
+	queue.MessageQueue = make(map[MessageKey]Message)
 }
 
 /****************************************************************************************
@@ -101,85 +87,99 @@ func (queue *SimpleMessageQueue) Add(uniqueID string, message Message) error {
 	queue.queueMux.Lock()
 	defer queue.queueMux.Unlock()
 
-	// Check if not reached the max size
-	if len(queue.MessageQueue) > queue.MaxSize {
-		return errors.New("Reached max queue size")
+	message = newQueueMessage(uniqueID, message)
+
+	// Check limits of the queue, finished messages can be evicted to fit new one
+	evicted, err := queue.policy.admit(queue.MessageQueue, message)
+	if err != nil {
+		return err
+	}
+	for _, evictedKey := range evicted {
+		delete(queue.MessageQueue, evictedKey)
 	}
 
 	// Add message to the queue
-	queue.MessageQueue[uniqueID] = message
+	queue.MessageQueue[message.Key()] = message
 
 	return nil
 }
 
 /****************************************************************************************
  *
- * Function : SimpleMessageQueue::DeleteByUniqueID
+ * Function : SimpleMessageQueue::Delete
  *
- *  Purpose : Delete message from the queue by unique id
+ *  Purpose : Delete message of the charger from the queue by unique id
  *
- *    Input : uniqueID string - id of the message
+ *    Input : chargerName string - name of the charger
+ *			  uniqueID string - id of the message
  *
  *   Return : error - if happened, nil otherwise
  *
  */
-func (queue *SimpleMessageQueue) DeleteByUniqueID(uniqueID string) error {
+func (queue *SimpleMessageQueue) Delete(chargerName string, uniqueID string) error {
 	// Lock the queue before any changes
 	queue.queueMux.Lock()
 	defer queue.queueMux.Unlock()
 
-	// If unique id exists in the queue - delete it
-	if _, isKeyPresent := queue.MessageQueue[uniqueID]; isKeyPresent {
-		delete(queue.MessageQueue, uniqueID)
+	// If unique id of the charger exists in the queue - delete it
+	key := MessageKey{ChargerName: chargerName, UniqueID: uniqueID}
+	if _, isKeyPresent := queue.MessageQueue[key]; isKeyPresent {
+		delete(queue.MessageQueue, key)
 		return nil
 	}
 
 	// Otherwise return an error
-	return errors.New("DeleteByUniqueID. Message with pointed uniqueID is not exists")
+	return errors.New("Delete. Message with pointed uniqueID is not exists")
 }
 
 /****************************************************************************************
  *
- * Function : SimpleMessageQueue::UpdateByUniqueID
+ * Function : SimpleMessageQueue::Update
  *
- *  Purpose : Update message in the queue by unique id
+ *  Purpose : Update message of the charger in the queue by unique id
  *
- *    Input : uniqueID string - id of the message
+ *    Input : chargerName string - name of the charger
+ *			  uniqueID string - id of the message
  *            message Message - message obj to update in the queue
  *
  *   Return : error - if happened, nil otherwise
  *
  */
-func (queue *SimpleMessageQueue) UpdateByUniqueID(uniqueID string, message Message) error {
+func (queue *SimpleMessageQueue) Update(chargerName string, uniqueID string, message Message) error {
 	// Lock the queue before any changes
 	queue.queueMux.Lock()
 	defer queue.queueMux.Unlock()
 
-	// If unique id exists in the queue - update it
-	if _, isKeyPresent := queue.MessageQueue[uniqueID]; isKeyPresent {
-		queue.MessageQueue[uniqueID] = message
+	// If unique id of the charger exists in the queue - update it
+	key := MessageKey{ChargerName: chargerName, UniqueID: uniqueID}
+	if queuedMessage, isKeyPresent := queue.MessageQueue[key]; isKeyPresent {
+		queue.MessageQueue[key] = updatedQueueMessage(queuedMessage, message)
 		return nil
 	}
 
 	// Otherwise return an error
-	return errors.New("UpdateByUniqueID. Message with pointed uniqueID is not exists")
+	return errors.New("Update. Message with pointed uniqueID is not exists")
 }
 
 /****************************************************************************************
  *
- * Function : SimpleMessageQueue::GetMessage
+ * Function : SimpleMessageQueue::Get
  *
- *  Purpose : Get message from the queue by unique id
+ *  Purpose : Get message of the charger from the queue by unique id
  *
- *    Input : uniqueID string - id of the message
+ *    Input : chargerName string - name of the charger
+ *			  uniqueID string - id of the message
  *
  *   Return : Message
  *			  bool - true when message exists, false otherwise
  *
  */
-func (queue *SimpleMessageQueue) GetMessage(uniqueID string) (Message, bool) {
-	// Check if uniqueID is exists in the queue
-	if message, isKeyPresent := queue.MessageQueue[uniqueID]; isKeyPresent {
+func (queue *SimpleMessageQueue) Get(chargerName string, uniqueID string) (Message, bool) {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	// Check if uniqueID of the charger is exists in the queue
+	if message, isKeyPresent := queue.MessageQueue[MessageKey{ChargerName: chargerName, UniqueID: uniqueID}]; isKeyPresent {
 		// Return message
 		return message, true
 	}
@@ -189,83 +189,81 @@ func (queue *SimpleMessageQueue) GetMessage(uniqueID string) (Message, bool) {
 
 /****************************************************************************************
  *
- * Function : SimpleMessageQueue::printStatus
+ * Function : SimpleMessageQueue::List
  *
- *  Purpose : Print parameters of the queue
+ *  Purpose : Get snapshot of the messages selected by the filter
  *
- *    Input : Nothing
+ *    Input : filter MessageFilter - parameters to select messages
  *
- *   Return : string - Parameters of the queue in the text format
+ *   Return : []Message - messages ordered by time of creation
  *
  */
-func (queue *SimpleMessageQueue) printStatus() string {
-	return fmt.Sprintf("Size of the queue is %v where max size set to %v", len(queue.MessageQueue), queue.MaxSize)
-}
+func (queue *SimpleMessageQueue) List(filter MessageFilter) []Message {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
 
-/****************************************************************************************
- *	Struct 	: Charger
- *
- * 	Purpose : Struct handles charger parameters in the gorutines
- *
-*****************************************************************************************/
-type Charger struct {
-	AuthToken          string
-	HeartBeatInterval  int
-	AuthConnection     bool
-	WebSocketConnected bool        `json:"Connected"`
-	InboundIP          string      `json:"RemoteIP"`
-	WriteChannel       chan string `json:"-"`
+	return filterMessages(queue.MessageQueue, filter)
 }
 
 /****************************************************************************************
  *
- * Function : ChargerConstructor (Constructor)
+ * Function : SimpleMessageQueue::Expire
  *
- *  Purpose : Creates a new instance of the Charger
+ *  Purpose : Delete finished messages which are not updated since pointed time
  *
- *	  Input : Nothing
+ *    Input : updatedBefore time.Time - messages updated before it are deleted
+ *
+ *   Return : int - number of deleted messages
+ *			  error - if happened, nil otherwise
  *
- *	Return : Charger object
  */
-func ChargerConstructor() Charger {
-	charger := Charger{}
-	charger.init()
-	return charger
+func (queue *SimpleMessageQueue) Expire(updatedBefore time.Time) (int, error) {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	expired := 0
+	for key, message := range queue.MessageQueue {
+		if queue.policy.isExpired(message, updatedBefore) {
+			delete(queue.MessageQueue, key)
+			expired++
+		}
+	}
+	queue.policy.expired += expired
+
+	return expired, nil
 }
 
 /****************************************************************************************
  *
- * Function : Charger::init
+ * Function : SimpleMessageQueue::Stats
  *
- *  Purpose : Initiate variables of the Charger structure
+ *  Purpose : Get statistics of the queue
  *
- *	  Input : Nothing
+ *    Input : Nothing
+ *
+ *   Return : QueueStats
  *
- *	 Return : Nothing
  */
-func (charger *Charger) init() {
-	charger.AuthToken = ""
-	charger.HeartBeatInterval = 300
-	charger.AuthConnection = false
-	charger.WebSocketConnected = false
-	charger.InboundIP = ""
-	charger.WriteChannel = make(chan string, 10) // Create channel with buffer 10 messages
+func (queue *SimpleMessageQueue) Stats() QueueStats {
+	queue.queueMux.Lock()
+	defer queue.queueMux.Unlock()
+
+	return queue.policy.stats(queue.MessageQueue)
 }
 
 /****************************************************************************************
  *
- * Function : Charger::Disconnected
+ * Function : SimpleMessageQueue::printStatus
  *
- *  Purpose : Clear Charger parameters when disconnected from socket
+ *  Purpose : Print parameters of the queue
  *
  *    Input : Nothing
  *
- *   Return : Nothing
+ *   Return : string - Parameters of the queue in the text format
+ *
  */
-func (charger *Charger) Disconnected() {
-	charger.AuthConnection = false
-	charger.WebSocketConnected = false
-	charger.InboundIP = ""
+func (queue *SimpleMessageQueue) printStatus() string {
+	return fmt.Sprintf("Size of the queue is %v where max size set to %v", len(queue.MessageQueue), queue.policy.limits.MaxSize)
 }
 
 /****************************************************************************************
@@ -275,8 +273,43 @@ func (charger *Charger) Disconnected() {
  *
 *****************************************************************************************/
 type Configs struct {
-	Chargers     map[string]*Charger `json:"Chargers"`
-	MaxQueueSize int                 `json:"MaxQueueSize"`
+	Chargers     ChargerRegistry `json:"-"`
+	ChargersFile string          `json:"ChargersFile"` // File of the registry, empty - chargers are kept in memory
+	MaxQueueSize int             `json:"MaxQueueSize"`
+	// Messages of one charger in the queue, 0 - not limited
+	MaxChargerQueueSize int      `json:"MaxChargerQueueSize"`
+	QueueEvictionPolicy string   `json:"QueueEvictionPolicy"` // Handling of the message added to the full queue
+	MessageTTL          int      `json:"MessageTTL"`          // Seconds to keep finished messages in the queue
+	QueueFile           string   `json:"QueueFile"`           // Log of the persistent queue, empty - queue is kept in memory
+	CallTimeout         int      `json:"CallTimeout"`         // Seconds to wait for the answer on Call
+	Subprotocols        []string `json:"Subprotocols"`        // Supported subprotocols in order of preference
+	// Calls of one charger waiting for the delivery or answer, 0 - not limited
+	MaxOutboundCalls int `json:"MaxOutboundCalls"`
+	// Seconds the Call to the offline charger waits for the connection, 0 - Call is rejected
+	OfflineCallTimeout int `json:"OfflineCallTimeout"`
+	// Handling of the charger connecting while previous connection is open
+	ReconnectPolicy      string `json:"ReconnectPolicy"`
+	ReconnectPingTimeout int    `json:"ReconnectPingTimeout"` // Seconds to wait for the pong of the previous connection
+	// Chargers without Authorization connect without credentials (Security Profile 0)
+	AllowUnauthenticated bool `json:"AllowUnauthenticated"`
+	// Default of the chargers, seconds between pings of the server, 0 - disabled
+	WebSocketPingInterval int        `json:"WebSocketPingInterval"`
+	PongTimeout           int        `json:"PongTimeout"`     // Seconds to wait for the pong after ping
+	ShutdownTimeout       int        `json:"ShutdownTimeout"` // Seconds to drain connections on server stop
+	TLS                   TLSConfigs `json:"TLS"`             // Empty CertFile - server listens without TLS
+}
+
+/****************************************************************************************
+ *	Struct 	: TLSConfigs
+ *
+ * 	Purpose : Struct handles certificates of the wss:// listener (Security Profiles 2 and 3)
+ *
+*****************************************************************************************/
+type TLSConfigs struct {
+	CertFile          string `json:"CertFile"`          // Server certificate in PEM format
+	KeyFile           string `json:"KeyFile"`           // Private key of the server certificate
+	ClientCAFile      string `json:"ClientCAFile"`      // CA bundle to verify client certificates of the chargers
+	RequireClientCert bool   `json:"RequireClientCert"` // Reject chargers without client certificate (Profile 3)
 }
 
 /****************************************************************************************
@@ -306,8 +339,55 @@ func ServerConfigsConstructor() Configs {
  *	 Return : Nothing
  */
 func (conf *Configs) init() {
-	conf.Chargers = make(map[string]*Charger)
 	conf.MaxQueueSize = 10
+	conf.QueueEvictionPolicy = QUEUE_EVICTION_POLICY_FINISHED
+	conf.MessageTTL = 300
+	conf.CallTimeout = 30
+	conf.MaxOutboundCalls = 10
+	conf.OfflineCallTimeout = 300
+	conf.Subprotocols = []string{core.SUBPROTOCOL_OCPP16}
+	conf.ReconnectPolicy = RECONNECT_POLICY_REPLACE
+	conf.ReconnectPingTimeout = 5
+	conf.WebSocketPingInterval = 60
+	conf.PongTimeout = 10
+	conf.ShutdownTimeout = 10
+	conf.Chargers = MemoryChargerRegistryConstructor(conf.ChargerDefaults())
+}
+
+/****************************************************************************************
+ *
+ * Function : Configs::QueueLimits
+ *
+ *  Purpose : Get limits of the messages queue from the configuration
+ *
+ *	  Input : Nothing
+ *
+ *	 Return : QueueLimits
+ */
+func (conf *Configs) QueueLimits() QueueLimits {
+	return QueueLimits{
+		MaxSize:        conf.MaxQueueSize,
+		MaxChargerSize: conf.MaxChargerQueueSize,
+		EvictionPolicy: conf.QueueEvictionPolicy,
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : Configs::ChargerDefaults
+ *
+ *  Purpose : Get parameters of the configuration applied to the created chargers
+ *
+ *	  Input : Nothing
+ *
+ *	 Return : ChargerDefaults
+ */
+func (conf *Configs) ChargerDefaults() ChargerDefaults {
+	return ChargerDefaults{
+		WebSocketPingInterval: conf.WebSocketPingInterval,
+		PongTimeout:           conf.PongTimeout,
+		MaxOutboundCalls:      conf.MaxOutboundCalls,
+	}
 }
 
 /****************************************************************************************
@@ -322,15 +402,34 @@ func (conf *Configs) init() {
  * 			  error - error if happened
  */
 func (conf *Configs) GetChargerObj(chargerName string) (*Charger, error) {
+	return conf.Chargers.Get(chargerName)
+}
 
-	if charger, isKeyPresent := conf.Chargers[chargerName]; isKeyPresent {
-		// Requested charger is exists in the configs
-		return charger, nil
+/****************************************************************************************
+ *
+ * Function : Configs::AcceptReconnect
+ *
+ *  Purpose : Check by ReconnectPolicy if new connection of the charger is accepted
+ *			  while charger is connected already
+ *
+ *	  Input : chargerObj *Charger - charger of the new connection
+ *
+ *	 Return : bool - false when new connection is rejected and previous one is kept
+ */
+func (conf *Configs) AcceptReconnect(chargerObj *Charger) bool {
+	if !chargerObj.IsConnected() {
+		return true
 	}
 
-	// Charger details is not exists in the configs
-	return nil, errors.New("Charger is not exists in configs")
+	switch conf.ReconnectPolicy {
+	case RECONNECT_POLICY_REJECT:
+		return false
+	case RECONNECT_POLICY_PING:
+		// Previous connection is kept while it is alive
+		return !chargerObj.PingConnection(time.Duration(conf.ReconnectPingTimeout) * time.Second)
+	}
 
+	return true
 }
 
 /****************************************************************************************
@@ -343,6 +442,8 @@ type ChargerFromFile struct {
 	Name              string `json:"Name"`
 	Authorization     string `json:"Authorization"`
 	HeartBeatInterval int    `json:"HeartBeatInterval"`
+	// Overrides WebSocketPingInterval of the configs, 0 - disabled for the charger
+	WebSocketPingInterval *int `json:"WebSocketPingInterval"`
 }
 
 /****************************************************************************************
@@ -352,8 +453,24 @@ type ChargerFromFile struct {
  *
 *****************************************************************************************/
 type FileConfigs struct {
-	Chargers     []ChargerFromFile `json:"Chargers"`
-	MaxQueueSize int               `json:"MaxQueueSize"`
+	Chargers              []ChargerFromFile `json:"Chargers"`
+	ChargersFile          string            `json:"ChargersFile"`
+	MaxQueueSize          int               `json:"MaxQueueSize"`
+	MaxChargerQueueSize   int               `json:"MaxChargerQueueSize"`
+	QueueEvictionPolicy   string            `json:"QueueEvictionPolicy"`
+	MessageTTL            int               `json:"MessageTTL"`
+	QueueFile             string            `json:"QueueFile"`
+	CallTimeout           int               `json:"CallTimeout"`
+	MaxOutboundCalls      int               `json:"MaxOutboundCalls"`
+	OfflineCallTimeout    *int              `json:"OfflineCallTimeout"`
+	Subprotocols          []string          `json:"Subprotocols"`
+	ReconnectPolicy       string            `json:"ReconnectPolicy"`
+	ReconnectPingTimeout  int               `json:"ReconnectPingTimeout"`
+	AllowUnauthenticated  bool              `json:"AllowUnauthenticated"`
+	WebSocketPingInterval *int              `json:"WebSocketPingInterval"`
+	PongTimeout           int               `json:"PongTimeout"`
+	ShutdownTimeout       int               `json:"ShutdownTimeout"`
+	TLS                   TLSConfigs        `json:"TLS"`
 }
 
 /****************************************************************************************
@@ -388,15 +505,76 @@ func SetConfigsFromFile(fileName string) (Configs, error) {
 		return configs, err
 	}
 
-	configs.MaxQueueSize = conf.MaxQueueSize
+	if conf.MaxQueueSize > 0 {
+		configs.MaxQueueSize = conf.MaxQueueSize
+	}
+	if conf.MaxChargerQueueSize > 0 {
+		configs.MaxChargerQueueSize = conf.MaxChargerQueueSize
+	}
+	switch conf.QueueEvictionPolicy {
+	case "":
+	case QUEUE_EVICTION_POLICY_REJECT, QUEUE_EVICTION_POLICY_FINISHED:
+		configs.QueueEvictionPolicy = conf.QueueEvictionPolicy
+	default:
+		return configs, errors.New(fmt.Sprintf("Unknown QueueEvictionPolicy '%v'", conf.QueueEvictionPolicy))
+	}
+	if conf.MessageTTL > 0 {
+		configs.MessageTTL = conf.MessageTTL
+	}
+	configs.QueueFile = conf.QueueFile
+	if conf.CallTimeout > 0 {
+		configs.CallTimeout = conf.CallTimeout
+	}
+	if conf.MaxOutboundCalls > 0 {
+		configs.MaxOutboundCalls = conf.MaxOutboundCalls
+	}
+	if conf.OfflineCallTimeout != nil {
+		configs.OfflineCallTimeout = *conf.OfflineCallTimeout
+	}
+	if len(conf.Subprotocols) > 0 {
+		configs.Subprotocols = conf.Subprotocols
+	}
+	configs.TLS = conf.TLS
+
+	switch conf.ReconnectPolicy {
+	case "":
+	case RECONNECT_POLICY_REPLACE, RECONNECT_POLICY_REJECT, RECONNECT_POLICY_PING:
+		configs.ReconnectPolicy = conf.ReconnectPolicy
+	default:
+		return configs, errors.New(fmt.Sprintf("Unknown ReconnectPolicy '%v'", conf.ReconnectPolicy))
+	}
+	if conf.ReconnectPingTimeout > 0 {
+		configs.ReconnectPingTimeout = conf.ReconnectPingTimeout
+	}
+	configs.AllowUnauthenticated = conf.AllowUnauthenticated
+	if conf.WebSocketPingInterval != nil {
+		configs.WebSocketPingInterval = *conf.WebSocketPingInterval
+	}
+	if conf.PongTimeout > 0 {
+		configs.PongTimeout = conf.PongTimeout
+	}
+	if conf.ShutdownTimeout > 0 {
+		configs.ShutdownTimeout = conf.ShutdownTimeout
+	}
+
+	// Chargers of the registry file are used instead of the configs when file exists
+	configs.ChargersFile = conf.ChargersFile
+	if configs.ChargersFile != "" {
+		registry, err := FileChargerRegistryConstructor(configs.ChargersFile, configs.ChargerDefaults(), conf.Chargers)
+		if err != nil {
+			return configs, err
+		}
+		configs.Chargers = registry
+		return configs, nil
+	}
 
+	registry := MemoryChargerRegistryConstructor(configs.ChargerDefaults())
 	for _, charger := range conf.Chargers {
-		chargerConf := ChargerConstructor()
-		chargerConf.AuthToken = charger.Authorization
-		chargerConf.HeartBeatInterval = charger.HeartBeatInterval
-
-		configs.Chargers[charger.Name] = &chargerConf
+		if _, err := registry.Create(charger); err != nil {
+			return configs, err
+		}
 	}
+	configs.Chargers = registry
 
 	return configs, nil
 }
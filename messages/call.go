@@ -57,16 +57,44 @@ func CallMessageConstructor() CallMessage {
  *	 Return : CallMessage object
  */
 func CreateCallMessageCreator(rawMessage string) CallMessage {
-	callMessageObj := CallMessage{}
+	// Load JSON from string and check JSON structure
+	callMessageObj, err := CallMessageParser(rawMessage)
+	if err != nil {
+		return CallMessage{}
+	}
+
+	return callMessageObj
+}
+
+/****************************************************************************************
+ *
+ * Function : CallMessageParser (Constructor)
+ *
+ *  Purpose : Creates a new instance of the CallMessage object using raw message
+ *			  and reports why message is not valid Call frame
+ *
+ *    Input : rawMessage string - raw message to parse and validate
+ *
+ *	 Return : CallMessage object
+ *			  error - when message is not valid Call frame, nil otherwise
+ */
+func CallMessageParser(rawMessage string) (CallMessage, error) {
+	callMessageObj := CallMessageConstructor()
 
 	// Load JSON from string and check JSON structure
 	if err := callMessageObj.unpackMessage(rawMessage); err != nil {
-		return CallMessage{}
+		return callMessageObj, err
 	}
 
-	// Validate
+	if callMessageObj.UniqueID == "" {
+		return callMessageObj, errors.New("UniqueID of the CallMessage is empty")
+	}
 
-	return callMessageObj
+	if callMessageObj.Action == "" {
+		return callMessageObj, errors.New("Action of the CallMessage is empty")
+	}
+
+	return callMessageObj, nil
 }
 
 /****************************************************************************************
@@ -160,6 +188,19 @@ func (callMessage *CallMessage) unpackMessage(rawMessage string) error {
 	return nil
 }
 
+/****************************************************************************************
+ *
+ * Function : CallMessage::Validate
+ *
+ *  Purpose : Validate payload of the Call by OCPP 1.6 schema of the action.
+ *			  Call with action without schema is not validated
+ *
+ *	 Return : error - *SchemaError when payload is not valid, nil otherwise
+ */
+func (callMessage *CallMessage) Validate() error {
+	return ValidateRequestPayload(callMessage.Action, callMessage.Payload)
+}
+
 /****************************************************************************************
  *
  * Function : CallMessage::ToString
@@ -170,6 +211,11 @@ func (callMessage *CallMessage) unpackMessage(rawMessage string) error {
  *			  error if happened, nil otherwise
  */
 func (callMessage *CallMessage) ToString() (string, error) {
+	// Charger must not get Call which is not valid by schema
+	if err := callMessage.Validate(); err != nil {
+		return "", err
+	}
+
 	messageType := int(MESSAGE_TYPE_CALL)
 	var parametersArray []interface{}
 	if callMessage.Signature == "" {
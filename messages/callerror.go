@@ -12,10 +12,27 @@ package messages
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 )
 
 const MESSAGE_TYPE_CALL_ERROR MessageType = 4
 
+type ErrorCode string
+
+const (
+	// Error codes of the CallError message defined by OCPP-J 1.6
+	ERROR_CODE_NOT_IMPLEMENTED                ErrorCode = "NotImplemented"               // Requested Action is not known by receiver
+	ERROR_CODE_NOT_SUPPORTED                  ErrorCode = "NotSupported"                 // Requested Action is recognized but not supported by the receiver
+	ERROR_CODE_INTERNAL_ERROR                 ErrorCode = "InternalError"                // An internal error occurred and the receiver was not able to process the requested Action
+	ERROR_CODE_PROTOCOL_ERROR                 ErrorCode = "ProtocolError"                // Payload for Action is incomplete
+	ERROR_CODE_SECURITY_ERROR                 ErrorCode = "SecurityError"                // During the processing of Action a security issue occurred
+	ERROR_CODE_FORMATION_VIOLATION            ErrorCode = "FormationViolation"           // Payload for Action is syntactically incorrect
+	ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION  ErrorCode = "PropertyConstraintViolation"  // Payload has a field with an invalid value
+	ERROR_CODE_OCCURENCE_CONSTRAINT_VIOLATION ErrorCode = "OccurenceConstraintViolation" // Payload has a field which violates occurence constraints
+	ERROR_CODE_TYPE_CONSTRAINT_VIOLATION      ErrorCode = "TypeConstraintViolation"      // Payload has a field which violates data type constraints
+	ERROR_CODE_GENERIC_ERROR                  ErrorCode = "GenericError"                 // Any other error not covered by the previous ones
+)
+
 /****************************************************************************************
  *	Struct 	: CallErrorMessage
  *
@@ -24,7 +41,7 @@ const MESSAGE_TYPE_CALL_ERROR MessageType = 4
 *****************************************************************************************/
 type CallErrorMessage struct {
 	UniqueID         string
-	ErrorCode        string
+	ErrorCode        ErrorCode
 	ErrorDescription string
 	ErrorDetails     map[string]interface{}
 }
@@ -83,23 +100,29 @@ func (cem *CallErrorMessage) init() {
 
 /****************************************************************************************
  *
- * Function : CallError::CreateCallError (Constructor)
+ * Function : CreateCallErrorMessage (Constructor)
+ *
+ *  Purpose : Creates a new instance of the CallErrorMessage object
  *
- *  Purpose : Creates a new instance of the CallMessage object
+ *    Input : uniqueID string - id of the Call message
+ *			  errorCode ErrorCode - code of the error
+ *			  errorDescription string - description of the error
+ *			  errorDetails map[string]interface{} - details of the error, can be nil
  *
- *	Return : nil is cannot unmarshal message, otherwise CallMessage object
+ *	 Return : CallErrorMessage
  */
-/*
-func CreateCallError (uniqueID string, errorCode string, errorDescription string, errorDetails map[string]interface{}) string {
-	ce := CallErrorConstructor()
-	ce.UniqueID = uniqueID
-	ce.ErrorCode = errorCode
-	ce.ErrorDescription = errorDescription
-	ce.ErrorDetails = errorDetails
-
-	return ce.createCallErrorMessage()
+func CreateCallErrorMessage(uniqueID string, errorCode ErrorCode, errorDescription string, errorDetails map[string]interface{}) CallErrorMessage {
+	callErrorObj := CallErrorMessageConstructor()
+	callErrorObj.UniqueID = uniqueID
+	callErrorObj.ErrorCode = errorCode
+	callErrorObj.ErrorDescription = errorDescription
+	if errorDetails != nil {
+		callErrorObj.ErrorDetails = errorDetails
+	}
+
+	return callErrorObj
 }
-*/
+
 /****************************************************************************************
  *
  * Function : CallErrorMessage::getMessageType
@@ -112,6 +135,18 @@ func (callErrorMessage CallErrorMessage) getMessageType() MessageType {
 	return MESSAGE_TYPE_CALL_ERROR
 }
 
+/****************************************************************************************
+ *
+ * Function : CallErrorMessage::Error
+ *
+ *  Purpose : Implement error interface to return CallError as an error
+ *
+ *	 Return : string - error code and description of the CallError
+ */
+func (callErrorMessage CallErrorMessage) Error() string {
+	return fmt.Sprintf("CallError '%v': '%v'", callErrorMessage.ErrorCode, callErrorMessage.ErrorDescription)
+}
+
 /****************************************************************************************
  *
  * Function : CallErrorMessage::unpackMessage
@@ -157,22 +192,19 @@ func (callErrorMessage *CallErrorMessage) unpackMessage(raw_message string) erro
 func (callErrorMessage *CallErrorMessage) ToString() (string, error) {
 
 	messageTypeID := MESSAGE_TYPE_CALL_ERROR
-	var parametersArray []interface{}
-	if len(callErrorMessage.ErrorDetails) > 0 {
-		parametersArray = []interface{}{
-			&messageTypeID,
-			&callErrorMessage.UniqueID,
-			&callErrorMessage.ErrorCode,
-			&callErrorMessage.ErrorDescription,
-			&callErrorMessage.ErrorDetails,
-		}
-	} else {
-		parametersArray = []interface{}{
-			&messageTypeID,
-			&callErrorMessage.UniqueID,
-			&callErrorMessage.ErrorCode,
-			&callErrorMessage.ErrorDescription,
-		}
+
+	// ErrorDetails is required by OCPP-J, empty object is sent when there are no details
+	errorDetails := callErrorMessage.ErrorDetails
+	if errorDetails == nil {
+		errorDetails = make(map[string]interface{})
+	}
+
+	parametersArray := []interface{}{
+		&messageTypeID,
+		&callErrorMessage.UniqueID,
+		&callErrorMessage.ErrorCode,
+		&callErrorMessage.ErrorDescription,
+		&errorDetails,
 	}
 
 	jsonResult, err := json.Marshal(parametersArray)
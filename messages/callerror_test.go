@@ -0,0 +1,45 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: callerror_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/messages
+	Purpose: File with test cases for CallErrorMessage
+	=============================================================================
+*/
+
+package messages
+
+import (
+	"fmt"
+	"testing"
+)
+
+/****************************************************************************************
+ *
+ * Function : TestCallErrorMessage
+ *
+ *  Purpose : Test that generated CallError always includes error details
+ *
+ *   Return : Nothing
+ */
+func TestCallErrorMessage(t *testing.T) {
+
+	callErrorMessage := CreateCallErrorMessage("19223201", ERROR_CODE_NOT_SUPPORTED, "Action is not supported", nil)
+
+	generatedMessage, err := callErrorMessage.ToString()
+	if err != nil {
+		t.Error(fmt.Sprintf("Error when generating message '%v'", err))
+		return
+	}
+
+	expected := "[4,\"19223201\",\"NotSupported\",\"Action is not supported\",{}]"
+	if generatedMessage != expected {
+		t.Error(fmt.Sprintf("Generated message '%v' is not matched expected '%v'", generatedMessage, expected))
+	}
+
+	// Parsed message keeps error code
+	parsedMessage := CallErrorMessageCreator(generatedMessage)
+	if parsedMessage.ErrorCode != ERROR_CODE_NOT_SUPPORTED || parsedMessage.UniqueID != "19223201" {
+		t.Error(fmt.Sprintf("Parsed message '%v' is not matched generated one", parsedMessage))
+	}
+}
@@ -0,0 +1,374 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: schema.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/messages
+	Purpose: Validation of the payloads by OCPP 1.6 JSON schemas.
+			 Schemas are embedded from the schemas folder, file names are
+			 {Action}.json for the request and {Action}Response.json for the response.
+			 Validator supports subset of draft-04 used by OCPP 1.6 schemas
+	=============================================================================
+*/
+
+package messages
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+var (
+	schemas     map[string]*schema
+	schemasErr  error
+	schemasOnce sync.Once
+)
+
+/****************************************************************************************
+ *	Struct 	: schema
+ *
+ * 	Purpose : Struct handles keywords of the JSON schema used by OCPP 1.6
+ *
+*****************************************************************************************/
+type schema struct {
+	Type                 string             `json:"type"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	MaxLength            *int               `json:"maxLength"`
+	Enum                 []string           `json:"enum"`
+	Format               string             `json:"format"`
+	Items                *schema            `json:"items"`
+	MultipleOf           *float64           `json:"multipleOf"`
+}
+
+/****************************************************************************************
+ *	Struct 	: SchemaError
+ *
+ * 	Purpose : Error of the payload validation with OCPP-J error code to reply by CallError
+ *
+*****************************************************************************************/
+type SchemaError struct {
+	ErrorCode   ErrorCode // Code of the CallError
+	Field       string    // Path to the field violated schema
+	Description string    // Violated constraint
+}
+
+/****************************************************************************************
+ *
+ * Function : SchemaError::Error
+ *
+ *  Purpose : Implement error interface
+ *
+ *	 Return : string - field and violated constraint
+ */
+func (schemaError *SchemaError) Error() string {
+	if schemaError.Field == "" {
+		return fmt.Sprintf("Payload %v", schemaError.Description)
+	}
+	return fmt.Sprintf("Field '%v' %v", schemaError.Field, schemaError.Description)
+}
+
+/****************************************************************************************
+ *
+ * Function : ValidateRequestPayload
+ *
+ *  Purpose : Validate payload of the Call by schema of the action
+ *
+ *    Input : action string - action of the Call
+ *			  payload map[string]interface{} - payload to validate
+ *
+ *	 Return : error - *SchemaError when payload is not valid, nil otherwise or if there is no schema for action
+ */
+func ValidateRequestPayload(action string, payload map[string]interface{}) error {
+	return validatePayload(action, payload)
+}
+
+/****************************************************************************************
+ *
+ * Function : ValidateResponsePayload
+ *
+ *  Purpose : Validate payload of the CallResult by schema of the action
+ *
+ *    Input : action string - action of the Call answered by CallResult
+ *			  payload map[string]interface{} - payload to validate
+ *
+ *	 Return : error - *SchemaError when payload is not valid, nil otherwise or if there is no schema for action
+ */
+func ValidateResponsePayload(action string, payload map[string]interface{}) error {
+	return validatePayload(action+"Response", payload)
+}
+
+/****************************************************************************************
+ *
+ * Function : validatePayload
+ *
+ *  Purpose : Validate payload by schema with name
+ *
+ *    Input : schemaName string - name of the schema file without extension
+ *			  payload map[string]interface{} - payload to validate
+ *
+ *	 Return : error - *SchemaError when payload is not valid, nil otherwise
+ */
+func validatePayload(schemaName string, payload map[string]interface{}) error {
+	schemasOnce.Do(loadSchemas)
+	if schemasErr != nil {
+		return schemasErr
+	}
+
+	payloadSchema, isKeyPresent := schemas[schemaName]
+	if !isKeyPresent {
+		return nil
+	}
+
+	// Payload built in code can keep Go types, bring it to the JSON types first
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(jsonPayload, &value); err != nil {
+		return err
+	}
+
+	// Payload is always an object, even if it is empty
+	if value == nil {
+		value = map[string]interface{}{}
+	}
+
+	return payloadSchema.validate(value, "")
+}
+
+/****************************************************************************************
+ *
+ * Function : loadSchemas
+ *
+ *  Purpose : Load all embedded schemas
+ *
+ *	 Return : Nothing
+ */
+func loadSchemas() {
+	schemas = make(map[string]*schema)
+
+	entries, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		schemasErr = err
+		return
+	}
+
+	for _, entry := range entries {
+		content, err := schemaFiles.ReadFile("schemas/" + entry.Name())
+		if err != nil {
+			schemasErr = err
+			return
+		}
+
+		fileSchema := schema{}
+		if err := json.Unmarshal(content, &fileSchema); err != nil {
+			schemasErr = errors.New(fmt.Sprintf("Cannot load schema '%v' with error '%v'", entry.Name(), err))
+			return
+		}
+
+		schemas[strings.TrimSuffix(entry.Name(), ".json")] = &fileSchema
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : schema::validate
+ *
+ *  Purpose : Validate value by the schema
+ *
+ *    Input : value interface{} - value decoded from JSON
+ *			  path string - path to the value in the payload
+ *
+ *	 Return : error - *SchemaError when value is not valid, nil otherwise
+ */
+func (s *schema) validate(value interface{}, path string) error {
+	switch s.Type {
+	case "object":
+		object, isObject := value.(map[string]interface{})
+		if !isObject {
+			return s.typeError(path)
+		}
+		return s.validateObject(object, path)
+
+	case "array":
+		array, isArray := value.([]interface{})
+		if !isArray {
+			return s.typeError(path)
+		}
+		if s.Items != nil {
+			for index, item := range array {
+				if err := s.Items.validate(item, fmt.Sprintf("%v[%v]", path, index)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+
+	case "string":
+		str, isString := value.(string)
+		if !isString {
+			return s.typeError(path)
+		}
+		return s.validateString(str, path)
+
+	case "integer":
+		number, isNumber := value.(float64)
+		if !isNumber || number != math.Trunc(number) {
+			return s.typeError(path)
+		}
+		return nil
+
+	case "number":
+		number, isNumber := value.(float64)
+		if !isNumber {
+			return s.typeError(path)
+		}
+		if s.MultipleOf != nil {
+			quotient := number / *s.MultipleOf
+			if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+				return &SchemaError{ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, path, fmt.Sprintf("must be multiple of %v", *s.MultipleOf)}
+			}
+		}
+		return nil
+
+	case "boolean":
+		if _, isBool := value.(bool); !isBool {
+			return s.typeError(path)
+		}
+		return nil
+	}
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : schema::validateObject
+ *
+ *  Purpose : Validate required, known and nested properties of the object
+ *
+ *    Input : object map[string]interface{} - object decoded from JSON
+ *			  path string - path to the object in the payload
+ *
+ *	 Return : error - *SchemaError when object is not valid, nil otherwise
+ */
+func (s *schema) validateObject(object map[string]interface{}, path string) error {
+	for _, name := range s.Required {
+		if _, isKeyPresent := object[name]; !isKeyPresent {
+			return &SchemaError{ERROR_CODE_OCCURENCE_CONSTRAINT_VIOLATION, joinPath(path, name), "is required"}
+		}
+	}
+
+	// Check fields in the same order to report the same violation every time
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := object[name]
+		propertySchema, isKeyPresent := s.Properties[name]
+		if !isKeyPresent {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				return &SchemaError{ERROR_CODE_FORMATION_VIOLATION, joinPath(path, name), "is not defined by schema"}
+			}
+			continue
+		}
+
+		if err := propertySchema.validate(value, joinPath(path, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : schema::validateString
+ *
+ *  Purpose : Validate length, enumeration and format of the string
+ *
+ *    Input : str string - value of the field
+ *			  path string - path to the field in the payload
+ *
+ *	 Return : error - *SchemaError when string is not valid, nil otherwise
+ */
+func (s *schema) validateString(str string, path string) error {
+	if s.MaxLength != nil && len(str) > *s.MaxLength {
+		return &SchemaError{ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, path, fmt.Sprintf("exceeds max length %v", *s.MaxLength)}
+	}
+
+	if len(s.Enum) > 0 {
+		isEnumValue := false
+		for _, enumValue := range s.Enum {
+			if str == enumValue {
+				isEnumValue = true
+				break
+			}
+		}
+		if !isEnumValue {
+			return &SchemaError{ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, path, fmt.Sprintf("has value '%v' which is not in '%v'", str, strings.Join(s.Enum, ", "))}
+		}
+	}
+
+	switch s.Format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+			return &SchemaError{ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, path, fmt.Sprintf("has value '%v' which is not date-time", str)}
+		}
+	case "uri":
+		if _, err := url.ParseRequestURI(str); err != nil {
+			return &SchemaError{ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION, path, fmt.Sprintf("has value '%v' which is not uri", str)}
+		}
+	}
+
+	return nil
+}
+
+/****************************************************************************************
+ *
+ * Function : schema::typeError
+ *
+ *  Purpose : Create error of the wrong type of the value
+ *
+ *    Input : path string - path to the value in the payload
+ *
+ *	 Return : *SchemaError
+ */
+func (s *schema) typeError(path string) *SchemaError {
+	return &SchemaError{ERROR_CODE_TYPE_CONSTRAINT_VIOLATION, path, fmt.Sprintf("must be '%v'", s.Type)}
+}
+
+/****************************************************************************************
+ *
+ * Function : joinPath
+ *
+ *  Purpose : Add name of the field to the path
+ *
+ *    Input : path string - path to the object
+ *			  name string - name of the field
+ *
+ *	 Return : string - path to the field
+ */
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
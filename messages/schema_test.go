@@ -0,0 +1,93 @@
+/*	==========================================================================
+	OCPP 1.6 Protocol
+	Filename: schema_test.go
+	Owner: Sergiy Safronov
+	Source : github.com/CoderSergiy/ocpp16-go/messages
+	Purpose: File with test cases for validation of the payloads by schemas
+	=============================================================================
+*/
+
+package messages
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+/****************************************************************************************
+ *
+ * Function : TestLoadSchemas
+ *
+ *  Purpose : Test that all embedded schemas are loaded
+ *
+ *   Return : Nothing
+ */
+func TestLoadSchemas(t *testing.T) {
+
+	if err := ValidateRequestPayload("Heartbeat", map[string]interface{}{}); err != nil {
+		t.Error(fmt.Sprintf("Unexpected error '%v'", err))
+		return
+	}
+
+	// Request and response schemas for each of 28 actions of OCPP 1.6
+	if len(schemas) != 56 {
+		t.Error(fmt.Sprintf("Loaded '%v' schemas instead of 56", len(schemas)))
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : TestValidatePayload
+ *
+ *  Purpose : Test that violated constraints are reported with correct error code
+ *
+ *   Return : Nothing
+ */
+func TestValidatePayload(t *testing.T) {
+
+	testCases := []struct {
+		action    string
+		response  bool
+		payload   string
+		errorCode ErrorCode
+	}{
+		{"BootNotification", false, `{"chargePointVendor":"VendorX","chargePointModel":"SingleSocketCharger"}`, ""},
+		{"BootNotification", false, `{"chargePointVendor":"VendorX"}`, ERROR_CODE_OCCURENCE_CONSTRAINT_VIOLATION},
+		{"BootNotification", false, `{"chargePointVendor":"VendorX","chargePointModel":"SingleSocketCharger","color":"red"}`, ERROR_CODE_FORMATION_VIOLATION},
+		{"BootNotification", true, `{"status":"Accepted","currentTime":"2022-07-01T10:00:00.000Z","interval":300}`, ""},
+		{"BootNotification", true, `{"status":"Accepted","currentTime":"2022-07-01 10:00:00.000","interval":300}`, ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION},
+		{"StartTransaction", false, `{"connectorId":"1","idTag":"TAG1","meterStart":0,"timestamp":"2022-07-01T10:00:00Z"}`, ERROR_CODE_TYPE_CONSTRAINT_VIOLATION},
+		{"StartTransaction", false, `{"connectorId":1.5,"idTag":"TAG1","meterStart":0,"timestamp":"2022-07-01T10:00:00Z"}`, ERROR_CODE_TYPE_CONSTRAINT_VIOLATION},
+		{"Authorize", false, `{"idTag":"TAG00010001000100010001"}`, ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION},
+		{"Reset", false, `{"type":"Warm"}`, ERROR_CODE_PROPERTY_CONSTRAINT_VIOLATION},
+		{"MeterValues", false, `{"connectorId":1,"meterValue":[{"timestamp":"2022-07-01T10:00:00Z","sampledValue":[{"value":"10","unit":"kWh"}]}]}`, ""},
+		{"MeterValues", false, `{"connectorId":1,"meterValue":[{"timestamp":"2022-07-01T10:00:00Z","sampledValue":[{"unit":"kWh"}]}]}`, ERROR_CODE_OCCURENCE_CONSTRAINT_VIOLATION},
+		{"GetConfiguration", true, `{"configurationKey":[{"key":"HeartbeatInterval","readonly":"no"}]}`, ERROR_CODE_TYPE_CONSTRAINT_VIOLATION},
+		{"UnknownAction", false, `{"any":"value"}`, ""},
+	}
+
+	for _, testCase := range testCases {
+		payload := make(map[string]interface{})
+		json.Unmarshal([]byte(testCase.payload), &payload)
+
+		var err error
+		if testCase.response {
+			err = ValidateResponsePayload(testCase.action, payload)
+		} else {
+			err = ValidateRequestPayload(testCase.action, payload)
+		}
+
+		if testCase.errorCode == "" {
+			if err != nil {
+				t.Error(fmt.Sprintf("Payload '%v' of '%v' must be valid, got '%v'", testCase.payload, testCase.action, err))
+			}
+			continue
+		}
+
+		schemaError, isSchemaError := err.(*SchemaError)
+		if !isSchemaError || schemaError.ErrorCode != testCase.errorCode {
+			t.Error(fmt.Sprintf("Payload '%v' of '%v' expected '%v', got '%v'", testCase.payload, testCase.action, testCase.errorCode, err))
+		}
+	}
+}
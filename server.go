@@ -10,12 +10,18 @@
 		1. messageStatusHandler
 		2. triggerActionHandler
 		3. wsChargerHandler
+		4. chargerStatusAPIHandler
+		5. meterValuesAPIHandler
+		6. commandAPIHandler
 	=============================================================================
 */
 
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/CoderSergiy/golib/logging"
 	"github.com/CoderSergiy/golib/timelib"
 	"github.com/CoderSergiy/golib/tools"
@@ -25,17 +31,33 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 )
 
 const (
 	configFilePath string = "/tmp/configs.json"
+	idTagsFilePath string = "/tmp/idtags.json"
 	logFilesPath   string = "/tmp/logs/server"
 )
 
 var (
 	log           logging.Log
 	ServerConfigs example.Configs
-	MQueue        example.SimpleMessageQueue
+	MQueue        example.MessageQueue
+	Transactions  core.TransactionStore
+	MeterValues   core.MeterValueStore
+	Authorizer    core.IdTagAuthorizer
+	CentralSystem example.CentralSystem
+	// Lifecycle of the websocket connections
+	connections                      sync.WaitGroup        // Reading and writing goroutines of the connections
+	connectionsMux                   sync.Mutex            // Guards isShuttingDown and connections.Add
+	isShuttingDown                   bool                  // New connections are rejected
+	shutdown                         = make(chan struct{}) // Closed on stop to drain connections
+	connectionsCtx, closeConnections = context.WithCancel(context.Background())
 )
 
 /****************************************************************************************
@@ -58,22 +80,232 @@ func main() {
 	}
 	ServerConfigs = configs
 	log.Info_Log("Set configs from file '%s'", configFilePath)
-	log.Info_Log("Uploaded '%v' chargers configurations", len(configs.Chargers))
-	log.Info_Log("Max queue size is %v", configs.MaxQueueSize)
+	log.Info_Log("Uploaded '%v' chargers configurations", len(configs.Chargers.List()))
+	log.Info_Log("Max queue size is %v, per charger %v, eviction policy '%v'", configs.MaxQueueSize, configs.MaxChargerQueueSize, configs.QueueEvictionPolicy)
 
-	// Init message queue
-	MQueue = example.SimpleMessageQueueConstructor()
+	// Set identifiers authorizer from file
+	authorizer, authorizerErr := example.SetIdTagAuthorizerFromFile(idTagsFilePath)
+	if authorizerErr != nil {
+		log.Error_Log("Cannot set idTags from file '%v' with error '%v'", idTagsFilePath, authorizerErr)
+		return
+	}
+	Authorizer = authorizer
+	log.Info_Log("Set idTags from file '%s'", idTagsFilePath)
+
+	// Init message queue, history of the messages is kept in the file when it is set
+	if ServerConfigs.QueueFile != "" {
+		fileQueue, queueErr := example.FileMessageQueueConstructor(ServerConfigs.QueueFile, ServerConfigs.QueueLimits())
+		if queueErr != nil {
+			log.Error_Log("Cannot open message queue with error '%v'", queueErr)
+			return
+		}
+		defer fileQueue.Close()
+		MQueue = fileQueue
+		log.Info_Log("Message queue is restored from file '%v' with %v messages", ServerConfigs.QueueFile, fileQueue.Stats().Size)
+	} else {
+		MQueue = example.SimpleMessageQueueConstructor(ServerConfigs.QueueLimits())
+	}
+	go expireMessages(time.Duration(ServerConfigs.MessageTTL) * time.Second)
+	// Init central system to send Calls to the chargers
+	CentralSystem = example.CentralSystemConstructor(&ServerConfigs, MQueue)
+	CentralSystem.Context = connectionsCtx
+	// Init transactions store
+	Transactions = core.MemoryTransactionStoreConstructor()
+	// Init meter values store
+	MeterValues = core.MemoryMeterValueStoreConstructor()
 
 	// Define http router
 	router := httprouter.New()
 	// Handle clients API requests
 	router.GET("/message/:messageReference/status", messageStatusAPIHandler)
+	router.GET("/messages", messagesAPIHandler)
+	router.GET("/queue/stats", queueStatsAPIHandler)
 	router.GET("/charger/:chargerName/status", chargerStatusAPIHandler)
+	router.GET("/charger/:chargerName/metervalues", meterValuesAPIHandler)
+	router.GET("/chargers", listChargersAPIHandler)
+	router.POST("/chargers", createChargerAPIHandler)
+	router.PUT("/charger/:chargerName", updateChargerAPIHandler)
+	router.DELETE("/charger/:chargerName", deleteChargerAPIHandler)
+	router.POST("/charger/:chargerName/authtoken", rotateAuthTokenAPIHandler)
 	router.POST("/command/:chargerName/triggeraction/:action", triggerActionAPIHandler)
+	router.POST("/command/:chargerName/remotestarttransaction", commandAPIHandler(core.ACTION_REMOTESTARTTRANSACTION))
+	router.POST("/command/:chargerName/remotestoptransaction", commandAPIHandler(core.ACTION_REMOTESTOPTRANSACTION))
+	router.POST("/command/:chargerName/reset", commandAPIHandler(core.ACTION_RESET))
+	router.POST("/command/:chargerName/unlockconnector", commandAPIHandler(core.ACTION_UNLOCKCONNECTOR))
+	router.POST("/command/:chargerName/changeavailability", commandAPIHandler(core.ACTION_CHANGEAVAILABILITY))
+	router.POST("/command/:chargerName/changeconfiguration", commandAPIHandler(core.ACTION_CHANGECONFIGURATION))
+	router.POST("/command/:chargerName/getconfiguration", commandAPIHandler(core.ACTION_GETCONFIGURATION))
+	router.POST("/command/:chargerName/clearcache", commandAPIHandler(core.ACTION_CLEARCACHE))
+	router.POST("/command/:chargerName/datatransfer", commandAPIHandler(core.ACTION_DATATRANSFER))
 	// Set router for the ocpp V1.6 (json) connection
 	router.GET("/ocppj/1.6/:chargerName", wsChargerHandler)
+	server := &http.Server{Addr: ":8080", Handler: router}
+
+	// Load certificates for the wss:// listener
+	if tlsConfigs := ServerConfigs.TLS; tlsConfigs.CertFile != "" {
+		certificates, certErr := core.CertificateStoreConstructor(tlsConfigs.CertFile, tlsConfigs.KeyFile, tlsConfigs.ClientCAFile, tlsConfigs.RequireClientCert)
+		if certErr != nil {
+			log.Error_Log("Cannot load certificates with error '%v'", certErr)
+			return
+		}
+		go reloadCertificates(certificates)
+
+		server.TLSConfig = certificates.TLSConfig()
+		log.Info_Log("Server listens with TLS, client certificates CA '%v'", tlsConfigs.ClientCAFile)
+	}
+
+	// Stop server by SIGTERM or Ctrl+C
+	stopCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Start server
-	log.Error_Log("Server fata errorr: '%v'", http.ListenAndServe(":8080", router))
+	serverErr := make(chan error, 1)
+	go func() {
+		if server.TLSConfig != nil {
+			serverErr <- server.ListenAndServeTLS("", "")
+			return
+		}
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		log.Error_Log("Server fata errorr: '%v'", err)
+	case <-stopCtx.Done():
+		shutdownServer(server, time.Duration(ServerConfigs.ShutdownTimeout)*time.Second)
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : shutdownServer
+ *
+ *  Purpose : Stop server gracefully. New websocket upgrades are rejected, pending messages
+ *			  are flushed and chargers get close frame, then HTTP server is shut down.
+ *			  Connections not closed before the timeout are closed forcibly
+ *
+ *    Input : server *http.Server - HTTP server to shut down
+ *			  timeout time.Duration - time to drain the connections
+ *
+ *   Return : Nothing
+ */
+func shutdownServer(server *http.Server, timeout time.Duration) {
+	log.Info_Log("Server is stopping, connections are drained in %v", timeout)
+
+	connectionsMux.Lock()
+	isShuttingDown = true
+	close(shutdown) // Writing goroutines flush messages and close connections
+	connectionsMux.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		connections.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		log.Info_Log("Connections are closed")
+	case <-time.After(timeout):
+		log.Error_Log("Connections are not closed in %v, closing them", timeout)
+		closeConnections()
+		select {
+		case <-drained:
+		case <-time.After(time.Second):
+			log.Error_Log("Goroutines of the connections are not finished")
+		}
+	}
+
+	// Requests of the API in progress are finished
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	shutdownErr := server.Shutdown(ctx)
+
+	// Calls waiting in background are cancelled before the queue is closed
+	closeConnections()
+	CentralSystem.Wait()
+
+	if shutdownErr != nil {
+		log.Error_Log("Cannot shutdown HTTP server with error '%v'", shutdownErr)
+		return
+	}
+
+	log.Info_Log("Server is stopped")
+}
+
+/****************************************************************************************
+ *
+ * Function : isServerStopping
+ *
+ *  Purpose : Check if server is stopping
+ *
+ *   Return : bool - true when new connections are rejected
+ */
+func isServerStopping() bool {
+	connectionsMux.Lock()
+	defer connectionsMux.Unlock()
+	return isShuttingDown
+}
+
+/****************************************************************************************
+ *
+ * Function : expireMessages
+ *
+ *  Purpose : Goroutine to delete finished messages from the queue after TTL,
+ *			  stopped on server stop
+ *
+ *    Input : ttl time.Duration - time to keep finished messages
+ *
+ *   Return : Nothing
+ */
+func expireMessages(ttl time.Duration) {
+	// Messages are checked at least every minute
+	interval := ttl
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			expired, err := MQueue.Expire(time.Now().Add(-ttl))
+			if err != nil {
+				log.Error_Log("Cannot expire messages of the queue with error '%v'", err)
+			}
+			if expired > 0 {
+				log.Info_Log("Expired %v finished messages of the queue", expired)
+			}
+		case <-shutdown:
+			return
+		}
+	}
+}
+
+/****************************************************************************************
+ *
+ * Function : reloadCertificates
+ *
+ *  Purpose : Goroutine to reload certificates from the files on SIGHUP signal,
+ *			  new connections use them without restart of the server
+ *
+ *    Input : certificates *core.CertificateStore - certificates of the listener
+ *
+ *   Return : Nothing
+ */
+func reloadCertificates(certificates *core.CertificateStore) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	for range signals {
+		if err := certificates.Reload(); err != nil {
+			log.Error_Log("Cannot reload certificates, previous are in use. Error '%v'", err)
+			continue
+		}
+		log.Info_Log("Certificates are reloaded from '%v'", certificates.CertFile)
+	}
 }
 
 /****************************************************************************************
@@ -92,10 +324,48 @@ func messageStatusAPIHandler(w http.ResponseWriter, r *http.Request, ps httprout
 	tm := timelib.EventTimerConstructor()
 	log.Info_Log("Handle income messageStatusAPIHandler request from Host '%v' and Path '%v'", r.URL.Host, r.URL.Path)
 	// Get Message from queue
-	example.GetMessageStatusAPI(ps.ByName("messageReference"), &MQueue, &log, w)
+	example.GetMessageStatusAPI(ps.ByName("messageReference"), r.URL.Query().Get("charger"), MQueue, &log, w)
 	log.Info_Log("messageStatusAPIHandler is finished in %v", tm.PrintTimerString())
 }
 
+/****************************************************************************************
+ *
+ * Function : messagesAPIHandler
+ *
+ *  Purpose : Handles client request to list messages of the queue
+ *
+ *    Input : w http.ResponseWriter - http response
+ *            r *http.Request - http request object
+ *            ps httprouter.Params - router parameter
+ *
+ *   Return : Nothing
+ */
+func messagesAPIHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	tm := timelib.EventTimerConstructor()
+	log.Info_Log("Handle income messagesAPIHandler request from Host '%v' and Path '%v'", r.URL.Host, r.URL.Path)
+	example.GetMessagesAPI(r.URL.Query(), MQueue, &log, w)
+	log.Info_Log("messagesAPIHandler is finished in %v", tm.PrintTimerString())
+}
+
+/****************************************************************************************
+ *
+ * Function : queueStatsAPIHandler
+ *
+ *  Purpose : Handles client request to get statistics of the message queue
+ *
+ *    Input : w http.ResponseWriter - http response
+ *            r *http.Request - http request object
+ *            ps httprouter.Params - router parameter
+ *
+ *   Return : Nothing
+ */
+func queueStatsAPIHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	tm := timelib.EventTimerConstructor()
+	log.Info_Log("Handle income queueStatsAPIHandler request from Host '%v' and Path '%v'", r.URL.Host, r.URL.Path)
+	example.GetQueueStatsAPI(MQueue, &log, w)
+	log.Info_Log("queueStatsAPIHandler is finished in %v", tm.PrintTimerString())
+}
+
 /****************************************************************************************
  *
  * Function : chargerStatusAPIHandler
@@ -116,6 +386,121 @@ func chargerStatusAPIHandler(w http.ResponseWriter, r *http.Request, ps httprout
 	log.Info_Log("chargerStatusAPIHandler is finished in %v", tm.PrintTimerString())
 }
 
+/****************************************************************************************
+ *
+ * Function : meterValuesAPIHandler
+ *
+ *  Purpose : Handles client request to get meter readings of the charger
+ *
+ *    Input : w http.ResponseWriter - http response
+ *            r *http.Request - http request object
+ *            ps httprouter.Params - router parameter
+ *
+ *   Return : Nothing
+ */
+func meterValuesAPIHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	tm := timelib.EventTimerConstructor()
+	log.Info_Log("Handle income meterValuesAPIHandler request from Host '%v' and Path '%v'", r.URL.Host, r.URL.Path)
+	// Get readings from the store
+	example.GetMeterValuesAPI(ps.ByName("chargerName"), r.URL.Query(), &ServerConfigs, MeterValues, &log, w)
+	log.Info_Log("meterValuesAPIHandler is finished in %v", tm.PrintTimerString())
+}
+
+/****************************************************************************************
+ *
+ * Function : listChargersAPIHandler
+ *
+ *  Purpose : Handles client request to get status of all chargers
+ *
+ *    Input : w http.ResponseWriter - http response
+ *            r *http.Request - http request object
+ *            ps httprouter.Params - router parameter
+ *
+ *   Return : Nothing
+ */
+func listChargersAPIHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	tm := timelib.EventTimerConstructor()
+	log.Info_Log("Handle income listChargersAPIHandler request from Host '%v' and Path '%v'", r.URL.Host, r.URL.Path)
+	example.ListChargersAPI(ServerConfigs.Chargers, &log, w)
+	log.Info_Log("listChargersAPIHandler is finished in %v", tm.PrintTimerString())
+}
+
+/****************************************************************************************
+ *
+ * Function : createChargerAPIHandler
+ *
+ *  Purpose : Handles client request to add charger to the registry
+ *
+ *    Input : w http.ResponseWriter - http response
+ *            r *http.Request - http request object
+ *            ps httprouter.Params - router parameter
+ *
+ *   Return : Nothing
+ */
+func createChargerAPIHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	tm := timelib.EventTimerConstructor()
+	log.Info_Log("Handle income createChargerAPIHandler request from Host '%v' and Path '%v'", r.URL.Host, r.URL.Path)
+	example.CreateChargerAPI(ServerConfigs.Chargers, &log, r, w)
+	log.Info_Log("createChargerAPIHandler is finished in %v", tm.PrintTimerString())
+}
+
+/****************************************************************************************
+ *
+ * Function : updateChargerAPIHandler
+ *
+ *  Purpose : Handles client request to change settings of the charger
+ *
+ *    Input : w http.ResponseWriter - http response
+ *            r *http.Request - http request object
+ *            ps httprouter.Params - router parameter
+ *
+ *   Return : Nothing
+ */
+func updateChargerAPIHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	tm := timelib.EventTimerConstructor()
+	log.Info_Log("Handle income updateChargerAPIHandler request from Host '%v' and Path '%v'", r.URL.Host, r.URL.Path)
+	example.UpdateChargerAPI(ps.ByName("chargerName"), ServerConfigs.Chargers, &log, r, w)
+	log.Info_Log("updateChargerAPIHandler is finished in %v", tm.PrintTimerString())
+}
+
+/****************************************************************************************
+ *
+ * Function : deleteChargerAPIHandler
+ *
+ *  Purpose : Handles client request to remove charger from the registry
+ *
+ *    Input : w http.ResponseWriter - http response
+ *            r *http.Request - http request object
+ *            ps httprouter.Params - router parameter
+ *
+ *   Return : Nothing
+ */
+func deleteChargerAPIHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	tm := timelib.EventTimerConstructor()
+	log.Info_Log("Handle income deleteChargerAPIHandler request from Host '%v' and Path '%v'", r.URL.Host, r.URL.Path)
+	example.DeleteChargerAPI(ps.ByName("chargerName"), ServerConfigs.Chargers, &log, w)
+	log.Info_Log("deleteChargerAPIHandler is finished in %v", tm.PrintTimerString())
+}
+
+/****************************************************************************************
+ *
+ * Function : rotateAuthTokenAPIHandler
+ *
+ *  Purpose : Handles client request to set new password of the charger
+ *
+ *    Input : w http.ResponseWriter - http response
+ *            r *http.Request - http request object
+ *            ps httprouter.Params - router parameter
+ *
+ *   Return : Nothing
+ */
+func rotateAuthTokenAPIHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	tm := timelib.EventTimerConstructor()
+	log.Info_Log("Handle income rotateAuthTokenAPIHandler request from Host '%v' and Path '%v'", r.URL.Host, r.URL.Path)
+	example.RotateAuthTokenAPI(ps.ByName("chargerName"), ServerConfigs.Chargers, &log, r, w)
+	log.Info_Log("rotateAuthTokenAPIHandler is finished in %v", tm.PrintTimerString())
+}
+
 /****************************************************************************************
  *
  * Function : triggerActionAPIHandler
@@ -132,10 +517,30 @@ func triggerActionAPIHandler(w http.ResponseWriter, r *http.Request, ps httprout
 	tm := timelib.EventTimerConstructor()
 	log.Info_Log("Handle income TriggerActionAPI request from Host '%v' and Path '%v'", r.URL.Host, r.URL.Path)
 	// Call Trigger Action API
-	example.TriggerActionAPI(&ServerConfigs, &MQueue, &log, ps, w)
+	example.TriggerActionAPI(&CentralSystem, &log, ps, w)
 	log.Info_Log("triggerActionAPIHandler is finished in %v", tm.PrintTimerString())
 }
 
+/****************************************************************************************
+ *
+ * Function : commandAPIHandler
+ *
+ *  Purpose : Create handler of the clients request to send Call with action to the charger
+ *
+ *    Input : action string - action of the Call
+ *
+ *   Return : httprouter.Handle - handler for the router
+ */
+func commandAPIHandler(action string) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		tm := timelib.EventTimerConstructor()
+		log.Info_Log("Handle income CommandAPI '%v' request from Host '%v' and Path '%v'", action, r.URL.Host, r.URL.Path)
+		// Call Command API
+		example.CommandAPI(action, &CentralSystem, &log, ps, r, w)
+		log.Info_Log("commandAPIHandler '%v' is finished in %v", action, tm.PrintTimerString())
+	}
+}
+
 /****************************************************************************************
  *
  * Function : wsChargerHandler
@@ -156,6 +561,13 @@ func wsChargerHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Para
 	chargerName := ps.ByName("chargerName")
 	log.Info_Log("[%v] HTTP is connected", chargerName)
 
+	// Charger reconnects later or to other instance of the server
+	if isServerStopping() {
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+		log.Info_Log("[%v] Server is stopping, connection is rejected", chargerName)
+		return
+	}
+
 	// Get Charger from the Configs
 	chargerObj, err := ServerConfigs.GetChargerObj(chargerName)
 	if err != nil || chargerObj == nil {
@@ -165,47 +577,88 @@ func wsChargerHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Para
 		return
 	}
 
-	// Check if charger already has connection with server
-	if chargerObj.WebSocketConnected {
-		// Requested charger is connected to server already
+	// Charger must offer subprotocol supported by server, regarding OCPP-J section 3
+	subprotocol, isNegotiated := core.NegotiateSubprotocol(ServerConfigs.Subprotocols, websocket.Subprotocols(r))
+	if !isNegotiated {
 		http.Error(w, "Bad Request", http.StatusBadRequest)
-		log.Info_Log("[%v] Charger is connected already", chargerName)
+		log.Error_Log("[%v] Charger offered subprotocols '%v', server supports '%v'", chargerName, websocket.Subprotocols(r), ServerConfigs.Subprotocols)
 		return
 	}
 
-	log.Info_Log("[%v] Charger is exists and websocket connection is not established yet. Will try now", chargerName)
+	// Create log instance with file name "server.{chargerName}"
+	chargerLog := logging.LogConstructor(logFilesPath+"."+chargerName, true)
+	// Create OCPP Hadlers
+	ocppHandlers := example.OCPPHandlersConstructor()
 
-	//Convert http request to WebSocket
-	conn, err := websocket.Upgrade(w, r, w.Header(), 1024, 1024)
-	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		log.Error_Log("[%v] Could not open websocket connection with error '%v'", chargerName, err)
+	// Update ocppHandlers object
+	ocppHandlers.Log = chargerLog            // Add log
+	ocppHandlers.MQueue = MQueue             // Add the Message queue
+	ocppHandlers.Charger = chargerObj        // Add charger details to ocppHandlers
+	ocppHandlers.Transactions = Transactions // Add transactions store
+	ocppHandlers.MeterValues = MeterValues   // Add meter values store
+	ocppHandlers.Authorizer = Authorizer     // Add identifiers authorizer
+	// Chargers without AuthToken are allowed only by configs
+	ocppHandlers.AllowUnauthenticated = ServerConfigs.AllowUnauthenticated
+
+	// Authorise charger before upgrade, regarding Security Profile 1
+	if !ocppHandlers.Authorisation(chargerName, r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="OCPP", charset="UTF-8"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		log.Error_Log("[%v] Charger is not authorised", chargerName)
 		return
 	}
 
-	log.Info_Log("[%v] Connection is upgraded to Websocket type", chargerName)
+	// Answer with agreed subprotocol
+	responseHeader := http.Header{}
+	responseHeader.Set("Sec-WebSocket-Protocol", subprotocol)
 
-	// Create log instance with file name "server.{chargerName}"
-	chargerLog := logging.LogConstructor(logFilesPath+"."+chargerName, true)
-	// Create OCPP Hadlers
-	ocppHandlers := example.OCPPHandlersConstructor()
+	// Reading goroutine reports when charger goes offline
+	chargerObj.SetOfflineHandler(chargerOffline)
+
+	// Reconnect policy is checked and connection is set to the charger while other handshakes of the charger wait
+	connection, previousConnection, err := chargerObj.Adopt(ServerConfigs.AcceptReconnect, func() (*example.Connection, error) {
+		//Convert http request to WebSocket
+		conn, err := websocket.Upgrade(w, r, responseHeader, 1024, 1024)
+		if err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return nil, errors.New(fmt.Sprintf("Could not open websocket connection with error '%v'", err))
+		}
 
-	// Update charger object
-	chargerObj.InboundIP = r.RemoteAddr                                    // Store remote IP
-	chargerObj.WebSocketConnected = true                                   // Set Charger's WebSocket flag as connected
-	chargerObj.AuthConnection = ocppHandlers.Authorisation(chargerName, r) // Authorise request
+		log.Info_Log("[%v] Connection is upgraded to Websocket type with subprotocol '%v'", chargerName, conn.Subprotocol())
 
-	// Update ocppHandlers object
-	ocppHandlers.Log = chargerLog     // Add log
-	ocppHandlers.MQueue = &MQueue     // Add pointer to the Message queue
-	ocppHandlers.Charger = chargerObj // Add charger details to ocppHandlers
+		// Connection owns the socket till one of the goroutines closes it
+		connection := example.ConnectionConstructor(connectionsCtx, conn)
 
-	// Define socket activity flag
-	isSocketActive := true
+		// Goroutines are counted to be waited on server stop
+		connectionsMux.Lock()
+		defer connectionsMux.Unlock()
+		if isShuttingDown {
+			connection.CloseWithCode(websocket.CloseGoingAway, "Server is stopped")
+			return nil, errors.New("Server is stopping, connection is closed")
+		}
+		connections.Add(2)
+
+		return connection, nil
+	})
+	if err == example.ErrReconnectRejected {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		log.Info_Log("[%v] Charger is connected already, connection is rejected by '%v' policy", chargerName, ServerConfigs.ReconnectPolicy)
+		return
+	}
+	if err != nil {
+		log.Error_Log("[%v] %v", chargerName, err)
+		return
+	}
+
+	// Goroutines of the previous connection are stopped by closing it
+	if previousConnection != nil {
+		previousConnection.Close("Replaced by new connection")
+		log.Info_Log("[%v] Charger is connected already, previous connection from '%v' is closed", chargerName, previousConnection.RemoteAddr())
+	}
 
 	// Start Read and Write gorutine
-	go logReaderRD(conn, chargerName, &isSocketActive, chargerObj, &ocppHandlers, &chargerLog)
-	go logReaderWR(conn, chargerName, &isSocketActive, chargerObj, &chargerLog)
+	go logReaderRD(connection, chargerName, chargerObj, &ocppHandlers, &chargerLog)
+	go logReaderWR(connection, chargerName, chargerObj, &chargerLog)
 
 	log.Info_Log("OCPPRequestHandler is finished in %v", tm.PrintTimerString())
 }
@@ -216,10 +669,9 @@ func wsChargerHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Para
  *
  *  Purpose : Goroutine method to read from connected websocket
  *
- *    Input : conn *websocket.Conn - websocket connection pointer
+ *    Input : connection *example.Connection - websocket connection of the charger
  *			  chargerName string - current charger name
  *			  chargerObj *example.Charger - pointer on the charger obj
- *			  isSocketActive *bool - Socket activity flag
  *			  ocppHandlers *example.OCPPHandlers - defined ocppHandlers for the charger
  *			  chargerLog *logging.Log - pointer to the charger log file
  *
@@ -227,48 +679,56 @@ func wsChargerHandler(w http.ResponseWriter, r *http.Request, ps httprouter.Para
  *
  */
 
-func logReaderRD(conn *websocket.Conn, chargerName string, isSocketActive *bool, chargerObj *example.Charger, ocppHandlers *example.OCPPHandlers, chargerLog *logging.Log) {
-	defer conn.Close()
+func logReaderRD(connection *example.Connection, chargerName string, chargerObj *example.Charger, ocppHandlers *example.OCPPHandlers, chargerLog *logging.Log) {
+	defer connections.Done()
 	chargerLog.Info_Log("[%v] Start RD goroutine for charger '%v'", tools.GetGoID(), chargerName)
 
 	// Define OCPP Handler Class
 	centralSystem := core.CentralSystemHandlerConstructor(ocppHandlers)
+	// Answers of the charger are passed to the outstanding Call
+	centralSystem.Calls = chargerObj.Calls
 
-	for {
-
-		if *isSocketActive == false {
-			break
-		}
+	// Connection is closed when nothing is received before read deadline
+	chargerObj.KeepAlive(connection)
+	offlineReason := "Connection is closed by handler"
 
+	for {
 		// Read websocket message
-		_, rawMessage, readingSocketError := conn.ReadMessage()
+		rawMessage, readingSocketError := connection.ReadMessage()
 
 		if readingSocketError != nil {
 			chargerLog.Error_Log("[%v] Client is disconnected with error: '%v'", tools.GetGoID(), readingSocketError)
-			*isSocketActive = false
-			chargerObj.WebSocketConnected = false
-			chargerObj.WriteChannel <- "wakeup" // Send string to wakeup write gorutine
+			offlineReason = chargerObj.ReadErrorReason(readingSocketError)
 			break
 		}
 
+		chargerObj.KeepAlive(connection)
 		chargerLog.Info_Log("[%v] Received '%v'", tools.GetGoID(), string(rawMessage))
 
 		// Add arrived rawMessage to the queue
-		_, uniqueID, err := messages.GetMessageTypeFromRaw(string(rawMessage))
+		messageType, uniqueID, err := messages.GetMessageTypeFromRaw(string(rawMessage))
 		if err != nil {
 			chargerLog.Error_Log("[%v] Cannot get uniqueid from : '%v'", tools.GetGoID(), err)
 			continue
 		}
-		qMessage := example.Message{Action: "", Received: string(rawMessage), Status: example.MESSAGE_TYPE_RECEIVED, Sent: ""}
-		// Add message to the queue
-		addingErr := MQueue.Add(uniqueID, qMessage)
-		if addingErr != nil {
-			log.Error_Log("[%v] Error to add message to the queue: '%v'", tools.GetGoID(), addingErr)
+		if messageType == int(messages.MESSAGE_TYPE_CALL) {
+			qMessage := example.Message{ChargerName: chargerName, Direction: example.MESSAGE_DIRECTION_INCOMING, Received: string(rawMessage), Status: example.MESSAGE_TYPE_RECEIVED}
+			if callMessage, err := messages.CallMessageParser(string(rawMessage)); err == nil {
+				qMessage.Action = callMessage.Action
+			}
+			// Add message to the queue
+			addingErr := MQueue.Add(uniqueID, qMessage)
+			if addingErr != nil {
+				log.Error_Log("[%v] Error to add message to the queue: '%v'", tools.GetGoID(), addingErr)
+			}
+		} else if qMessage, exists := MQueue.Get(chargerName, uniqueID); exists {
+			// Answer to the Call of the Central System is kept in the Call message, status is set by handler
+			qMessage.MarkAnswered(string(rawMessage), time.Now().UTC())
+			MQueue.Update(chargerName, uniqueID, qMessage)
 		}
 
 		// Call OCPP message handler
-		response, responseErr, socketStatus := centralSystem.HandleIncomeMessage(string(rawMessage))
-		*isSocketActive = socketStatus
+		response, responseErr, keepOpen := centralSystem.HandleIncomeMessage(string(rawMessage))
 
 		if responseErr != nil {
 			chargerLog.Error_Log("[%v] Response error: '%v'", tools.GetGoID(), responseErr)
@@ -276,25 +736,58 @@ func logReaderRD(conn *websocket.Conn, chargerName string, isSocketActive *bool,
 
 		// If handler generated callResult message - send it to the charger
 		if response != "" {
-			chargerObj.WriteChannel <- uniqueID
+			example.PushResponse(MQueue, chargerObj, uniqueID, response)
+		}
+
+		if !keepOpen {
+			chargerLog.Info_Log("[%v] Connection is closed by handler", tools.GetGoID())
+			break
 		}
 	}
 
-	// Clear Charger parameters
-	chargerObj.Disconnected()
+	// Stop writing goroutine. Reason is kept when connection is closed by other side already
+	connection.Close(offlineReason)
+
+	// Clear Charger parameters, unless connection is replaced by the new one
+	if chargerObj.Disconnected(connection, connection.Reason()) {
+		// Calls of the Central System not written yet are kept for the next connection, responses are not
+		for _, outboundMessage := range chargerObj.Outbound.DropResponses() {
+			if qMessage, exists := MQueue.Get(chargerName, outboundMessage.UniqueID); exists {
+				qMessage.MarkError("Charger is disconnected before response is sent")
+				MQueue.Update(chargerName, outboundMessage.UniqueID, qMessage)
+			}
+		}
+	} else {
+		chargerLog.Info_Log("[%v] Connection is replaced", tools.GetGoID())
+	}
 
 	chargerLog.Info_Log("[%v] Reading goroutine is finished", tools.GetGoID())
 }
 
+/****************************************************************************************
+ *
+ * Function : chargerOffline
+ *
+ *  Purpose : Handle offline event of the charger, called by the reading goroutine
+ *			  when connection is closed and not replaced by the new one
+ *
+ *    Input : chargerName string - name of the charger
+ *			  reason string - why connection is closed
+ *
+ *   Return : Nothing
+ */
+func chargerOffline(chargerName string, reason string) {
+	log.Info_Log("[%v] Charger is offline: %v", chargerName, reason)
+}
+
 /****************************************************************************************
  *
  * Function : logReaderWR
  *
  *  Purpose : Goroutine method to write to connected websocket
  *
- *    Input : conn *websocket.Conn - websocket connection pointer
+ *    Input : connection *example.Connection - websocket connection of the charger
  *			  chargerName string - current charger name
- *			  isSocketActive *bool - Socket activity flag
  *			  chargerObj *example.Charger - pointer on the charger obj
  *			  chargerLog *logging.Log - pointer to the charger log file
  *
@@ -302,39 +795,97 @@ func logReaderRD(conn *websocket.Conn, chargerName string, isSocketActive *bool,
  *
  */
 
-func logReaderWR(conn *websocket.Conn, chargerName string, isSocketActive *bool, chargerObj *example.Charger, chargerLog *logging.Log) {
-	defer conn.Close()
+func logReaderWR(connection *example.Connection, chargerName string, chargerObj *example.Charger, chargerLog *logging.Log) {
+	defer connections.Done()
 	chargerLog.Info_Log("[%v] Start WR gorutine for charger '%v'", tools.GetGoID(), chargerName)
 
-	for {
-		// Wait for the message from channel
-		uniqueID := <-chargerObj.WriteChannel
-		//log.Info_Log("[%v] logReaderWR. UniqueuID '%v'", tools.GetGoID(), uniqueID)
-
-		// Check if gorutine must to be finished
-		if *isSocketActive == false {
-			log.Info_Log("[%v] Close writing goroutine", tools.GetGoID())
-			break
-		}
+	// Ping charger to detect half-open connection, pong extends read deadline
+	var pingTicks <-chan time.Time
+	if pingInterval := chargerObj.PingInterval(); pingInterval > 0 {
+		pingTicker := time.NewTicker(time.Duration(pingInterval) * time.Second)
+		defer pingTicker.Stop()
+		pingTicks = pingTicker.C
+	}
 
-		// Get message from the queue
-		qMessage, _ := MQueue.GetMessage(uniqueID)
+	// Messages queued while charger was offline are written first
+	if !writeOutboundMessages(connection, chargerObj, chargerLog) {
+		return
+	}
 
-		//Send response to the charger
-		if err := conn.WriteMessage(websocket.TextMessage, []byte(qMessage.Sent)); err != nil {
-			chargerLog.Error_Log("[%v] Send error: '%v'", tools.GetGoID(), err)
+	for {
+		// Wait for the message in the outbound queue
+		select {
+		case <-connection.Done():
+			chargerLog.Info_Log("[%v] Writing goroutine is finished", tools.GetGoID())
+			return
+		case <-shutdown:
+			// Pending responses are sent before the close frame, Calls will not be answered
+			for outboundMessage, isPending := chargerObj.Outbound.PopResponse(); isPending; outboundMessage, isPending = chargerObj.Outbound.PopResponse() {
+				if !writeQueuedMessage(connection, chargerObj, outboundMessage, chargerLog) {
+					break
+				}
+			}
+			connection.CloseWithCode(websocket.CloseGoingAway, "Server is stopped")
+			chargerLog.Info_Log("[%v] Writing goroutine is finished on server stop", tools.GetGoID())
 			return
+		case <-pingTicks:
+			if err := connection.Ping(time.Duration(chargerObj.PongTimeout) * time.Second); err != nil {
+				chargerLog.Error_Log("[%v] Ping error: '%v'", tools.GetGoID(), err)
+				connection.Close(fmt.Sprintf("Ping error '%v'", err))
+				return
+			}
+		case <-chargerObj.Outbound.Ready():
+			if !writeOutboundMessages(connection, chargerObj, chargerLog) {
+				return
+			}
 		}
+	}
+}
 
-		// update status in the queue
-		qMessage.Status = example.MESSAGE_TYPE_COMPLETED
-		MQueue.UpdateByUniqueID(uniqueID, qMessage)
-
-		chargerLog.Info_Log("[%v] Sent to charger '%v'", tools.GetGoID(), qMessage.Sent)
+/****************************************************************************************
+ *
+ * Function : writeOutboundMessages
+ *
+ *  Purpose : Send messages of the outbound queue to the charger till queue is empty,
+ *			  responses are sent before Calls of the Central System
+ *
+ *    Input : connection *example.Connection - websocket connection of the charger
+ *			  chargerObj *example.Charger - pointer on the charger obj
+ *			  chargerLog *logging.Log - pointer to the charger log file
+ *
+ *   Return : bool - false when connection is broken and closed
+ *
+ */
+func writeOutboundMessages(connection *example.Connection, chargerObj *example.Charger, chargerLog *logging.Log) bool {
+	for outboundMessage, isQueued := chargerObj.Outbound.Pop(); isQueued; outboundMessage, isQueued = chargerObj.Outbound.Pop() {
+		if !writeQueuedMessage(connection, chargerObj, outboundMessage, chargerLog) {
+			return false
+		}
 	}
+	return true
+}
 
-	// Clear Charger parameters
-	chargerObj.Disconnected()
+/****************************************************************************************
+ *
+ * Function : writeQueuedMessage
+ *
+ *  Purpose : Send message of the outbound queue to the charger. Outgoing Call is marked
+ *			  as sent, response to the Call of the charger completes the exchange
+ *
+ *    Input : connection *example.Connection - websocket connection of the charger
+ *			  chargerObj *example.Charger - pointer on the charger obj
+ *			  outboundMessage example.OutboundMessage - message of the outbound queue
+ *			  chargerLog *logging.Log - pointer to the charger log file
+ *
+ *   Return : bool - false when connection is broken and closed
+ *
+ */
+func writeQueuedMessage(connection *example.Connection, chargerObj *example.Charger, outboundMessage example.OutboundMessage, chargerLog *logging.Log) bool {
+	if err := example.WriteOutboundMessage(connection, chargerObj, MQueue, outboundMessage); err != nil {
+		chargerLog.Error_Log("[%v] Send error: '%v'", tools.GetGoID(), err)
+		return false
+	}
 
-	chargerLog.Info_Log("[%v] Writing goroutine is finished", tools.GetGoID())
+	chargerLog.Info_Log("[%v] Sent to charger '%v'", tools.GetGoID(), outboundMessage.Message)
+	return true
 }